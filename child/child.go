@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math/rand"
 	"os"
 	"os/exec"
@@ -12,6 +11,8 @@ import (
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/hashicorp/consul-template/logging"
 )
 
 func init() {
@@ -53,12 +54,21 @@ type Child struct {
 
 	splay time.Duration
 
+	// logger is the logger of this child, which adds the command to each line.
+	logger *logging.Logger
+
 	// cmd is the actual child process under management.
 	cmd *exec.Cmd
 
 	// exitCh is the channel where the processes exit will be returned.
 	exitCh chan int
 
+	// exitLock protects exited and exitCode, which record the exit code of the
+	// most recent process to exit. These are only used for reporting.
+	exitLock sync.RWMutex
+	exited   bool
+	exitCode int
+
 	// stopLock is the mutex to lock when stopping. stopCh is the circuit breaker
 	// to force-terminate any waiting splays to kill the process now. stopped is
 	// a boolean that tells us if we have previously been stopped.
@@ -136,6 +146,7 @@ func New(i *NewInput) (*Child, error) {
 		splay:        i.Splay,
 		stopCh:       make(chan struct{}, 1),
 	}
+	child.logger = logging.New("child").With("command", child.Command())
 
 	return child, nil
 }
@@ -157,6 +168,14 @@ func (c *Child) Pid() int {
 	return c.pid()
 }
 
+// LastExitCode returns the exit code of the most recent process to exit. The
+// boolean is false if no process has exited yet.
+func (c *Child) LastExitCode() (int, bool) {
+	c.exitLock.RLock()
+	defer c.exitLock.RUnlock()
+	return c.exitCode, c.exited
+}
+
 // Command returns the human-formatted command with arguments.
 func (c *Child) Command() string {
 	list := append([]string{c.command}, c.args...)
@@ -169,7 +188,7 @@ func (c *Child) Command() string {
 // as the second error argument, but any errors returned by the command after
 // execution will be returned as a non-zero value over the exit code channel.
 func (c *Child) Start() error {
-	log.Printf("[INFO] (child) spawning: %s", c.Command())
+	c.logger.Printf("[INFO] spawning: %s", c.Command())
 	c.Lock()
 	defer c.Unlock()
 	return c.start()
@@ -178,7 +197,7 @@ func (c *Child) Start() error {
 // Signal sends the signal to the child process, returning any errors that
 // occur.
 func (c *Child) Signal(s os.Signal) error {
-	log.Printf("[INFO] (child) receiving signal %q", s.String())
+	c.logger.Printf("[INFO] receiving signal %q", s.String())
 	c.RLock()
 	defer c.RUnlock()
 	return c.signal(s)
@@ -189,26 +208,49 @@ func (c *Child) Signal(s os.Signal) error {
 // replaces the process attached to this Child.
 func (c *Child) Reload() error {
 	if c.reloadSignal == nil {
-		log.Printf("[INFO] (child) restarting process")
-
-		// Take a full lock because start is going to replace the process. We also
-		// want to make sure that no other routines attempt to send reload signals
-		// during this transition.
-		c.Lock()
-		defer c.Unlock()
-
-		c.kill()
-		return c.start()
+		return c.Restart()
 	}
 
-	log.Printf("[INFO] (child) reloading process")
+	c.logger.Printf("[INFO] reloading process")
 
 	// We only need a read lock here because neither the process nor the exit
 	// channel are changing.
 	c.RLock()
 	defer c.RUnlock()
 
-	return c.reload()
+	return c.reload(c.reloadSignal)
+}
+
+// ReloadWith behaves like Reload, but sends the given signal instead of the
+// reload signal. If the given signal is nil, the process is restarted.
+func (c *Child) ReloadWith(s os.Signal) error {
+	if s == nil {
+		return c.Restart()
+	}
+
+	c.logger.Printf("[INFO] reloading process with signal %q", s.String())
+
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.reload(s)
+}
+
+// Restart kills the child process, if it is running, and starts a new process
+// which replaces it. The process is killed the same way as Kill, so the kill
+// signal, kill timeout and splay all apply. A new exit channel is created, so
+// callers must fetch it again with ExitCh.
+func (c *Child) Restart() error {
+	c.logger.Printf("[INFO] restarting process")
+
+	// Take a full lock because start is going to replace the process. We also
+	// want to make sure that no other routines attempt to send reload signals
+	// during this transition.
+	c.Lock()
+	defer c.Unlock()
+
+	c.kill()
+	return c.start()
 }
 
 // Kill sends the kill signal to the child process and waits for successful
@@ -220,7 +262,7 @@ func (c *Child) Reload() error {
 // does not return any errors because it guarantees the process will be dead by
 // the return of the function call.
 func (c *Child) Kill() {
-	log.Printf("[INFO] (child) killing process")
+	c.logger.Printf("[INFO] killing process")
 	c.Lock()
 	defer c.Unlock()
 	c.kill()
@@ -231,7 +273,7 @@ func (c *Child) Kill() {
 // process from sending its value back up the exit channel. This is useful
 // when doing a graceful shutdown of an application.
 func (c *Child) Stop() {
-	log.Printf("[INFO] (child) stopping process")
+	c.logger.Printf("[INFO] stopping process")
 
 	c.Lock()
 	defer c.Unlock()
@@ -239,7 +281,7 @@ func (c *Child) Stop() {
 	c.stopLock.Lock()
 	defer c.stopLock.Unlock()
 	if c.stopped {
-		log.Printf("[WARN] (child) already stopped")
+		c.logger.Printf("[WARN] already stopped")
 		return
 	}
 	c.kill()
@@ -275,6 +317,11 @@ func (c *Child) start() error {
 			}
 		}
 
+		c.exitLock.Lock()
+		c.exited = true
+		c.exitCode = code
+		c.exitLock.Unlock()
+
 		// If the child is in the process of killing, do not send a response back
 		// down the exit channel.
 		c.stopLock.RLock()
@@ -345,13 +392,13 @@ func (c *Child) signal(s os.Signal) error {
 	return c.cmd.Process.Signal(s)
 }
 
-func (c *Child) reload() error {
+func (c *Child) reload(s os.Signal) error {
 	select {
 	case <-c.stopCh:
 	case <-c.randomSplay():
 	}
 
-	return c.signal(c.reloadSignal)
+	return c.signal(s)
 }
 
 func (c *Child) kill() {
@@ -405,7 +452,7 @@ func (c *Child) randomSplay() <-chan time.Time {
 	offset := rand.Int63n(ns)
 	t := time.Duration(offset)
 
-	log.Printf("[DEBUG] (child) waiting %.2fs for random splay", t.Seconds())
+	c.logger.Printf("[DEBUG] waiting %.2fs for random splay", t.Seconds())
 
 	return time.After(t)
 }
@@ -168,6 +168,42 @@ func TestPid(t *testing.T) {
 	}
 }
 
+func TestLastExitCode_noProcess(t *testing.T) {
+	t.Parallel()
+
+	c := testChild(t)
+	if _, ok := c.LastExitCode(); ok {
+		t.Error("expected no exit code")
+	}
+}
+
+func TestLastExitCode(t *testing.T) {
+	t.Parallel()
+
+	c := testChild(t)
+	c.command = "sh"
+	c.args = []string{"-c", "exit 3"}
+
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	select {
+	case <-c.ExitCh():
+	case <-time.After(fileWaitSleepDelay):
+		t.Fatal("process should have exited")
+	}
+
+	code, ok := c.LastExitCode()
+	if !ok {
+		t.Fatal("expected an exit code")
+	}
+	if code != 3 {
+		t.Errorf("expected %d to be %d", code, 3)
+	}
+}
+
 func TestStart(t *testing.T) {
 	t.Parallel()
 
@@ -317,6 +353,38 @@ func TestReload_noSignal(t *testing.T) {
 	}
 }
 
+func TestReloadWith_signal(t *testing.T) {
+	t.Parallel()
+
+	c := testChild(t)
+	c.command = "bash"
+	c.args = []string{"-c", "trap 'echo one; exit' SIGUSR1; while true; do sleep 0.2; done"}
+	c.reloadSignal = nil
+
+	out := gatedio.NewByteBuffer()
+	c.stdout, c.stderr = out, out
+
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	// For some reason bash doesn't start immediately
+	time.Sleep(fileWaitSleepDelay)
+
+	if err := c.ReloadWith(syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Give time for the file to flush
+	time.Sleep(fileWaitSleepDelay)
+
+	expected := "one\n"
+	if out.String() != expected {
+		t.Errorf("expected %q to be %q", out.String(), expected)
+	}
+}
+
 func TestReload_noProcess(t *testing.T) {
 	t.Parallel()
 
@@ -327,6 +395,47 @@ func TestReload_noProcess(t *testing.T) {
 	}
 }
 
+func TestRestart_exited(t *testing.T) {
+	t.Parallel()
+
+	c := testChild(t)
+	c.command = "bash"
+	c.args = []string{"-c", "exit 2"}
+
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	exitCh := c.ExitCh()
+	select {
+	case code := <-exitCh:
+		if code != 2 {
+			t.Errorf("expected %d to be %d", code, 2)
+		}
+	case <-time.After(fileWaitSleepDelay):
+		t.Fatal("process should have exited")
+	}
+
+	if err := c.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	nexitCh := c.ExitCh()
+	if nexitCh == exitCh {
+		t.Error("expected a new exit channel")
+	}
+
+	select {
+	case code := <-nexitCh:
+		if code != 2 {
+			t.Errorf("expected %d to be %d", code, 2)
+		}
+	case <-time.After(fileWaitSleepDelay):
+		t.Fatal("restarted process should have exited")
+	}
+}
+
 func TestKill_signal(t *testing.T) {
 	t.Parallel()
 
@@ -15,6 +15,8 @@ import (
 	"github.com/hashicorp/consul-template/logging"
 	"github.com/hashicorp/consul-template/manager"
 	"github.com/hashicorp/consul-template/signals"
+	"github.com/hashicorp/consul-template/systemd"
+	"github.com/hashicorp/consul-template/telemetry"
 	"github.com/hashicorp/consul-template/version"
 )
 
@@ -31,6 +33,7 @@ const (
 	ExitCodeParseFlagsError
 	ExitCodeRunnerError
 	ExitCodeConfigError
+	ExitCodeRenderTimeout
 )
 
 // CLI is the main entry point.
@@ -47,6 +50,10 @@ type CLI struct {
 	// stopCh is an internal channel used to trigger a shutdown of the CLI.
 	stopCh  chan struct{}
 	stopped bool
+
+	// telemetry holds the metrics sinks, which are recreated each time the
+	// configuration is loaded.
+	telemetry *telemetry.Telemetry
 }
 
 // NewCLI creates a new CLI object with the given stdout and stderr streams.
@@ -109,50 +116,105 @@ func (cli *CLI) Run(args []string) int {
 	}
 	go runner.Start()
 
+	// Tell the service manager about reloads, if it started us. The runner
+	// tells it when the templates have rendered.
+	notifier := systemd.NewNotifier()
+
+	// Reload when the configuration or template sources change, if enabled.
+	watcher := watchConfig(nil, paths, config)
+	defer func() { watcher.Stop() }()
+
 	// Listen for signals
 	signal.Notify(cli.signalCh)
 
+	// Reloads run in their own goroutine, since the runner only applies a new
+	// configuration between runs, and signals must still be handled meanwhile.
+	// reloadCh is only set while a reload is running. A reload which is asked
+	// for while another one runs is started once it finishes.
+	var reloadCh chan *reloadResult
+	var reloadSignaled, reloadQueued bool
+	startReload := func(signaled bool) {
+		notifyService(notifier, systemd.Reloading)
+		reloadCh = make(chan *reloadResult, 1)
+		reloadSignaled = signaled
+		cfg, r, ch := config, runner, reloadCh
+		go func() {
+			ch <- cli.reload(cfg, r, paths, cliConfig, dry, once)
+		}()
+	}
+
 	for {
+		// While a reload runs, the runner's channels are read by the reload, and
+		// the runner may be replaced.
+		var runnerErrCh <-chan error
+		var runnerDoneCh <-chan struct{}
+		var watcherCh <-chan struct{}
+		if reloadCh == nil {
+			runnerErrCh, runnerDoneCh = runner.ErrCh, runner.DoneCh
+			watcherCh = watcher.ReloadCh()
+		}
+
 		select {
-		case err := <-runner.ErrCh:
-			// Check if the runner's error returned a specific exit status, and return
-			// that value. If no value was given, return a generic exit status.
-			code := ExitCodeRunnerError
-			if typed, ok := err.(manager.ErrExitable); ok {
-				code = typed.ExitStatus()
-			}
-			return logError(err, code)
-		case <-runner.DoneCh:
+		case err := <-runnerErrCh:
+			return logError(err, runnerExitCode(err, once))
+		case <-runnerDoneCh:
 			return ExitCodeOK
+		case <-watcherCh:
+			startReload(false)
+		case res := <-reloadCh:
+			reloadCh = nil
+			prev := config
+			config, runner = res.config, res.runner
+			if runner == nil {
+				if res.err == manager.ErrRunnerStopped {
+					return ExitCodeOK
+				}
+				return logError(res.err, res.code)
+			}
+
+			// A configuration which cannot be loaded after a change on disk, such as
+			// a file saved with a syntax error, is reported and the runner keeps
+			// running. A reload which was signaled fails instead.
+			if res.err != nil {
+				if reloadSignaled {
+					runner.Stop()
+					return logError(res.err, res.code)
+				}
+				log.Printf("[ERR] (cli) %s", res.err)
+			}
+			if config != prev {
+				watcher = watchConfig(watcher, paths, config)
+			}
+
+			notifyService(notifier, systemd.Ready)
+
+			if reloadQueued {
+				reloadQueued = false
+				startReload(true)
+			}
 		case s := <-cli.signalCh:
 			log.Printf("[DEBUG] (cli) receiving signal %q", s)
 
 			switch s {
 			case *config.ReloadSignal:
 				fmt.Fprintf(cli.errStream, "Reloading configuration...\n")
-				runner.Stop()
-
-				// Re-parse any configuration files or paths
-				config, err = loadConfigs(paths, cliConfig)
-				if err != nil {
-					return logError(err, ExitCodeConfigError)
-				}
-				config.Finalize()
-
-				// Load the new configuration from disk
-				config, err = cli.setup(config)
-				if err != nil {
-					return logError(err, ExitCodeConfigError)
+				if reloadCh != nil {
+					log.Printf("[INFO] (cli) reload already running, reloading again once it finishes")
+					reloadQueued = true
+					continue
 				}
-
-				runner, err = manager.NewRunner(config, dry, once)
-				if err != nil {
-					return logError(err, ExitCodeRunnerError)
-				}
-				go runner.Start()
+				startReload(true)
 			case *config.KillSignal:
 				fmt.Fprintf(cli.errStream, "Cleaning up...\n")
 				runner.Stop()
+
+				// A running reload returns once the runner stopped. It may have
+				// replaced the runner already.
+				if reloadCh != nil {
+					if res := <-reloadCh; res.runner != nil {
+						res.runner.Stop()
+					}
+				}
 				return ExitCodeInterrupt
 			case signals.SignalLookup["SIGCHLD"]:
 				// The SIGCHLD signal is sent to the parent of a child process when it
@@ -171,6 +233,83 @@ func (cli *CLI) Run(args []string) int {
 	}
 }
 
+// reloadResult is the result of a reload, see reload.
+type reloadResult struct {
+	config *config.Config
+	runner *manager.Runner
+	code   int
+	err    error
+}
+
+// reload loads the configuration paths again and applies the configuration to
+// the runner, which keeps its watches and child processes. If the configuration
+// cannot be applied to the running runner, it is stopped and replaced by a new
+// one. Logging and telemetry are set up again once the configuration is
+// applied, so a configuration which is refused does not change them.
+//
+// It returns the configuration and the runner which are running afterwards,
+// along with any error and its exit status. If the configuration could not be
+// loaded or applied, the given configuration and runner keep running. The
+// returned runner is nil if it was stopped, in which case the error is fatal.
+//
+// Reload waits for the runner to be between runs, so it is called from its own
+// goroutine.
+func (cli *CLI) reload(c *config.Config, runner *manager.Runner, paths []string, cliConfig *config.Config, dry, once bool) *reloadResult {
+	// Re-parse any configuration files or paths
+	next, err := loadConfigs(paths, cliConfig)
+	if err != nil {
+		return &reloadResult{c, runner, ExitCodeConfigError, err}
+	}
+	next.Finalize()
+
+	err = runner.Reload(next)
+	if _, ok := err.(*manager.ErrReloadFailed); ok {
+		return &reloadResult{c, runner, ExitCodeConfigError, err}
+	}
+	if err == manager.ErrRestartRequired {
+		runner.Stop()
+		runner, err = manager.NewRunner(next, dry, once)
+		if err != nil {
+			return &reloadResult{nil, nil, ExitCodeRunnerError, err}
+		}
+		go runner.Start()
+	} else if err != nil {
+		// The runner stopped with an error before it could reload.
+		runner.Stop()
+		return &reloadResult{nil, nil, runnerExitCode(err, once), err}
+	}
+
+	// Set up logging and telemetry for the configuration which is now running.
+	if _, err := cli.setup(next); err != nil {
+		return &reloadResult{next, runner, ExitCodeConfigError, err}
+	}
+	return &reloadResult{next, runner, ExitCodeOK, nil}
+}
+
+// notifyService sends the given state to the service manager, if it started
+// us.
+func notifyService(n *systemd.Notifier, state string) {
+	if err := n.Notify(state); err != nil {
+		log.Printf("[WARN] (cli) failed to notify service manager: %s", err)
+	}
+}
+
+// runnerExitCode returns the exit status for the given error from the runner.
+func runnerExitCode(err error, once bool) int {
+	// Check if the runner's error returned a specific exit status, and return
+	// that value. If no value was given, return a generic exit status.
+	code := ExitCodeRunnerError
+	if typed, ok := err.(manager.ErrExitable); ok {
+		code = typed.ExitStatus()
+	}
+	// In once mode, templates which never rendered have their own exit
+	// status, so pipelines can tell why they failed.
+	if _, ok := err.(*manager.ErrRenderTimeout); ok && once {
+		code = ExitCodeRenderTimeout
+	}
+	return code
+}
+
 // stop is used internally to shutdown a running CLI
 func (cli *CLI) stop() {
 	cli.Lock()
@@ -310,6 +449,11 @@ func (cli *CLI) ParseFlags(args []string) (*config.Config, []string, bool, bool,
 		return nil
 	}), "dedup", "")
 
+	flags.Var((funcBoolVar)(func(b bool) error {
+		c.Diff = config.Bool(b)
+		return nil
+	}), "diff", "")
+
 	flags.BoolVar(&dry, "dry", false, "")
 
 	flags.Var((funcVar)(func(s string) error {
@@ -355,6 +499,16 @@ func (cli *CLI) ParseFlags(args []string) (*config.Config, []string, bool, bool,
 		return nil
 	}), "kill-signal", "")
 
+	flags.Var((funcVar)(func(s string) error {
+		c.LogFile.Path = config.String(s)
+		return nil
+	}), "log-file", "")
+
+	flags.Var((funcVar)(func(s string) error {
+		c.LogFormat = config.String(s)
+		return nil
+	}), "log-format", "")
+
 	flags.Var((funcVar)(func(s string) error {
 		c.LogLevel = config.String(s)
 		return nil
@@ -381,16 +535,31 @@ func (cli *CLI) ParseFlags(args []string) (*config.Config, []string, bool, bool,
 		return nil
 	}), "reload-signal", "")
 
+	flags.Var((funcDurationVar)(func(d time.Duration) error {
+		c.RenderTimeout = config.TimeDuration(d)
+		return nil
+	}), "render-timeout", "")
+
 	flags.Var((funcDurationVar)(func(d time.Duration) error {
 		c.Consul.Retry.Backoff = config.TimeDuration(d)
 		return nil
 	}), "retry", "")
 
+	flags.Var((funcVar)(func(s string) error {
+		c.Status.Address = config.String(s)
+		return nil
+	}), "status-addr", "")
+
 	flags.Var((funcBoolVar)(func(b bool) error {
 		c.Syslog.Enabled = config.Bool(b)
 		return nil
 	}), "syslog", "")
 
+	flags.Var((funcVar)(func(s string) error {
+		c.Syslog.Address = config.String(s)
+		return nil
+	}), "syslog-addr", "")
+
 	flags.Var((funcVar)(func(s string) error {
 		c.Syslog.Facility = config.String(s)
 		return nil
@@ -519,6 +688,11 @@ func (cli *CLI) ParseFlags(args []string) (*config.Config, []string, bool, bool,
 		return nil
 	}), "wait", "")
 
+	flags.Var((funcBoolVar)(func(b bool) error {
+		c.WatchConfig = config.Bool(b)
+		return nil
+	}), "watch-config", "")
+
 	flags.BoolVar(&isVersion, "v", false, "")
 	flags.BoolVar(&isVersion, "version", false, "")
 
@@ -564,16 +738,58 @@ func logError(err error, status int) int {
 }
 
 func (cli *CLI) setup(conf *config.Config) (*config.Config, error) {
+	var logFile string
+	if config.BoolVal(conf.LogFile.Enabled) {
+		logFile = config.StringVal(conf.LogFile.Path)
+	}
+
 	if err := logging.Setup(&logging.Config{
-		Name:           version.Name,
-		Level:          config.StringVal(conf.LogLevel),
-		Syslog:         config.BoolVal(conf.Syslog.Enabled),
-		SyslogFacility: config.StringVal(conf.Syslog.Facility),
-		Writer:         cli.errStream,
+		Name:            version.Name,
+		Level:           config.StringVal(conf.LogLevel),
+		Format:          config.StringVal(conf.LogFormat),
+		SubsystemLevels: conf.LogLevels,
+		Syslog:          config.BoolVal(conf.Syslog.Enabled),
+		SyslogFacility:  config.StringVal(conf.Syslog.Facility),
+		SyslogAddress:   config.StringVal(conf.Syslog.Address),
+		SyslogProtocol:  config.StringVal(conf.Syslog.Protocol),
+
+		SyslogSSLEnabled:    config.BoolVal(conf.Syslog.SSL.Enabled),
+		SyslogSSLVerify:     config.BoolVal(conf.Syslog.SSL.Verify),
+		SyslogSSLCert:       config.StringVal(conf.Syslog.SSL.Cert),
+		SyslogSSLKey:        config.StringVal(conf.Syslog.SSL.Key),
+		SyslogSSLCACert:     config.StringVal(conf.Syslog.SSL.CaCert),
+		SyslogSSLCAPath:     config.StringVal(conf.Syslog.SSL.CaPath),
+		SyslogSSLServerName: config.StringVal(conf.Syslog.SSL.ServerName),
+
+		LogFile:           logFile,
+		LogRotateBytes:    config.IntVal(conf.LogFile.RotateBytes),
+		LogRotateDuration: config.TimeDurationVal(conf.LogFile.RotateDuration),
+		LogRotateMaxFiles: config.IntVal(conf.LogFile.RotateMaxFiles),
+
+		Writer: cli.errStream,
 	}); err != nil {
 		return nil, err
 	}
 
+	// Stop any existing sinks first, since a listener cannot be bound twice.
+	cli.telemetry.Stop()
+	cli.telemetry = nil
+
+	if config.BoolVal(conf.Telemetry.Enabled) {
+		t, err := telemetry.Setup(&telemetry.Config{
+			Prefix:            config.StringVal(conf.Telemetry.MetricsPrefix),
+			DisableHostname:   config.BoolVal(conf.Telemetry.DisableHostname),
+			StatsdAddress:     config.StringVal(conf.Telemetry.StatsdAddress),
+			DogStatsdAddress:  config.StringVal(conf.Telemetry.DogStatsdAddress),
+			DogStatsdTags:     conf.Telemetry.DogStatsdTags,
+			PrometheusAddress: config.StringVal(conf.Telemetry.PrometheusAddress),
+		})
+		if err != nil {
+			return nil, err
+		}
+		cli.telemetry = t
+	}
+
 	return conf, nil
 }
 
@@ -655,6 +871,10 @@ Options:
       Enable de-duplication mode - reduces load on Consul when many instances of
       Consul Template are rendering a common template
 
+  -diff
+      Print a unified diff of each template's changes instead of its contents
+      in dry mode, and log the diff on every render otherwise
+
   -dry
       Print generated templates to stdout instead of rendering
 
@@ -678,6 +898,13 @@ Options:
   -kill-signal=<signal>
       Signal to listen to gracefully terminate the process
 
+  -log-file=<path>
+      Write logs to the file at the given path in addition to stderr - the file
+      is rotated as configured by the log_file block
+
+  -log-format=<format>
+      Set the format of log lines - values are "text" and "json"
+
   -log-level=<level>
       Set the logging level - values are "debug", "info", "warn", and "err"
 
@@ -694,15 +921,28 @@ Options:
   -reload-signal=<signal>
       Signal to listen to reload configuration
 
+  -render-timeout=<duration>
+      Maximum time to wait for each template to render for the first time,
+      before reporting its missing dependencies and exiting with an error
+
   -retry=<duration>
       The amount of time to wait if Consul returns an error when communicating
       with the API
 
+  -status-addr=<address>
+      Enable the HTTP status listener on the given address, which reports the
+      state of templates, watched dependencies, the child process, and
+      de-duplication as JSON
+
   -syslog
       Send the output to syslog instead of standard error and standard out. The
       syslog facility defaults to LOCAL0 and can be changed using a
       configuration file
 
+  -syslog-addr=<address>
+      Send syslog messages to the remote syslog server at the given address
+      instead of the local syslog socket - this implies -syslog
+
   -syslog-facility=<facility>
       Set the facility where syslog should log - if this attribute is supplied,
       the -syslog flag must also be supplied
@@ -783,6 +1023,10 @@ Options:
       Sets the 'min(:max)' amount of time to wait before writing a template (and
       triggering a command)
 
+  -watch-config
+      Reload the configuration when the configuration files or template
+      sources change on disk
+
   -v, -version
       Print the version of this daemon
 `
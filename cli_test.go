@@ -289,6 +289,14 @@ func TestCLI_ParseFlags(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"diff",
+			[]string{"-diff"},
+			&config.Config{
+				Diff: config.Bool(true),
+			},
+			false,
+		},
 		{
 			"exec",
 			[]string{"-exec", "command"},
@@ -348,6 +356,24 @@ func TestCLI_ParseFlags(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"log-file",
+			[]string{"-log-file", "/var/log/consul-template.log"},
+			&config.Config{
+				LogFile: &config.LogFileConfig{
+					Path: config.String("/var/log/consul-template.log"),
+				},
+			},
+			false,
+		},
+		{
+			"log-format",
+			[]string{"-log-format", "json"},
+			&config.Config{
+				LogFormat: config.String("json"),
+			},
+			false,
+		},
 		{
 			"log-level",
 			[]string{"-log-level", "DEBUG"},
@@ -380,6 +406,14 @@ func TestCLI_ParseFlags(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"render-timeout",
+			[]string{"-render-timeout", "30s"},
+			&config.Config{
+				RenderTimeout: config.TimeDuration(30 * time.Second),
+			},
+			false,
+		},
 		{
 			"retry",
 			[]string{"-retry", "30s"},
@@ -392,6 +426,16 @@ func TestCLI_ParseFlags(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"status-addr",
+			[]string{"-status-addr", "127.0.0.1:8502"},
+			&config.Config{
+				Status: &config.StatusConfig{
+					Address: config.String("127.0.0.1:8502"),
+				},
+			},
+			false,
+		},
 		{
 			"syslog",
 			[]string{"-syslog"},
@@ -402,6 +446,16 @@ func TestCLI_ParseFlags(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"syslog-addr",
+			[]string{"-syslog-addr", "logs.example.com:514"},
+			&config.Config{
+				Syslog: &config.SyslogConfig{
+					Address: config.String("logs.example.com:514"),
+				},
+			},
+			false,
+		},
 		{
 			"syslog-facility",
 			[]string{"-syslog-facility", "LOCAL0"},
@@ -688,6 +742,14 @@ func TestCLI_ParseFlags(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"watch_config",
+			[]string{"-watch-config"},
+			&config.Config{
+				WatchConfig: config.Bool(true),
+			},
+			false,
+		},
 	}
 
 	for i, tc := range cases {
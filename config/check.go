@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultCheckTimeout is the amount of time to wait for a check command to
+	// return.
+	DefaultCheckTimeout = 30 * time.Second
+)
+
+// CheckConfig is the configuration for validating a rendered template before
+// it replaces the file on disk.
+type CheckConfig struct {
+	// Command is the command to run to validate the rendered template. The path
+	// to the temporary file holding the new contents is available to the
+	// command as {{.TempPath}}. A non-zero exit status fails the check.
+	Command *string `mapstructure:"command"`
+
+	// Enabled controls if the check is enabled. It is enabled by default if a
+	// command or format is given.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// Format is the name of a built-in syntax check for the rendered template.
+	// Valid values are "json", "yaml" and "toml".
+	Format *string `mapstructure:"format"`
+
+	// Timeout is the maximum amount of time to wait for the command to complete.
+	Timeout *time.Duration `mapstructure:"timeout"`
+}
+
+// DefaultCheckConfig returns a configuration that is populated with the
+// default values.
+func DefaultCheckConfig() *CheckConfig {
+	return &CheckConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *CheckConfig) Copy() *CheckConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o CheckConfig
+	o.Command = c.Command
+	o.Enabled = c.Enabled
+	o.Format = c.Format
+	o.Timeout = c.Timeout
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *CheckConfig) Merge(o *CheckConfig) *CheckConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Command != nil {
+		r.Command = o.Command
+	}
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.Format != nil {
+		r.Format = o.Format
+	}
+
+	if o.Timeout != nil {
+		r.Timeout = o.Timeout
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *CheckConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(StringPresent(c.Command) || StringPresent(c.Format))
+	}
+
+	if c.Command == nil {
+		c.Command = String("")
+	}
+
+	if c.Format == nil {
+		c.Format = String("")
+	}
+
+	if c.Timeout == nil {
+		c.Timeout = TimeDuration(DefaultCheckTimeout)
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *CheckConfig) GoString() string {
+	if c == nil {
+		return "(*CheckConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&CheckConfig{"+
+		"Command:%s, "+
+		"Enabled:%s, "+
+		"Format:%s, "+
+		"Timeout:%s"+
+		"}",
+		StringGoString(c.Command),
+		BoolGoString(c.Enabled),
+		StringGoString(c.Format),
+		TimeDurationGoString(c.Timeout),
+	)
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheckConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *CheckConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&CheckConfig{},
+		},
+		{
+			"same_enabled",
+			&CheckConfig{
+				Command: String("command"),
+				Enabled: Bool(true),
+				Format:  String("json"),
+				Timeout: TimeDuration(10 * time.Second),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestCheckConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *CheckConfig
+		b    *CheckConfig
+		r    *CheckConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&CheckConfig{},
+			&CheckConfig{},
+		},
+		{
+			"nil_b",
+			&CheckConfig{},
+			nil,
+			&CheckConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&CheckConfig{},
+			&CheckConfig{},
+			&CheckConfig{},
+		},
+		{
+			"command_overrides",
+			&CheckConfig{Command: String("command")},
+			&CheckConfig{Command: String("")},
+			&CheckConfig{Command: String("")},
+		},
+		{
+			"command_empty_one",
+			&CheckConfig{Command: String("command")},
+			&CheckConfig{},
+			&CheckConfig{Command: String("command")},
+		},
+		{
+			"command_empty_two",
+			&CheckConfig{},
+			&CheckConfig{Command: String("command")},
+			&CheckConfig{Command: String("command")},
+		},
+		{
+			"command_same",
+			&CheckConfig{Command: String("command")},
+			&CheckConfig{Command: String("command")},
+			&CheckConfig{Command: String("command")},
+		},
+		{
+			"enabled_overrides",
+			&CheckConfig{Enabled: Bool(true)},
+			&CheckConfig{Enabled: Bool(false)},
+			&CheckConfig{Enabled: Bool(false)},
+		},
+		{
+			"enabled_empty_one",
+			&CheckConfig{Enabled: Bool(true)},
+			&CheckConfig{},
+			&CheckConfig{Enabled: Bool(true)},
+		},
+		{
+			"enabled_empty_two",
+			&CheckConfig{},
+			&CheckConfig{Enabled: Bool(true)},
+			&CheckConfig{Enabled: Bool(true)},
+		},
+		{
+			"enabled_same",
+			&CheckConfig{Enabled: Bool(true)},
+			&CheckConfig{Enabled: Bool(true)},
+			&CheckConfig{Enabled: Bool(true)},
+		},
+		{
+			"format_overrides",
+			&CheckConfig{Format: String("json")},
+			&CheckConfig{Format: String("")},
+			&CheckConfig{Format: String("")},
+		},
+		{
+			"format_empty_one",
+			&CheckConfig{Format: String("json")},
+			&CheckConfig{},
+			&CheckConfig{Format: String("json")},
+		},
+		{
+			"format_empty_two",
+			&CheckConfig{},
+			&CheckConfig{Format: String("json")},
+			&CheckConfig{Format: String("json")},
+		},
+		{
+			"format_same",
+			&CheckConfig{Format: String("json")},
+			&CheckConfig{Format: String("json")},
+			&CheckConfig{Format: String("json")},
+		},
+		{
+			"timeout_overrides",
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+			&CheckConfig{Timeout: TimeDuration(0)},
+			&CheckConfig{Timeout: TimeDuration(0)},
+		},
+		{
+			"timeout_empty_one",
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+			&CheckConfig{},
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+		},
+		{
+			"timeout_empty_two",
+			&CheckConfig{},
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+		},
+		{
+			"timeout_same",
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+			&CheckConfig{Timeout: TimeDuration(10 * time.Second)},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestCheckConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *CheckConfig
+		r    *CheckConfig
+	}{
+		{
+			"empty",
+			&CheckConfig{},
+			&CheckConfig{
+				Command: String(""),
+				Enabled: Bool(false),
+				Format:  String(""),
+				Timeout: TimeDuration(DefaultCheckTimeout),
+			},
+		},
+		{
+			"with_command",
+			&CheckConfig{
+				Command: String("nginx -t -c {{.TempPath}}"),
+			},
+			&CheckConfig{
+				Command: String("nginx -t -c {{.TempPath}}"),
+				Enabled: Bool(true),
+				Format:  String(""),
+				Timeout: TimeDuration(DefaultCheckTimeout),
+			},
+		},
+		{
+			"with_format",
+			&CheckConfig{
+				Format: String("json"),
+			},
+			&CheckConfig{
+				Command: String(""),
+				Enabled: Bool(true),
+				Format:  String("json"),
+				Timeout: TimeDuration(DefaultCheckTimeout),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import "fmt"
+
+const (
+	// DefaultConcurrencyLimit is the default number of instances which may run
+	// a command at the same time.
+	DefaultConcurrencyLimit = 1
+)
+
+// ConcurrencyConfig is the configuration for limiting how many instances of
+// Consul Template across the cluster run a template command at the same time,
+// using a semaphore in Consul.
+type ConcurrencyConfig struct {
+	// Enabled controls if the concurrency limit is enabled. It is enabled by
+	// default if a prefix is given.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// Limit is the number of instances which may run the command at the same
+	// time.
+	Limit *int `mapstructure:"limit"`
+
+	// Prefix is the path in the Consul KV store of the semaphore. Every instance
+	// which shares the limit must use the same prefix.
+	Prefix *string `mapstructure:"prefix"`
+}
+
+// DefaultConcurrencyConfig returns a configuration that is populated with the
+// default values.
+func DefaultConcurrencyConfig() *ConcurrencyConfig {
+	return &ConcurrencyConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *ConcurrencyConfig) Copy() *ConcurrencyConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o ConcurrencyConfig
+
+	o.Enabled = c.Enabled
+
+	o.Limit = c.Limit
+
+	o.Prefix = c.Prefix
+
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *ConcurrencyConfig) Merge(o *ConcurrencyConfig) *ConcurrencyConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.Limit != nil {
+		r.Limit = o.Limit
+	}
+
+	if o.Prefix != nil {
+		r.Prefix = o.Prefix
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *ConcurrencyConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(StringPresent(c.Prefix))
+	}
+
+	if c.Limit == nil {
+		c.Limit = Int(DefaultConcurrencyLimit)
+	}
+
+	if c.Prefix == nil {
+		c.Prefix = String("")
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *ConcurrencyConfig) GoString() string {
+	if c == nil {
+		return "(*ConcurrencyConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&ConcurrencyConfig{"+
+		"Enabled:%s, "+
+		"Limit:%s, "+
+		"Prefix:%s"+
+		"}",
+		BoolGoString(c.Enabled),
+		IntGoString(c.Limit),
+		StringGoString(c.Prefix),
+	)
+}
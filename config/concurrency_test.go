@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestConcurrencyConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *ConcurrencyConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&ConcurrencyConfig{},
+		},
+		{
+			"copy",
+			&ConcurrencyConfig{
+				Enabled: Bool(true),
+				Limit:   Int(2),
+				Prefix:  String("service/nginx/reload"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestConcurrencyConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *ConcurrencyConfig
+		b    *ConcurrencyConfig
+		r    *ConcurrencyConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&ConcurrencyConfig{},
+			&ConcurrencyConfig{},
+		},
+		{
+			"nil_b",
+			&ConcurrencyConfig{},
+			nil,
+			&ConcurrencyConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&ConcurrencyConfig{},
+			&ConcurrencyConfig{},
+			&ConcurrencyConfig{},
+		},
+		{
+			"enabled_overrides",
+			&ConcurrencyConfig{Enabled: Bool(true)},
+			&ConcurrencyConfig{Enabled: Bool(false)},
+			&ConcurrencyConfig{Enabled: Bool(false)},
+		},
+		{
+			"limit_overrides",
+			&ConcurrencyConfig{Limit: Int(1)},
+			&ConcurrencyConfig{Limit: Int(2)},
+			&ConcurrencyConfig{Limit: Int(2)},
+		},
+		{
+			"prefix_overrides",
+			&ConcurrencyConfig{Prefix: String("a")},
+			&ConcurrencyConfig{Prefix: String("b")},
+			&ConcurrencyConfig{Prefix: String("b")},
+		},
+		{
+			"prefix_empty_one",
+			&ConcurrencyConfig{Prefix: String("a")},
+			&ConcurrencyConfig{},
+			&ConcurrencyConfig{Prefix: String("a")},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestConcurrencyConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *ConcurrencyConfig
+		r    *ConcurrencyConfig
+	}{
+		{
+			"empty",
+			&ConcurrencyConfig{},
+			&ConcurrencyConfig{
+				Enabled: Bool(false),
+				Limit:   Int(DefaultConcurrencyLimit),
+				Prefix:  String(""),
+			},
+		},
+		{
+			"with_prefix",
+			&ConcurrencyConfig{
+				Prefix: String("service/nginx/reload"),
+			},
+			&ConcurrencyConfig{
+				Enabled: Bool(true),
+				Limit:   Int(DefaultConcurrencyLimit),
+				Prefix:  String("service/nginx/reload"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
@@ -23,6 +23,9 @@ const (
 	// DefaultLogLevel is the default logging level.
 	DefaultLogLevel = "WARN"
 
+	// DefaultLogFormat is the default format of log lines.
+	DefaultLogFormat = "text"
+
 	// DefaultMaxStale is the default staleness permitted. This enables stale
 	// queries by default for performance reasons.
 	DefaultMaxStale = 2 * time.Second
@@ -47,15 +50,34 @@ type Config struct {
 	// Dedup is used to configure the dedup settings
 	Dedup *DedupConfig `mapstructure:"deduplicate"`
 
+	// Diff controls if a unified diff between the file on disk and the new
+	// contents is printed in dry mode, and logged on every render otherwise.
+	Diff *bool `mapstructure:"diff"`
+
 	// Exec is the configuration for exec/supervise mode.
 	Exec *ExecConfig `mapstructure:"exec"`
 
+	// Execs is the list of processes to supervise in exec mode, in start order.
+	// These are read from the configuration file when more than one exec block
+	// is given, and are supervised alongside Exec if it has a command.
+	Execs *ExecConfigs `mapstructure:"execs"`
+
 	// KillSignal is the signal to listen for a graceful terminate event.
 	KillSignal *os.Signal `mapstructure:"kill_signal"`
 
+	// LogFile is the configuration for writing logs to a rotated file.
+	LogFile *LogFileConfig `mapstructure:"log_file"`
+
+	// LogFormat is the format of log lines, either "text" or "json".
+	LogFormat *string `mapstructure:"log_format"`
+
 	// LogLevel is the level with which to log for this config.
 	LogLevel *string `mapstructure:"log_level"`
 
+	// LogLevels is the level with which to log for each subsystem, such as
+	// "view" or "runner", overriding LogLevel.
+	LogLevels map[string]string `mapstructure:"log_levels"`
+
 	// MaxStale is the maximum amount of time for staleness from Consul as given
 	// by LastContact. If supplied, Consul Template will query all servers instead
 	// of just the leader.
@@ -68,17 +90,37 @@ type Config struct {
 	// ReloadSignal is the signal to listen for a reload event.
 	ReloadSignal *os.Signal `mapstructure:"reload_signal"`
 
+	// RenderTimeout is the maximum amount of time to wait for each template to
+	// render for the first time, before reporting its missing dependencies and
+	// returning an error. A template may set its own timeout. The default value
+	// is 0, which waits forever.
+	RenderTimeout *time.Duration `mapstructure:"render_timeout"`
+
+	// Status is the configuration for the HTTP status listener.
+	Status *StatusConfig `mapstructure:"status"`
+
 	// Syslog is the configuration for syslog.
 	Syslog *SyslogConfig `mapstructure:"syslog"`
 
+	// Telemetry is the configuration for emitting metrics.
+	Telemetry *TelemetryConfig `mapstructure:"telemetry"`
+
 	// Templates is the list of templates.
 	Templates *TemplateConfigs `mapstructure:"template"`
 
+	// TemplateGroups is the list of template groups.
+	TemplateGroups *TemplateGroupConfigs `mapstructure:"template_group"`
+
 	// Vault is the configuration for connecting to a vault server.
 	Vault *VaultConfig `mapstructure:"vault"`
 
 	// Wait is the quiescence timers.
 	Wait *WaitConfig `mapstructure:"wait"`
+
+	// WatchConfig controls if the configuration files and template sources are
+	// watched for changes, which reload the configuration as the reload signal
+	// does.
+	WatchConfig *bool `mapstructure:"watch_config"`
 }
 
 // Copy returns a deep copy of the current configuration. This is useful because
@@ -96,28 +138,61 @@ func (c *Config) Copy() *Config {
 		o.Dedup = c.Dedup.Copy()
 	}
 
+	o.Diff = c.Diff
+
 	if c.Exec != nil {
 		o.Exec = c.Exec.Copy()
 	}
 
+	if c.Execs != nil {
+		o.Execs = c.Execs.Copy()
+	}
+
 	o.KillSignal = c.KillSignal
 
+	if c.LogFile != nil {
+		o.LogFile = c.LogFile.Copy()
+	}
+
+	o.LogFormat = c.LogFormat
+
 	o.LogLevel = c.LogLevel
 
+	if c.LogLevels != nil {
+		o.LogLevels = make(map[string]string, len(c.LogLevels))
+		for k, v := range c.LogLevels {
+			o.LogLevels[k] = v
+		}
+	}
+
 	o.MaxStale = c.MaxStale
 
 	o.PidFile = c.PidFile
 
 	o.ReloadSignal = c.ReloadSignal
 
+	o.RenderTimeout = c.RenderTimeout
+
+	if c.Status != nil {
+		o.Status = c.Status.Copy()
+	}
+
 	if c.Syslog != nil {
 		o.Syslog = c.Syslog.Copy()
 	}
 
+	if c.Telemetry != nil {
+		o.Telemetry = c.Telemetry.Copy()
+	}
+
 	if c.Templates != nil {
 		o.Templates = c.Templates.Copy()
 	}
 
+	if c.TemplateGroups != nil {
+		o.TemplateGroups = c.TemplateGroups.Copy()
+	}
+
 	if c.Vault != nil {
 		o.Vault = c.Vault.Copy()
 	}
@@ -126,6 +201,8 @@ func (c *Config) Copy() *Config {
 		o.Wait = c.Wait.Copy()
 	}
 
+	o.WatchConfig = c.WatchConfig
+
 	return &o
 }
 
@@ -153,18 +230,43 @@ func (c *Config) Merge(o *Config) *Config {
 		r.Dedup = r.Dedup.Merge(o.Dedup)
 	}
 
+	if o.Diff != nil {
+		r.Diff = o.Diff
+	}
+
 	if o.Exec != nil {
 		r.Exec = r.Exec.Merge(o.Exec)
 	}
 
+	if o.Execs != nil {
+		r.Execs = r.Execs.Merge(o.Execs)
+	}
+
 	if o.KillSignal != nil {
 		r.KillSignal = o.KillSignal
 	}
 
+	if o.LogFile != nil {
+		r.LogFile = r.LogFile.Merge(o.LogFile)
+	}
+
+	if o.LogFormat != nil {
+		r.LogFormat = o.LogFormat
+	}
+
 	if o.LogLevel != nil {
 		r.LogLevel = o.LogLevel
 	}
 
+	if o.LogLevels != nil {
+		if r.LogLevels == nil {
+			r.LogLevels = make(map[string]string, len(o.LogLevels))
+		}
+		for k, v := range o.LogLevels {
+			r.LogLevels[k] = v
+		}
+	}
+
 	if o.MaxStale != nil {
 		r.MaxStale = o.MaxStale
 	}
@@ -177,14 +279,30 @@ func (c *Config) Merge(o *Config) *Config {
 		r.ReloadSignal = o.ReloadSignal
 	}
 
+	if o.RenderTimeout != nil {
+		r.RenderTimeout = o.RenderTimeout
+	}
+
+	if o.Status != nil {
+		r.Status = r.Status.Merge(o.Status)
+	}
+
 	if o.Syslog != nil {
 		r.Syslog = r.Syslog.Merge(o.Syslog)
 	}
 
+	if o.Telemetry != nil {
+		r.Telemetry = r.Telemetry.Merge(o.Telemetry)
+	}
+
 	if o.Templates != nil {
 		r.Templates = r.Templates.Merge(o.Templates)
 	}
 
+	if o.TemplateGroups != nil {
+		r.TemplateGroups = r.TemplateGroups.Merge(o.TemplateGroups)
+	}
+
 	if o.Vault != nil {
 		r.Vault = r.Vault.Merge(o.Vault)
 	}
@@ -193,6 +311,10 @@ func (c *Config) Merge(o *Config) *Config {
 		r.Wait = r.Wait.Merge(o.Wait)
 	}
 
+	if o.WatchConfig != nil {
+		r.WatchConfig = o.WatchConfig
+	}
+
 	return r
 }
 
@@ -209,6 +331,20 @@ func Parse(s string) (*Config, error) {
 		return nil, errors.New("error converting config")
 	}
 
+	// More than one exec block is a list of processes to supervise. Each is
+	// flattened like the templates below.
+	if execs, ok := parsed["exec"].([]map[string]interface{}); ok && len(execs) > 1 {
+		for _, exec := range execs {
+			flattenKeys(exec, []string{
+				"env",
+				"liveness_probe",
+				"restart",
+			})
+		}
+		parsed["execs"] = execs
+		delete(parsed, "exec")
+	}
+
 	flattenKeys(parsed, []string{
 		"auth",
 		"consul",
@@ -220,9 +356,17 @@ func Parse(s string) (*Config, error) {
 		"env",
 		"exec",
 		"exec.env",
+		"exec.liveness_probe",
+		"exec.restart",
+		"log_file",
+		"log_levels",
 		"ssl",
+		"status",
 		"syslog",
+		"syslog.ssl",
+		"telemetry",
 		"vault",
+		"vault.auth",
 		"vault.retry",
 		"vault.ssl",
 		"vault.transport",
@@ -234,14 +378,25 @@ func Parse(s string) (*Config, error) {
 	if templates, ok := parsed["template"].([]map[string]interface{}); ok {
 		for _, template := range templates {
 			flattenKeys(template, []string{
+				"check",
 				"env",
 				"exec",
+				"exec.concurrency",
 				"exec.env",
+				"retry",
 				"wait",
 			})
 		}
 	}
 
+	if groups, ok := parsed["template_group"].([]map[string]interface{}); ok {
+		for _, group := range groups {
+			flattenKeys(group, []string{
+				"check",
+			})
+		}
+	}
+
 	// Create a new, empty config
 	var c Config
 
@@ -371,29 +526,49 @@ func (c *Config) GoString() string {
 	return fmt.Sprintf("&Config{"+
 		"Consul:%#v, "+
 		"Dedup:%#v, "+
+		"Diff:%s, "+
 		"Exec:%#v, "+
+		"Execs:%#v, "+
 		"KillSignal:%s, "+
+		"LogFile:%#v, "+
+		"LogFormat:%s, "+
 		"LogLevel:%s, "+
+		"LogLevels:%q, "+
 		"MaxStale:%s, "+
 		"PidFile:%s, "+
 		"ReloadSignal:%s, "+
+		"RenderTimeout:%s, "+
+		"Status:%#v, "+
 		"Syslog:%#v, "+
+		"Telemetry:%#v, "+
 		"Templates:%#v, "+
+		"TemplateGroups:%#v, "+
 		"Vault:%#v, "+
-		"Wait:%#v"+
+		"Wait:%#v, "+
+		"WatchConfig:%s"+
 		"}",
 		c.Consul,
 		c.Dedup,
+		BoolGoString(c.Diff),
 		c.Exec,
+		c.Execs,
 		SignalGoString(c.KillSignal),
+		c.LogFile,
+		StringGoString(c.LogFormat),
 		StringGoString(c.LogLevel),
+		c.LogLevels,
 		TimeDurationGoString(c.MaxStale),
 		StringGoString(c.PidFile),
 		SignalGoString(c.ReloadSignal),
+		TimeDurationGoString(c.RenderTimeout),
+		c.Status,
 		c.Syslog,
+		c.Telemetry,
 		c.Templates,
+		c.TemplateGroups,
 		c.Vault,
 		c.Wait,
+		BoolGoString(c.WatchConfig),
 	)
 }
 
@@ -401,13 +576,18 @@ func (c *Config) GoString() string {
 // variables may be set which control the values for the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
-		Consul:    DefaultConsulConfig(),
-		Dedup:     DefaultDedupConfig(),
-		Exec:      DefaultExecConfig(),
-		Syslog:    DefaultSyslogConfig(),
-		Templates: DefaultTemplateConfigs(),
-		Vault:     DefaultVaultConfig(),
-		Wait:      DefaultWaitConfig(),
+		Consul:         DefaultConsulConfig(),
+		Dedup:          DefaultDedupConfig(),
+		Exec:           DefaultExecConfig(),
+		Execs:          DefaultExecConfigs(),
+		LogFile:        DefaultLogFileConfig(),
+		Status:         DefaultStatusConfig(),
+		Syslog:         DefaultSyslogConfig(),
+		Telemetry:      DefaultTelemetryConfig(),
+		Templates:      DefaultTemplateConfigs(),
+		TemplateGroups: DefaultTemplateGroupConfigs(),
+		Vault:          DefaultVaultConfig(),
+		Wait:           DefaultWaitConfig(),
 	}
 }
 
@@ -427,15 +607,33 @@ func (c *Config) Finalize() {
 	}
 	c.Dedup.Finalize()
 
+	if c.Diff == nil {
+		c.Diff = Bool(false)
+	}
+
 	if c.Exec == nil {
 		c.Exec = DefaultExecConfig()
 	}
 	c.Exec.Finalize()
 
+	if c.Execs == nil {
+		c.Execs = DefaultExecConfigs()
+	}
+	c.Execs.Finalize()
+
 	if c.KillSignal == nil {
 		c.KillSignal = Signal(DefaultKillSignal)
 	}
 
+	if c.LogFile == nil {
+		c.LogFile = DefaultLogFileConfig()
+	}
+	c.LogFile.Finalize()
+
+	if c.LogFormat == nil {
+		c.LogFormat = String(DefaultLogFormat)
+	}
+
 	if c.LogLevel == nil {
 		c.LogLevel = stringFromEnv([]string{
 			"CT_LOG",
@@ -443,6 +641,10 @@ func (c *Config) Finalize() {
 		}, DefaultLogLevel)
 	}
 
+	if c.LogLevels == nil {
+		c.LogLevels = make(map[string]string)
+	}
+
 	if c.MaxStale == nil {
 		c.MaxStale = TimeDuration(DefaultMaxStale)
 	}
@@ -455,16 +657,35 @@ func (c *Config) Finalize() {
 		c.ReloadSignal = Signal(DefaultReloadSignal)
 	}
 
+	if c.RenderTimeout == nil {
+		c.RenderTimeout = TimeDuration(0)
+	}
+
+	if c.Status == nil {
+		c.Status = DefaultStatusConfig()
+	}
+	c.Status.Finalize()
+
 	if c.Syslog == nil {
 		c.Syslog = DefaultSyslogConfig()
 	}
 	c.Syslog.Finalize()
 
+	if c.Telemetry == nil {
+		c.Telemetry = DefaultTelemetryConfig()
+	}
+	c.Telemetry.Finalize()
+
 	if c.Templates == nil {
 		c.Templates = DefaultTemplateConfigs()
 	}
 	c.Templates.Finalize()
 
+	if c.TemplateGroups == nil {
+		c.TemplateGroups = DefaultTemplateGroupConfigs()
+	}
+	c.TemplateGroups.Finalize()
+
 	if c.Vault == nil {
 		c.Vault = DefaultVaultConfig()
 	}
@@ -474,6 +695,10 @@ func (c *Config) Finalize() {
 		c.Wait = DefaultWaitConfig()
 	}
 	c.Wait.Finalize()
+
+	if c.WatchConfig == nil {
+		c.WatchConfig = Bool(false)
+	}
 }
 
 func stringFromEnv(list []string, def string) *string {
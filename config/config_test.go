@@ -299,6 +299,14 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"diff",
+			`diff = true`,
+			&Config{
+				Diff: Bool(true),
+			},
+			false,
+		},
 		{
 			"exec",
 			`exec {}`,
@@ -431,6 +439,26 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"exec_liveness_probe",
+			`exec {
+				liveness_probe {
+					http              = "http://127.0.0.1:8080/health"
+					interval          = "5s"
+					failure_threshold = 2
+				}
+			 }`,
+			&Config{
+				Exec: &ExecConfig{
+					LivenessProbe: &ProbeConfig{
+						FailureThreshold: Int(2),
+						HTTP:             String("http://127.0.0.1:8080/health"),
+						Interval:         TimeDuration(5 * time.Second),
+					},
+				},
+			},
+			false,
+		},
 		{
 			"exec_reload_signal",
 			`exec {
@@ -443,6 +471,60 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"exec_restart",
+			`exec {
+				restart {
+					policy       = "on-failure"
+					max_attempts = 3
+					backoff      = "2s"
+				}
+			 }`,
+			&Config{
+				Exec: &ExecConfig{
+					Restart: &RestartConfig{
+						Backoff:     TimeDuration(2 * time.Second),
+						MaxAttempts: Int(3),
+						Policy:      String("on-failure"),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"exec_multiple",
+			`exec {
+				name    = "app"
+				command = "app"
+				templates = ["/etc/app.conf"]
+			}
+			exec {
+				name     = "sidecar"
+				command  = "sidecar"
+				critical = false
+				env {
+					pristine = true
+				}
+			}`,
+			&Config{
+				Execs: &ExecConfigs{
+					&ExecConfig{
+						Command:   String("app"),
+						Name:      String("app"),
+						Templates: []string{"/etc/app.conf"},
+					},
+					&ExecConfig{
+						Command:  String("sidecar"),
+						Critical: Bool(false),
+						Env: &EnvConfig{
+							Pristine: Bool(true),
+						},
+						Name: String("sidecar"),
+					},
+				},
+			},
+			false,
+		},
 		{
 			"exec_splay",
 			`exec {
@@ -475,6 +557,32 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"log_file",
+			`log_file {
+				path = "/var/log/consul-template.log"
+				rotate_bytes = 1048576
+				rotate_duration = "1h"
+				rotate_max_files = 5
+			}`,
+			&Config{
+				LogFile: &LogFileConfig{
+					Path:           String("/var/log/consul-template.log"),
+					RotateBytes:    Int(1048576),
+					RotateDuration: TimeDuration(1 * time.Hour),
+					RotateMaxFiles: Int(5),
+				},
+			},
+			false,
+		},
+		{
+			"log_format",
+			`log_format = "json"`,
+			&Config{
+				LogFormat: String("json"),
+			},
+			false,
+		},
 		{
 			"log_level",
 			`log_level = "WARN"`,
@@ -483,6 +591,20 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"log_levels",
+			`log_levels {
+				view   = "trace"
+				runner = "debug"
+			}`,
+			&Config{
+				LogLevels: map[string]string{
+					"view":   "trace",
+					"runner": "debug",
+				},
+			},
+			false,
+		},
 		{
 			"max_stale",
 			`max_stale = "10s"`,
@@ -507,6 +629,34 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"render_timeout",
+			`render_timeout = "30s"`,
+			&Config{
+				RenderTimeout: TimeDuration(30 * time.Second),
+			},
+			false,
+		},
+		{
+			"status",
+			`status {}`,
+			&Config{
+				Status: &StatusConfig{},
+			},
+			false,
+		},
+		{
+			"status_address",
+			`status {
+				address = "127.0.0.1:8502"
+			}`,
+			&Config{
+				Status: &StatusConfig{
+					Address: String("127.0.0.1:8502"),
+				},
+			},
+			false,
+		},
 		{
 			"syslog",
 			`syslog {}`,
@@ -539,6 +689,84 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"syslog_address",
+			`syslog {
+				address = "logs.example.com:514"
+				protocol = "tcp"
+			}`,
+			&Config{
+				Syslog: &SyslogConfig{
+					Address:  String("logs.example.com:514"),
+					Protocol: String("tcp"),
+				},
+			},
+			false,
+		},
+		{
+			"syslog_ssl",
+			`syslog {
+				ssl {
+					enabled = true
+					ca_cert = "ca.pem"
+				}
+			}`,
+			&Config{
+				Syslog: &SyslogConfig{
+					SSL: &SSLConfig{
+						Enabled: Bool(true),
+						CaCert:  String("ca.pem"),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"telemetry",
+			`telemetry {}`,
+			&Config{
+				Telemetry: &TelemetryConfig{},
+			},
+			false,
+		},
+		{
+			"telemetry_statsd_address",
+			`telemetry {
+				statsd_address = "127.0.0.1:8125"
+			}`,
+			&Config{
+				Telemetry: &TelemetryConfig{
+					StatsdAddress: String("127.0.0.1:8125"),
+				},
+			},
+			false,
+		},
+		{
+			"telemetry_dogstatsd",
+			`telemetry {
+				dogstatsd_address = "127.0.0.1:8125"
+				dogstatsd_tags    = ["env:prod", "region:us"]
+			}`,
+			&Config{
+				Telemetry: &TelemetryConfig{
+					DogStatsdAddress: String("127.0.0.1:8125"),
+					DogStatsdTags:    []string{"env:prod", "region:us"},
+				},
+			},
+			false,
+		},
+		{
+			"telemetry_prometheus_address",
+			`telemetry {
+				prometheus_address = "127.0.0.1:9102"
+			}`,
+			&Config{
+				Telemetry: &TelemetryConfig{
+					PrometheusAddress: String("127.0.0.1:9102"),
+				},
+			},
+			false,
+		},
 		{
 			"template",
 			`template {}`,
@@ -575,6 +803,28 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"template_check",
+			`template {
+				check {
+					command = "nginx -t -c {{.TempPath}}"
+					format  = "json"
+					timeout = "10s"
+				}
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						Check: &CheckConfig{
+							Command: String("nginx -t -c {{.TempPath}}"),
+							Format:  String("json"),
+							Timeout: TimeDuration(10 * time.Second),
+						},
+					},
+				},
+			},
+			false,
+		},
 		{
 			"template_command",
 			`template {
@@ -892,6 +1142,20 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"template_group",
+			`template {
+				group = "nobody"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						Group: String("nobody"),
+					},
+				},
+			},
+			false,
+		},
 		{
 			"template_perms",
 			`template {
@@ -906,6 +1170,150 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"template_exec_concurrency",
+			`template {
+				exec {
+					command = "service nginx reload"
+					concurrency {
+						prefix = "service/nginx/reload"
+						limit  = 2
+					}
+				}
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						Exec: &ExecConfig{
+							Command: String("service nginx reload"),
+							Concurrency: &ConcurrencyConfig{
+								Limit:  Int(2),
+								Prefix: String("service/nginx/reload"),
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_on_failure",
+			`template {
+				on_failure = "restore_backup"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						OnFailure: String(OnFailureRestoreBackup),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_parallel_group",
+			`template {
+				parallel_group = "reload"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						ParallelGroup: String("reload"),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_preserve_ownership",
+			`template {
+				preserve_ownership = false
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						PreserveOwnership: Bool(false),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_reload_child",
+			`template {
+				reload_child = false
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						ReloadChild: Bool(false),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_reload_child_signal",
+			`template {
+				reload_child_signal = "SIGUSR1"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						ReloadChildSignal: Signal(syscall.SIGUSR1),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_render_timeout",
+			`template {
+				render_timeout = "10s"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						RenderTimeout: TimeDuration(10 * time.Second),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_restart_child",
+			`template {
+				restart_child = true
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						RestartChild: Bool(true),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_retry",
+			`template {
+				retry {
+					attempts = 3
+					backoff  = "1s"
+				}
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						Retry: &RetryConfig{
+							Attempts: Int(3),
+							Backoff:  TimeDuration(1 * time.Second),
+						},
+					},
+				},
+			},
+			false,
+		},
 		{
 			"template_source",
 			`template {
@@ -920,6 +1328,78 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"template_template_group",
+			`template {
+				template_group = "haproxy"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						TemplateGroup: String("haproxy"),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_group",
+			`template_group {
+				name = "haproxy"
+				path = "/etc/haproxy/conf"
+				keep = 5
+				check {
+					command = "haproxy -c -f {{.TempPath}}/haproxy.cfg"
+				}
+			}`,
+			&Config{
+				TemplateGroups: &TemplateGroupConfigs{
+					&TemplateGroupConfig{
+						Check: &CheckConfig{
+							Command: String("haproxy -c -f {{.TempPath}}/haproxy.cfg"),
+						},
+						Keep: Int(5),
+						Name: String("haproxy"),
+						Path: String("/etc/haproxy/conf"),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_group_multi",
+			`template_group {
+				name = "a"
+			}
+			template_group {
+				name = "b"
+			}`,
+			&Config{
+				TemplateGroups: &TemplateGroupConfigs{
+					&TemplateGroupConfig{
+						Name: String("a"),
+					},
+					&TemplateGroupConfig{
+						Name: String("b"),
+					},
+				},
+			},
+			false,
+		},
+		{
+			"template_user",
+			`template {
+				user = "nobody"
+			}`,
+			&Config{
+				Templates: &TemplateConfigs{
+					&TemplateConfig{
+						User: String("nobody"),
+					},
+				},
+			},
+			false,
+		},
 		{
 			"template_wait",
 			`template {
@@ -993,6 +1473,26 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"vault_auth",
+			`vault {
+				auth {
+					method         = "approle"
+					role_id_file   = "/path/to/role_id"
+					secret_id_file = "/path/to/secret_id"
+				}
+			}`,
+			&Config{
+				Vault: &VaultConfig{
+					Auth: &VaultAuthConfig{
+						Method:       String("approle"),
+						RoleIDFile:   String("/path/to/role_id"),
+						SecretIDFile: String("/path/to/secret_id"),
+					},
+				},
+			},
+			false,
+		},
 		{
 			"vault_enabled",
 			`vault {
@@ -1360,6 +1860,14 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"watch_config",
+			`watch_config = true`,
+			&Config{
+				WatchConfig: Bool(true),
+			},
+			false,
+		},
 
 		// Parse JSON file permissions as a string. There is a mapstructure
 		// function for testing this, but this is double-tested because it has
@@ -1505,6 +2013,18 @@ func TestConfig_Merge(t *testing.T) {
 				},
 			},
 		},
+		{
+			"diff",
+			&Config{
+				Diff: Bool(true),
+			},
+			&Config{
+				Diff: Bool(false),
+			},
+			&Config{
+				Diff: Bool(false),
+			},
+		},
 		{
 			"exec",
 			&Config{
@@ -1535,6 +2055,37 @@ func TestConfig_Merge(t *testing.T) {
 				KillSignal: Signal(syscall.SIGUSR2),
 			},
 		},
+		{
+			"log_file",
+			&Config{
+				LogFile: &LogFileConfig{
+					Path: String("a.log"),
+				},
+			},
+			&Config{
+				LogFile: &LogFileConfig{
+					RotateMaxFiles: Int(3),
+				},
+			},
+			&Config{
+				LogFile: &LogFileConfig{
+					Path:           String("a.log"),
+					RotateMaxFiles: Int(3),
+				},
+			},
+		},
+		{
+			"log_format",
+			&Config{
+				LogFormat: String("text"),
+			},
+			&Config{
+				LogFormat: String("json"),
+			},
+			&Config{
+				LogFormat: String("json"),
+			},
+		},
 		{
 			"log_level",
 			&Config{
@@ -1547,6 +2098,18 @@ func TestConfig_Merge(t *testing.T) {
 				LogLevel: String("log_level-diff"),
 			},
 		},
+		{
+			"log_levels",
+			&Config{
+				LogLevels: map[string]string{"view": "trace", "runner": "info"},
+			},
+			&Config{
+				LogLevels: map[string]string{"runner": "debug"},
+			},
+			&Config{
+				LogLevels: map[string]string{"view": "trace", "runner": "debug"},
+			},
+		},
 		{
 			"max_stale",
 			&Config{
@@ -1583,6 +2146,24 @@ func TestConfig_Merge(t *testing.T) {
 				ReloadSignal: Signal(syscall.SIGUSR2),
 			},
 		},
+		{
+			"status",
+			&Config{
+				Status: &StatusConfig{
+					Address: String("127.0.0.1:8501"),
+				},
+			},
+			&Config{
+				Status: &StatusConfig{
+					Address: String("127.0.0.1:8502"),
+				},
+			},
+			&Config{
+				Status: &StatusConfig{
+					Address: String("127.0.0.1:8502"),
+				},
+			},
+		},
 		{
 			"syslog",
 			&Config{
@@ -1601,6 +2182,24 @@ func TestConfig_Merge(t *testing.T) {
 				},
 			},
 		},
+		{
+			"telemetry",
+			&Config{
+				Telemetry: &TelemetryConfig{
+					StatsdAddress: String("127.0.0.1:8125"),
+				},
+			},
+			&Config{
+				Telemetry: &TelemetryConfig{
+					StatsdAddress: String("127.0.0.1:8126"),
+				},
+			},
+			&Config{
+				Telemetry: &TelemetryConfig{
+					StatsdAddress: String("127.0.0.1:8126"),
+				},
+			},
+		},
 		{
 			"template_configs",
 			&Config{
@@ -1667,6 +2266,18 @@ func TestConfig_Merge(t *testing.T) {
 				},
 			},
 		},
+		{
+			"watch_config",
+			&Config{
+				WatchConfig: Bool(true),
+			},
+			&Config{
+				WatchConfig: Bool(false),
+			},
+			&Config{
+				WatchConfig: Bool(false),
+			},
+		},
 	}
 
 	for i, tc := range cases {
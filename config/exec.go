@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -34,6 +35,16 @@ type ExecConfig struct {
 	// Command is the command to execute and watch as a child process.
 	Command *string `mapstructure:"command"`
 
+	// Concurrency limits how many instances of Consul Template across the
+	// cluster run the command at the same time. This only applies to the
+	// command of a template.
+	Concurrency *ConcurrencyConfig `mapstructure:"concurrency"`
+
+	// Critical controls if the exit of this process stops Consul Template, once
+	// any restart policy has given up. When false, the other processes keep
+	// running. This only applies in exec mode.
+	Critical *bool `mapstructure:"critical"`
+
 	// Enabled controls if this exec is enabled.
 	Enabled *bool `mapstructure:"enabled"`
 
@@ -48,15 +59,33 @@ type ExecConfig struct {
 	// hard-killing it.
 	KillTimeout *time.Duration `mapstructure:"kill_timeout"`
 
+	// LivenessProbe is the configuration for probing the child process while it
+	// runs. When the probe fails, the process is killed and restarted. This
+	// only applies in exec mode.
+	LivenessProbe *ProbeConfig `mapstructure:"liveness_probe"`
+
+	// Name is the name of the process, used in logs and the status endpoint.
+	// Names must be unique when there is more than one exec block.
+	Name *string `mapstructure:"name"`
+
 	// ReloadSignal is the signal to send to the child process when a template
 	// changes. This tells the child process that templates have
 	ReloadSignal *os.Signal `mapstructure:"reload_signal"`
 
+	// Restart is the configuration for restarting the child process when it
+	// exits. This only applies in exec mode.
+	Restart *RestartConfig `mapstructure:"restart"`
+
 	// Splay is the maximum amount of random time to wait to signal or kill the
 	// process. By default this is disabled, but it can be set to low values to
 	// reduce the "thundering herd" problem where all tasks are restarted at once.
 	Splay *time.Duration `mapstructure:"splay"`
 
+	// Templates is the list of destinations of the templates whose changes
+	// reload this process. By default, a change to any template reloads it. This
+	// only applies in exec mode.
+	Templates []string `mapstructure:"templates"`
+
 	// Timeout is the maximum amount of time to wait for a command to complete.
 	// By default, this is 0, which means "wait forever".
 	Timeout *time.Duration `mapstructure:"timeout"`
@@ -66,7 +95,10 @@ type ExecConfig struct {
 // default values.
 func DefaultExecConfig() *ExecConfig {
 	return &ExecConfig{
-		Env: DefaultEnvConfig(),
+		Concurrency:   DefaultConcurrencyConfig(),
+		Env:           DefaultEnvConfig(),
+		LivenessProbe: DefaultProbeConfig(),
+		Restart:       DefaultRestartConfig(),
 	}
 }
 
@@ -80,6 +112,12 @@ func (c *ExecConfig) Copy() *ExecConfig {
 
 	o.Command = c.Command
 
+	if c.Concurrency != nil {
+		o.Concurrency = c.Concurrency.Copy()
+	}
+
+	o.Critical = c.Critical
+
 	o.Enabled = c.Enabled
 
 	if c.Env != nil {
@@ -90,10 +128,24 @@ func (c *ExecConfig) Copy() *ExecConfig {
 
 	o.KillTimeout = c.KillTimeout
 
+	if c.LivenessProbe != nil {
+		o.LivenessProbe = c.LivenessProbe.Copy()
+	}
+
+	o.Name = c.Name
+
 	o.ReloadSignal = c.ReloadSignal
 
+	if c.Restart != nil {
+		o.Restart = c.Restart.Copy()
+	}
+
 	o.Splay = c.Splay
 
+	if c.Templates != nil {
+		o.Templates = append([]string{}, c.Templates...)
+	}
+
 	o.Timeout = c.Timeout
 
 	return &o
@@ -121,6 +173,14 @@ func (c *ExecConfig) Merge(o *ExecConfig) *ExecConfig {
 		r.Command = o.Command
 	}
 
+	if o.Concurrency != nil {
+		r.Concurrency = r.Concurrency.Merge(o.Concurrency)
+	}
+
+	if o.Critical != nil {
+		r.Critical = o.Critical
+	}
+
 	if o.Enabled != nil {
 		r.Enabled = o.Enabled
 	}
@@ -137,14 +197,30 @@ func (c *ExecConfig) Merge(o *ExecConfig) *ExecConfig {
 		r.KillTimeout = o.KillTimeout
 	}
 
+	if o.LivenessProbe != nil {
+		r.LivenessProbe = r.LivenessProbe.Merge(o.LivenessProbe)
+	}
+
+	if o.Name != nil {
+		r.Name = o.Name
+	}
+
 	if o.ReloadSignal != nil {
 		r.ReloadSignal = o.ReloadSignal
 	}
 
+	if o.Restart != nil {
+		r.Restart = r.Restart.Merge(o.Restart)
+	}
+
 	if o.Splay != nil {
 		r.Splay = o.Splay
 	}
 
+	if o.Templates != nil {
+		r.Templates = append(r.Templates, o.Templates...)
+	}
+
 	if o.Timeout != nil {
 		r.Timeout = o.Timeout
 	}
@@ -162,6 +238,15 @@ func (c *ExecConfig) Finalize() {
 		c.Command = String("")
 	}
 
+	if c.Concurrency == nil {
+		c.Concurrency = DefaultConcurrencyConfig()
+	}
+	c.Concurrency.Finalize()
+
+	if c.Critical == nil {
+		c.Critical = Bool(true)
+	}
+
 	if c.Env == nil {
 		c.Env = DefaultEnvConfig()
 	}
@@ -175,14 +260,32 @@ func (c *ExecConfig) Finalize() {
 		c.KillTimeout = TimeDuration(DefaultExecKillTimeout)
 	}
 
+	if c.LivenessProbe == nil {
+		c.LivenessProbe = DefaultProbeConfig()
+	}
+	c.LivenessProbe.Finalize()
+
+	if c.Name == nil {
+		c.Name = String("")
+	}
+
 	if c.ReloadSignal == nil {
 		c.ReloadSignal = Signal(DefaultExecReloadSignal)
 	}
 
+	if c.Restart == nil {
+		c.Restart = DefaultRestartConfig()
+	}
+	c.Restart.Finalize()
+
 	if c.Splay == nil {
 		c.Splay = TimeDuration(0 * time.Second)
 	}
 
+	if c.Templates == nil {
+		c.Templates = []string{}
+	}
+
 	if c.Timeout == nil {
 		c.Timeout = TimeDuration(DefaultExecTimeout)
 	}
@@ -196,21 +299,112 @@ func (c *ExecConfig) GoString() string {
 
 	return fmt.Sprintf("&ExecConfig{"+
 		"Command:%s, "+
+		"Concurrency:%#v, "+
+		"Critical:%s, "+
 		"Enabled:%s, "+
 		"Env:%#v, "+
 		"KillSignal:%s, "+
 		"KillTimeout:%s, "+
+		"LivenessProbe:%#v, "+
+		"Name:%s, "+
 		"ReloadSignal:%s, "+
+		"Restart:%#v, "+
 		"Splay:%s, "+
+		"Templates:%v, "+
 		"Timeout:%s"+
 		"}",
 		StringGoString(c.Command),
+		c.Concurrency,
+		BoolGoString(c.Critical),
 		BoolGoString(c.Enabled),
 		c.Env,
 		SignalGoString(c.KillSignal),
 		TimeDurationGoString(c.KillTimeout),
+		c.LivenessProbe,
+		StringGoString(c.Name),
 		SignalGoString(c.ReloadSignal),
+		c.Restart,
 		TimeDurationGoString(c.Splay),
+		c.Templates,
 		TimeDurationGoString(c.Timeout),
 	)
 }
+
+// Display is the human-friendly form of this configuration.
+func (c *ExecConfig) Display() string {
+	if c == nil {
+		return ""
+	}
+
+	if name := StringVal(c.Name); name != "" {
+		return fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("%q", StringVal(c.Command))
+}
+
+// ExecConfigs is a collection of ExecConfigs.
+type ExecConfigs []*ExecConfig
+
+// DefaultExecConfigs returns a configuration that is populated with the
+// default values.
+func DefaultExecConfigs() *ExecConfigs {
+	return &ExecConfigs{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *ExecConfigs) Copy() *ExecConfigs {
+	o := make(ExecConfigs, len(*c))
+	for i, t := range *c {
+		o[i] = t.Copy()
+	}
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *ExecConfigs) Merge(o *ExecConfigs) *ExecConfigs {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	*r = append(*r, *o...)
+
+	return r
+}
+
+// Finalize ensures the configuration has no nil pointers and sets default
+// values.
+func (c *ExecConfigs) Finalize() {
+	if c == nil {
+		*c = *DefaultExecConfigs()
+	}
+
+	for _, t := range *c {
+		t.Finalize()
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *ExecConfigs) GoString() string {
+	if c == nil {
+		return "(*ExecConfigs)(nil)"
+	}
+
+	s := make([]string, len(*c))
+	for i, t := range *c {
+		s[i] = t.GoString()
+	}
+
+	return "{" + strings.Join(s, ", ") + "}"
+}
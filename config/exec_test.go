@@ -25,12 +25,16 @@ func TestExecConfig_Copy(t *testing.T) {
 			"copy",
 			&ExecConfig{
 				Command:      String("command"),
+				Concurrency:  &ConcurrencyConfig{Limit: Int(2)},
+				Critical:     Bool(false),
 				Enabled:      Bool(true),
 				Env:          &EnvConfig{Pristine: Bool(true)},
 				KillSignal:   Signal(syscall.SIGINT),
 				KillTimeout:  TimeDuration(10 * time.Second),
+				Name:         String("name"),
 				ReloadSignal: Signal(syscall.SIGINT),
 				Splay:        TimeDuration(10 * time.Second),
+				Templates:    []string{"/a", "/b"},
 				Timeout:      TimeDuration(10 * time.Second),
 			},
 		},
@@ -89,6 +93,12 @@ func TestExecConfig_Merge(t *testing.T) {
 			&ExecConfig{},
 			&ExecConfig{Command: String("command")},
 		},
+		{
+			"concurrency_merges",
+			&ExecConfig{Concurrency: &ConcurrencyConfig{Prefix: String("a")}},
+			&ExecConfig{Concurrency: &ConcurrencyConfig{Limit: Int(2)}},
+			&ExecConfig{Concurrency: &ConcurrencyConfig{Limit: Int(2), Prefix: String("a")}},
+		},
 		{
 			"command_empty_two",
 			&ExecConfig{},
@@ -149,6 +159,24 @@ func TestExecConfig_Merge(t *testing.T) {
 			&ExecConfig{Env: &EnvConfig{Pristine: Bool(true)}},
 			&ExecConfig{Env: &EnvConfig{Pristine: Bool(true)}},
 		},
+		{
+			"critical_overrides",
+			&ExecConfig{Critical: Bool(true)},
+			&ExecConfig{Critical: Bool(false)},
+			&ExecConfig{Critical: Bool(false)},
+		},
+		{
+			"name_overrides",
+			&ExecConfig{Name: String("a")},
+			&ExecConfig{Name: String("b")},
+			&ExecConfig{Name: String("b")},
+		},
+		{
+			"templates_appends",
+			&ExecConfig{Templates: []string{"/a"}},
+			&ExecConfig{Templates: []string{"/b"}},
+			&ExecConfig{Templates: []string{"/a", "/b"}},
+		},
 		{
 			"kill_signal_overrides",
 			&ExecConfig{KillSignal: Signal(syscall.SIGINT)},
@@ -292,18 +320,42 @@ func TestExecConfig_Finalize(t *testing.T) {
 			&ExecConfig{},
 			&ExecConfig{
 				Command: String(""),
-				Enabled: Bool(false),
+				Concurrency: &ConcurrencyConfig{
+					Enabled: Bool(false),
+					Limit:   Int(DefaultConcurrencyLimit),
+					Prefix:  String(""),
+				},
+				Critical: Bool(true),
+				Enabled:  Bool(false),
 				Env: &EnvConfig{
 					Blacklist: []string{},
 					Custom:    []string{},
 					Pristine:  Bool(false),
 					Whitelist: []string{},
 				},
-				KillSignal:   Signal(DefaultExecKillSignal),
-				KillTimeout:  TimeDuration(DefaultExecKillTimeout),
+				KillSignal:  Signal(DefaultExecKillSignal),
+				KillTimeout: TimeDuration(DefaultExecKillTimeout),
+				LivenessProbe: &ProbeConfig{
+					Command:          String(""),
+					Enabled:          Bool(false),
+					FailureThreshold: Int(DefaultProbeFailureThreshold),
+					HTTP:             String(""),
+					InitialDelay:     TimeDuration(0),
+					Interval:         TimeDuration(DefaultProbeInterval),
+					TCP:              String(""),
+					Timeout:          TimeDuration(DefaultProbeTimeout),
+				},
+				Name:         String(""),
 				ReloadSignal: Signal(DefaultExecReloadSignal),
-				Splay:        TimeDuration(0 * time.Second),
-				Timeout:      TimeDuration(DefaultExecTimeout),
+				Restart: &RestartConfig{
+					Backoff:     TimeDuration(DefaultRestartBackoff),
+					MaxAttempts: Int(DefaultRestartMaxAttempts),
+					MaxBackoff:  TimeDuration(DefaultRestartMaxBackoff),
+					Policy:      String(RestartPolicyNever),
+				},
+				Splay:     TimeDuration(0 * time.Second),
+				Templates: []string{},
+				Timeout:   TimeDuration(DefaultExecTimeout),
 			},
 		},
 		{
@@ -313,18 +365,42 @@ func TestExecConfig_Finalize(t *testing.T) {
 			},
 			&ExecConfig{
 				Command: String("command"),
-				Enabled: Bool(true),
+				Concurrency: &ConcurrencyConfig{
+					Enabled: Bool(false),
+					Limit:   Int(DefaultConcurrencyLimit),
+					Prefix:  String(""),
+				},
+				Critical: Bool(true),
+				Enabled:  Bool(true),
 				Env: &EnvConfig{
 					Blacklist: []string{},
 					Custom:    []string{},
 					Pristine:  Bool(false),
 					Whitelist: []string{},
 				},
-				KillSignal:   Signal(DefaultExecKillSignal),
-				KillTimeout:  TimeDuration(DefaultExecKillTimeout),
+				KillSignal:  Signal(DefaultExecKillSignal),
+				KillTimeout: TimeDuration(DefaultExecKillTimeout),
+				LivenessProbe: &ProbeConfig{
+					Command:          String(""),
+					Enabled:          Bool(false),
+					FailureThreshold: Int(DefaultProbeFailureThreshold),
+					HTTP:             String(""),
+					InitialDelay:     TimeDuration(0),
+					Interval:         TimeDuration(DefaultProbeInterval),
+					TCP:              String(""),
+					Timeout:          TimeDuration(DefaultProbeTimeout),
+				},
+				Name:         String(""),
 				ReloadSignal: Signal(DefaultExecReloadSignal),
-				Splay:        TimeDuration(0 * time.Second),
-				Timeout:      TimeDuration(DefaultExecTimeout),
+				Restart: &RestartConfig{
+					Backoff:     TimeDuration(DefaultRestartBackoff),
+					MaxAttempts: Int(DefaultRestartMaxAttempts),
+					MaxBackoff:  TimeDuration(DefaultRestartMaxBackoff),
+					Policy:      String(RestartPolicyNever),
+				},
+				Splay:     TimeDuration(0 * time.Second),
+				Templates: []string{},
+				Timeout:   TimeDuration(DefaultExecTimeout),
 			},
 		},
 	}
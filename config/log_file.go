@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultLogRotateDuration is the default amount of time a log file is
+	// written to before it is rotated.
+	DefaultLogRotateDuration = 24 * time.Hour
+)
+
+// LogFileConfig is the configuration for writing logs to a file, which is
+// rotated once it grows too large or too old.
+type LogFileConfig struct {
+	// Enabled controls whether logs are written to the file.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// Path is the path of the file logs are written to. Rotated files are kept
+	// next to it, with the time of the rotation in their name.
+	Path *string `mapstructure:"path"`
+
+	// RotateBytes is the size in bytes a log file may grow to before it is
+	// rotated. The default value is 0, which does not rotate on size.
+	RotateBytes *int `mapstructure:"rotate_bytes"`
+
+	// RotateDuration is the amount of time a log file is written to before it is
+	// rotated. A value of 0 does not rotate on age.
+	RotateDuration *time.Duration `mapstructure:"rotate_duration"`
+
+	// RotateMaxFiles is the number of rotated files to keep, removing the oldest
+	// first. The default value is 0, which keeps every file.
+	RotateMaxFiles *int `mapstructure:"rotate_max_files"`
+}
+
+// DefaultLogFileConfig returns a configuration that is populated with the
+// default values.
+func DefaultLogFileConfig() *LogFileConfig {
+	return &LogFileConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *LogFileConfig) Copy() *LogFileConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o LogFileConfig
+	o.Enabled = c.Enabled
+	o.Path = c.Path
+	o.RotateBytes = c.RotateBytes
+	o.RotateDuration = c.RotateDuration
+	o.RotateMaxFiles = c.RotateMaxFiles
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *LogFileConfig) Merge(o *LogFileConfig) *LogFileConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.Path != nil {
+		r.Path = o.Path
+	}
+
+	if o.RotateBytes != nil {
+		r.RotateBytes = o.RotateBytes
+	}
+
+	if o.RotateDuration != nil {
+		r.RotateDuration = o.RotateDuration
+	}
+
+	if o.RotateMaxFiles != nil {
+		r.RotateMaxFiles = o.RotateMaxFiles
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *LogFileConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(StringPresent(c.Path))
+	}
+
+	if c.Path == nil {
+		c.Path = String("")
+	}
+
+	if c.RotateBytes == nil {
+		c.RotateBytes = Int(0)
+	}
+
+	if c.RotateDuration == nil {
+		c.RotateDuration = TimeDuration(DefaultLogRotateDuration)
+	}
+
+	if c.RotateMaxFiles == nil {
+		c.RotateMaxFiles = Int(0)
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *LogFileConfig) GoString() string {
+	if c == nil {
+		return "(*LogFileConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&LogFileConfig{"+
+		"Enabled:%s, "+
+		"Path:%s, "+
+		"RotateBytes:%s, "+
+		"RotateDuration:%s, "+
+		"RotateMaxFiles:%s"+
+		"}",
+		BoolGoString(c.Enabled),
+		StringGoString(c.Path),
+		IntGoString(c.RotateBytes),
+		TimeDurationGoString(c.RotateDuration),
+		IntGoString(c.RotateMaxFiles),
+	)
+}
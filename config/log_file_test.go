@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogFileConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *LogFileConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&LogFileConfig{},
+		},
+		{
+			"same_enabled",
+			&LogFileConfig{
+				Enabled:        Bool(true),
+				Path:           String("/var/log/consul-template.log"),
+				RotateBytes:    Int(1024),
+				RotateDuration: TimeDuration(time.Hour),
+				RotateMaxFiles: Int(5),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestLogFileConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *LogFileConfig
+		b    *LogFileConfig
+		r    *LogFileConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&LogFileConfig{},
+			&LogFileConfig{},
+		},
+		{
+			"nil_b",
+			&LogFileConfig{},
+			nil,
+			&LogFileConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&LogFileConfig{},
+			&LogFileConfig{},
+			&LogFileConfig{},
+		},
+		{
+			"enabled_overrides",
+			&LogFileConfig{Enabled: Bool(true)},
+			&LogFileConfig{Enabled: Bool(false)},
+			&LogFileConfig{Enabled: Bool(false)},
+		},
+		{
+			"path_overrides",
+			&LogFileConfig{Path: String("a.log")},
+			&LogFileConfig{Path: String("b.log")},
+			&LogFileConfig{Path: String("b.log")},
+		},
+		{
+			"path_empty_one",
+			&LogFileConfig{Path: String("a.log")},
+			&LogFileConfig{},
+			&LogFileConfig{Path: String("a.log")},
+		},
+		{
+			"rotate_bytes_overrides",
+			&LogFileConfig{RotateBytes: Int(10)},
+			&LogFileConfig{RotateBytes: Int(0)},
+			&LogFileConfig{RotateBytes: Int(0)},
+		},
+		{
+			"rotate_duration_overrides",
+			&LogFileConfig{RotateDuration: TimeDuration(time.Hour)},
+			&LogFileConfig{RotateDuration: TimeDuration(time.Minute)},
+			&LogFileConfig{RotateDuration: TimeDuration(time.Minute)},
+		},
+		{
+			"rotate_max_files_empty_two",
+			&LogFileConfig{},
+			&LogFileConfig{RotateMaxFiles: Int(3)},
+			&LogFileConfig{RotateMaxFiles: Int(3)},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestLogFileConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *LogFileConfig
+		r    *LogFileConfig
+	}{
+		{
+			"empty",
+			&LogFileConfig{},
+			&LogFileConfig{
+				Enabled:        Bool(false),
+				Path:           String(""),
+				RotateBytes:    Int(0),
+				RotateDuration: TimeDuration(DefaultLogRotateDuration),
+				RotateMaxFiles: Int(0),
+			},
+		},
+		{
+			"with_path",
+			&LogFileConfig{
+				Path: String("/var/log/consul-template.log"),
+			},
+			&LogFileConfig{
+				Enabled:        Bool(true),
+				Path:           String("/var/log/consul-template.log"),
+				RotateBytes:    Int(0),
+				RotateDuration: TimeDuration(DefaultLogRotateDuration),
+				RotateMaxFiles: Int(0),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
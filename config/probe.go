@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultProbeFailureThreshold is the default number of consecutive failed
+	// probes after which the child process is restarted.
+	DefaultProbeFailureThreshold = 3
+
+	// DefaultProbeInterval is the default amount of time between probes.
+	DefaultProbeInterval = 10 * time.Second
+
+	// DefaultProbeTimeout is the default amount of time to wait for a probe to
+	// succeed.
+	DefaultProbeTimeout = 5 * time.Second
+)
+
+// ProbeConfig is the configuration for probing the liveness of the child
+// process in exec mode. Exactly one of Command, HTTP or TCP must be given.
+type ProbeConfig struct {
+	// Command is a command to run. A non-zero exit status fails the probe.
+	Command *string `mapstructure:"command"`
+
+	// Enabled controls if the probe is enabled. It is enabled by default if a
+	// command, HTTP URL or TCP address is given.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// FailureThreshold is the number of consecutive failed probes after which
+	// the child process is killed and restarted.
+	FailureThreshold *int `mapstructure:"failure_threshold"`
+
+	// HTTP is a URL to send a GET request to. A response status outside of the
+	// 2xx and 3xx ranges fails the probe.
+	HTTP *string `mapstructure:"http"`
+
+	// InitialDelay is the amount of time to wait after the child process starts
+	// before probing it.
+	InitialDelay *time.Duration `mapstructure:"initial_delay"`
+
+	// Interval is the amount of time between probes.
+	Interval *time.Duration `mapstructure:"interval"`
+
+	// TCP is an address to open a connection to. A failed connection fails the
+	// probe.
+	TCP *string `mapstructure:"tcp"`
+
+	// Timeout is the maximum amount of time to wait for a probe to succeed.
+	Timeout *time.Duration `mapstructure:"timeout"`
+}
+
+// DefaultProbeConfig returns a configuration that is populated with the
+// default values.
+func DefaultProbeConfig() *ProbeConfig {
+	return &ProbeConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *ProbeConfig) Copy() *ProbeConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o ProbeConfig
+
+	o.Command = c.Command
+
+	o.Enabled = c.Enabled
+
+	o.FailureThreshold = c.FailureThreshold
+
+	o.HTTP = c.HTTP
+
+	o.InitialDelay = c.InitialDelay
+
+	o.Interval = c.Interval
+
+	o.TCP = c.TCP
+
+	o.Timeout = c.Timeout
+
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *ProbeConfig) Merge(o *ProbeConfig) *ProbeConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Command != nil {
+		r.Command = o.Command
+	}
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.FailureThreshold != nil {
+		r.FailureThreshold = o.FailureThreshold
+	}
+
+	if o.HTTP != nil {
+		r.HTTP = o.HTTP
+	}
+
+	if o.InitialDelay != nil {
+		r.InitialDelay = o.InitialDelay
+	}
+
+	if o.Interval != nil {
+		r.Interval = o.Interval
+	}
+
+	if o.TCP != nil {
+		r.TCP = o.TCP
+	}
+
+	if o.Timeout != nil {
+		r.Timeout = o.Timeout
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *ProbeConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(StringPresent(c.Command) ||
+			StringPresent(c.HTTP) || StringPresent(c.TCP))
+	}
+
+	if c.Command == nil {
+		c.Command = String("")
+	}
+
+	if c.FailureThreshold == nil {
+		c.FailureThreshold = Int(DefaultProbeFailureThreshold)
+	}
+
+	if c.HTTP == nil {
+		c.HTTP = String("")
+	}
+
+	if c.InitialDelay == nil {
+		c.InitialDelay = TimeDuration(0)
+	}
+
+	if c.Interval == nil {
+		c.Interval = TimeDuration(DefaultProbeInterval)
+	}
+
+	if c.TCP == nil {
+		c.TCP = String("")
+	}
+
+	if c.Timeout == nil {
+		c.Timeout = TimeDuration(DefaultProbeTimeout)
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *ProbeConfig) GoString() string {
+	if c == nil {
+		return "(*ProbeConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&ProbeConfig{"+
+		"Command:%s, "+
+		"Enabled:%s, "+
+		"FailureThreshold:%s, "+
+		"HTTP:%s, "+
+		"InitialDelay:%s, "+
+		"Interval:%s, "+
+		"TCP:%s, "+
+		"Timeout:%s"+
+		"}",
+		StringGoString(c.Command),
+		BoolGoString(c.Enabled),
+		IntGoString(c.FailureThreshold),
+		StringGoString(c.HTTP),
+		TimeDurationGoString(c.InitialDelay),
+		TimeDurationGoString(c.Interval),
+		StringGoString(c.TCP),
+		TimeDurationGoString(c.Timeout),
+	)
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProbeConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *ProbeConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&ProbeConfig{},
+		},
+		{
+			"copy",
+			&ProbeConfig{
+				Command:          String("command"),
+				Enabled:          Bool(true),
+				FailureThreshold: Int(2),
+				HTTP:             String("http://127.0.0.1/health"),
+				InitialDelay:     TimeDuration(5 * time.Second),
+				Interval:         TimeDuration(5 * time.Second),
+				TCP:              String("127.0.0.1:80"),
+				Timeout:          TimeDuration(1 * time.Second),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestProbeConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *ProbeConfig
+		b    *ProbeConfig
+		r    *ProbeConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&ProbeConfig{},
+			&ProbeConfig{},
+		},
+		{
+			"nil_b",
+			&ProbeConfig{},
+			nil,
+			&ProbeConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&ProbeConfig{},
+			&ProbeConfig{},
+			&ProbeConfig{},
+		},
+		{
+			"command_overrides",
+			&ProbeConfig{Command: String("a")},
+			&ProbeConfig{Command: String("b")},
+			&ProbeConfig{Command: String("b")},
+		},
+		{
+			"enabled_overrides",
+			&ProbeConfig{Enabled: Bool(true)},
+			&ProbeConfig{Enabled: Bool(false)},
+			&ProbeConfig{Enabled: Bool(false)},
+		},
+		{
+			"failure_threshold_overrides",
+			&ProbeConfig{FailureThreshold: Int(1)},
+			&ProbeConfig{FailureThreshold: Int(2)},
+			&ProbeConfig{FailureThreshold: Int(2)},
+		},
+		{
+			"http_overrides",
+			&ProbeConfig{HTTP: String("http://a")},
+			&ProbeConfig{HTTP: String("http://b")},
+			&ProbeConfig{HTTP: String("http://b")},
+		},
+		{
+			"initial_delay_overrides",
+			&ProbeConfig{InitialDelay: TimeDuration(1 * time.Second)},
+			&ProbeConfig{InitialDelay: TimeDuration(2 * time.Second)},
+			&ProbeConfig{InitialDelay: TimeDuration(2 * time.Second)},
+		},
+		{
+			"interval_overrides",
+			&ProbeConfig{Interval: TimeDuration(1 * time.Second)},
+			&ProbeConfig{Interval: TimeDuration(2 * time.Second)},
+			&ProbeConfig{Interval: TimeDuration(2 * time.Second)},
+		},
+		{
+			"tcp_overrides",
+			&ProbeConfig{TCP: String("a:80")},
+			&ProbeConfig{TCP: String("b:80")},
+			&ProbeConfig{TCP: String("b:80")},
+		},
+		{
+			"tcp_empty_one",
+			&ProbeConfig{TCP: String("a:80")},
+			&ProbeConfig{},
+			&ProbeConfig{TCP: String("a:80")},
+		},
+		{
+			"timeout_overrides",
+			&ProbeConfig{Timeout: TimeDuration(1 * time.Second)},
+			&ProbeConfig{Timeout: TimeDuration(2 * time.Second)},
+			&ProbeConfig{Timeout: TimeDuration(2 * time.Second)},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestProbeConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *ProbeConfig
+		r    *ProbeConfig
+	}{
+		{
+			"empty",
+			&ProbeConfig{},
+			&ProbeConfig{
+				Command:          String(""),
+				Enabled:          Bool(false),
+				FailureThreshold: Int(DefaultProbeFailureThreshold),
+				HTTP:             String(""),
+				InitialDelay:     TimeDuration(0),
+				Interval:         TimeDuration(DefaultProbeInterval),
+				TCP:              String(""),
+				Timeout:          TimeDuration(DefaultProbeTimeout),
+			},
+		},
+		{
+			"with_tcp",
+			&ProbeConfig{
+				TCP: String("127.0.0.1:80"),
+			},
+			&ProbeConfig{
+				Command:          String(""),
+				Enabled:          Bool(true),
+				FailureThreshold: Int(DefaultProbeFailureThreshold),
+				HTTP:             String(""),
+				InitialDelay:     TimeDuration(0),
+				Interval:         TimeDuration(DefaultProbeInterval),
+				TCP:              String("127.0.0.1:80"),
+				Timeout:          TimeDuration(DefaultProbeTimeout),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
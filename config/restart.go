@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// RestartPolicyNever never restarts the child process. When it exits,
+	// Consul Template exits too.
+	RestartPolicyNever = "never"
+
+	// RestartPolicyOnFailure restarts the child process when it exits with a
+	// non-zero exit code.
+	RestartPolicyOnFailure = "on-failure"
+
+	// RestartPolicyAlways restarts the child process whenever it exits.
+	RestartPolicyAlways = "always"
+
+	// DefaultRestartMaxAttempts is the default number of consecutive restarts
+	// to attempt before giving up.
+	DefaultRestartMaxAttempts = 5
+
+	// DefaultRestartBackoff is the default base for the exponential backoff
+	// between restarts.
+	DefaultRestartBackoff = 1 * time.Second
+
+	// DefaultRestartMaxBackoff is the default maximum time to wait between
+	// restarts.
+	DefaultRestartMaxBackoff = 1 * time.Minute
+)
+
+// RestartFunc is the signature of a function which decides if the child
+// process should be restarted after it exited with the given code, and how long
+// to wait first. The attempt is the number of consecutive restarts so far.
+type RestartFunc func(code, attempt int) (bool, time.Duration)
+
+// RestartConfig is the configuration for restarting the child process in exec
+// mode when it exits.
+type RestartConfig struct {
+	// Backoff is the base of the exponential backoff. This number will be
+	// multipled by the next power of 2 on each consecutive restart.
+	Backoff *time.Duration `mapstructure:"backoff"`
+
+	// MaxAttempts is the number of consecutive restarts to attempt before
+	// giving up and exiting. 0 means unlimited.
+	MaxAttempts *int `mapstructure:"max_attempts"`
+
+	// MaxBackoff is an upper limit to the time to wait between restarts. A child
+	// process which stays up for this long is considered healthy, and its next
+	// restart starts the backoff from the beginning.
+	MaxBackoff *time.Duration `mapstructure:"max_backoff"`
+
+	// Policy is when to restart the child process: "never", "on-failure" or
+	// "always".
+	Policy *string `mapstructure:"policy"`
+}
+
+// DefaultRestartConfig returns a configuration that is populated with the
+// default values.
+func DefaultRestartConfig() *RestartConfig {
+	return &RestartConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *RestartConfig) Copy() *RestartConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o RestartConfig
+
+	o.Backoff = c.Backoff
+
+	o.MaxAttempts = c.MaxAttempts
+
+	o.MaxBackoff = c.MaxBackoff
+
+	o.Policy = c.Policy
+
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *RestartConfig) Merge(o *RestartConfig) *RestartConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Backoff != nil {
+		r.Backoff = o.Backoff
+	}
+
+	if o.MaxAttempts != nil {
+		r.MaxAttempts = o.MaxAttempts
+	}
+
+	if o.MaxBackoff != nil {
+		r.MaxBackoff = o.MaxBackoff
+	}
+
+	if o.Policy != nil {
+		r.Policy = o.Policy
+	}
+
+	return r
+}
+
+// RestartFunc returns the restart function associated with this configuration.
+func (c *RestartConfig) RestartFunc() RestartFunc {
+	return func(code, attempt int) (bool, time.Duration) {
+		switch StringVal(c.Policy) {
+		case RestartPolicyAlways:
+		case RestartPolicyOnFailure:
+			if code == 0 {
+				return false, 0
+			}
+		default:
+			return false, 0
+		}
+
+		if IntVal(c.MaxAttempts) > 0 && attempt > IntVal(c.MaxAttempts)-1 {
+			return false, 0
+		}
+
+		base := math.Pow(2, float64(attempt))
+		sleep := time.Duration(base) * TimeDurationVal(c.Backoff)
+
+		maxSleep := TimeDurationVal(c.MaxBackoff)
+		if maxSleep > 0 && maxSleep < sleep {
+			return true, maxSleep
+		}
+
+		return true, sleep
+	}
+}
+
+// Finalize ensures there no nil pointers.
+func (c *RestartConfig) Finalize() {
+	if c.Backoff == nil {
+		c.Backoff = TimeDuration(DefaultRestartBackoff)
+	}
+
+	if c.MaxAttempts == nil {
+		c.MaxAttempts = Int(DefaultRestartMaxAttempts)
+	}
+
+	if c.MaxBackoff == nil {
+		c.MaxBackoff = TimeDuration(DefaultRestartMaxBackoff)
+	}
+
+	if c.Policy == nil {
+		c.Policy = String(RestartPolicyNever)
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *RestartConfig) GoString() string {
+	if c == nil {
+		return "(*RestartConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&RestartConfig{"+
+		"Backoff:%s, "+
+		"MaxAttempts:%s, "+
+		"MaxBackoff:%s, "+
+		"Policy:%s"+
+		"}",
+		TimeDurationGoString(c.Backoff),
+		IntGoString(c.MaxAttempts),
+		TimeDurationGoString(c.MaxBackoff),
+		StringGoString(c.Policy),
+	)
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRestartFunc(t *testing.T) {
+	cases := []struct {
+		name    string
+		c       *RestartConfig
+		code    int
+		attempt int
+		restart bool
+		sleep   time.Duration
+	}{
+		{
+			"default",
+			&RestartConfig{},
+			1,
+			0,
+			false,
+			0,
+		},
+		{
+			"on_failure, failed",
+			&RestartConfig{Policy: String(RestartPolicyOnFailure)},
+			1,
+			0,
+			true,
+			1 * time.Second,
+		},
+		{
+			"on_failure, succeeded",
+			&RestartConfig{Policy: String(RestartPolicyOnFailure)},
+			0,
+			0,
+			false,
+			0,
+		},
+		{
+			"always, succeeded",
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			0,
+			0,
+			true,
+			1 * time.Second,
+		},
+		{
+			"always, attempt 2",
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			0,
+			2,
+			true,
+			4 * time.Second,
+		},
+		{
+			"always, attempt 4",
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			0,
+			4,
+			true,
+			16 * time.Second,
+		},
+		{
+			"always, attempt 5",
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			0,
+			5,
+			false,
+			0,
+		},
+		{
+			"max backoff, unlimited attempt 10",
+			&RestartConfig{
+				MaxAttempts: Int(0),
+				MaxBackoff:  TimeDuration(5 * time.Second),
+				Policy:      String(RestartPolicyAlways),
+			},
+			0,
+			10,
+			true,
+			5 * time.Second,
+		},
+		{
+			"unknown policy",
+			&RestartConfig{Policy: String("sometimes")},
+			1,
+			0,
+			false,
+			0,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.c.Finalize()
+			restart, sleep := tc.c.RestartFunc()(tc.code, tc.attempt)
+			if restart != tc.restart {
+				t.Errorf("\nexp restart: %#v\nact: %#v", tc.restart, restart)
+			}
+			if sleep != tc.sleep {
+				t.Errorf("\nexp sleep time: %#v\nact: %#v", tc.sleep, sleep)
+			}
+		})
+	}
+}
+
+func TestRestartConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *RestartConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&RestartConfig{},
+		},
+		{
+			"copy",
+			&RestartConfig{
+				Backoff:     TimeDuration(2 * time.Second),
+				MaxAttempts: Int(3),
+				MaxBackoff:  TimeDuration(30 * time.Second),
+				Policy:      String(RestartPolicyAlways),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestRestartConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *RestartConfig
+		b    *RestartConfig
+		r    *RestartConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&RestartConfig{},
+			&RestartConfig{},
+		},
+		{
+			"nil_b",
+			&RestartConfig{},
+			nil,
+			&RestartConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&RestartConfig{},
+			&RestartConfig{},
+			&RestartConfig{},
+		},
+		{
+			"backoff_overrides",
+			&RestartConfig{Backoff: TimeDuration(10 * time.Second)},
+			&RestartConfig{Backoff: TimeDuration(20 * time.Second)},
+			&RestartConfig{Backoff: TimeDuration(20 * time.Second)},
+		},
+		{
+			"max_attempts_overrides",
+			&RestartConfig{MaxAttempts: Int(10)},
+			&RestartConfig{MaxAttempts: Int(20)},
+			&RestartConfig{MaxAttempts: Int(20)},
+		},
+		{
+			"max_backoff_overrides",
+			&RestartConfig{MaxBackoff: TimeDuration(10 * time.Second)},
+			&RestartConfig{MaxBackoff: TimeDuration(20 * time.Second)},
+			&RestartConfig{MaxBackoff: TimeDuration(20 * time.Second)},
+		},
+		{
+			"policy_overrides",
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			&RestartConfig{Policy: String(RestartPolicyOnFailure)},
+			&RestartConfig{Policy: String(RestartPolicyOnFailure)},
+		},
+		{
+			"policy_empty_one",
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			&RestartConfig{},
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+		},
+		{
+			"policy_empty_two",
+			&RestartConfig{},
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+			&RestartConfig{Policy: String(RestartPolicyAlways)},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestRestartConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *RestartConfig
+		r    *RestartConfig
+	}{
+		{
+			"empty",
+			&RestartConfig{},
+			&RestartConfig{
+				Backoff:     TimeDuration(DefaultRestartBackoff),
+				MaxAttempts: Int(DefaultRestartMaxAttempts),
+				MaxBackoff:  TimeDuration(DefaultRestartMaxBackoff),
+				Policy:      String(RestartPolicyNever),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
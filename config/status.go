@@ -0,0 +1,91 @@
+package config
+
+import "fmt"
+
+const (
+	// DefaultStatusAddress is the default address for the status listener.
+	DefaultStatusAddress = "127.0.0.1:8501"
+)
+
+// StatusConfig is the configuration for the HTTP status listener, which
+// exposes the internal state of the runner as JSON.
+type StatusConfig struct {
+	// Enabled controls whether the status listener is started.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// Address is the address (host:port) where the status listener binds.
+	Address *string `mapstructure:"address"`
+}
+
+// DefaultStatusConfig returns a configuration that is populated with the
+// default values.
+func DefaultStatusConfig() *StatusConfig {
+	return &StatusConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *StatusConfig) Copy() *StatusConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o StatusConfig
+	o.Enabled = c.Enabled
+	o.Address = c.Address
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *StatusConfig) Merge(o *StatusConfig) *StatusConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.Address != nil {
+		r.Address = o.Address
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *StatusConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(StringPresent(c.Address))
+	}
+
+	if c.Address == nil {
+		c.Address = String(DefaultStatusAddress)
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *StatusConfig) GoString() string {
+	if c == nil {
+		return "(*StatusConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&StatusConfig{"+
+		"Enabled:%s, "+
+		"Address:%s"+
+		"}",
+		BoolGoString(c.Enabled),
+		StringGoString(c.Address),
+	)
+}
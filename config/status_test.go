@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestStatusConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *StatusConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&StatusConfig{},
+		},
+		{
+			"same_enabled",
+			&StatusConfig{
+				Enabled: Bool(true),
+				Address: String("127.0.0.1:8502"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestStatusConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *StatusConfig
+		b    *StatusConfig
+		r    *StatusConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&StatusConfig{},
+			&StatusConfig{},
+		},
+		{
+			"nil_b",
+			&StatusConfig{},
+			nil,
+			&StatusConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&StatusConfig{},
+			&StatusConfig{},
+			&StatusConfig{},
+		},
+		{
+			"enabled_overrides",
+			&StatusConfig{Enabled: Bool(true)},
+			&StatusConfig{Enabled: Bool(false)},
+			&StatusConfig{Enabled: Bool(false)},
+		},
+		{
+			"enabled_empty_one",
+			&StatusConfig{Enabled: Bool(true)},
+			&StatusConfig{},
+			&StatusConfig{Enabled: Bool(true)},
+		},
+		{
+			"enabled_empty_two",
+			&StatusConfig{},
+			&StatusConfig{Enabled: Bool(true)},
+			&StatusConfig{Enabled: Bool(true)},
+		},
+		{
+			"enabled_same",
+			&StatusConfig{Enabled: Bool(true)},
+			&StatusConfig{Enabled: Bool(true)},
+			&StatusConfig{Enabled: Bool(true)},
+		},
+		{
+			"address_overrides",
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+			&StatusConfig{Address: String("")},
+			&StatusConfig{Address: String("")},
+		},
+		{
+			"address_empty_one",
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+			&StatusConfig{},
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+		},
+		{
+			"address_empty_two",
+			&StatusConfig{},
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+		},
+		{
+			"address_same",
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+			&StatusConfig{Address: String("127.0.0.1:8502")},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestStatusConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *StatusConfig
+		r    *StatusConfig
+	}{
+		{
+			"empty",
+			&StatusConfig{},
+			&StatusConfig{
+				Enabled: Bool(false),
+				Address: String(DefaultStatusAddress),
+			},
+		},
+		{
+			"with_address",
+			&StatusConfig{
+				Address: String("127.0.0.1:8502"),
+			},
+			&StatusConfig{
+				Enabled: Bool(true),
+				Address: String("127.0.0.1:8502"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
@@ -5,12 +5,28 @@ import "fmt"
 const (
 	// DefaultSyslogFacility is the default facility to log to.
 	DefaultSyslogFacility = "LOCAL0"
+
+	// DefaultSyslogProtocol is the default protocol to send to a remote syslog
+	// server with, unless SSL is enabled, which uses TCP.
+	DefaultSyslogProtocol = "udp"
 )
 
 // SyslogConfig is the configuration for syslog.
 type SyslogConfig struct {
+	// Address is the address (host:port) of a remote syslog server to send
+	// messages to in RFC 5424 format, instead of the local syslog socket.
+	Address *string `mapstructure:"address"`
+
 	Enabled  *bool   `mapstructure:"enabled"`
 	Facility *string `mapstructure:"facility"`
+
+	// Protocol is the protocol to send to the remote syslog server with, either
+	// "udp" or "tcp".
+	Protocol *string `mapstructure:"protocol"`
+
+	// SSL is the configuration for sending to the remote syslog server over TLS,
+	// which requires the "tcp" protocol.
+	SSL *SSLConfig `mapstructure:"ssl"`
 }
 
 // DefaultSyslogConfig returns a configuration that is populated with the
@@ -26,8 +42,15 @@ func (c *SyslogConfig) Copy() *SyslogConfig {
 	}
 
 	var o SyslogConfig
+	o.Address = c.Address
 	o.Enabled = c.Enabled
 	o.Facility = c.Facility
+	o.Protocol = c.Protocol
+
+	if c.SSL != nil {
+		o.SSL = c.SSL.Copy()
+	}
+
 	return &o
 }
 
@@ -49,6 +72,10 @@ func (c *SyslogConfig) Merge(o *SyslogConfig) *SyslogConfig {
 
 	r := c.Copy()
 
+	if o.Address != nil {
+		r.Address = o.Address
+	}
+
 	if o.Enabled != nil {
 		r.Enabled = o.Enabled
 	}
@@ -57,18 +84,43 @@ func (c *SyslogConfig) Merge(o *SyslogConfig) *SyslogConfig {
 		r.Facility = o.Facility
 	}
 
+	if o.Protocol != nil {
+		r.Protocol = o.Protocol
+	}
+
+	if o.SSL != nil {
+		r.SSL = r.SSL.Merge(o.SSL)
+	}
+
 	return r
 }
 
 // Finalize ensures there no nil pointers.
 func (c *SyslogConfig) Finalize() {
+	if c.Address == nil {
+		c.Address = String("")
+	}
+
 	if c.Enabled == nil {
-		c.Enabled = Bool(StringPresent(c.Facility))
+		c.Enabled = Bool(StringPresent(c.Facility) || StringPresent(c.Address))
 	}
 
 	if c.Facility == nil {
 		c.Facility = String(DefaultSyslogFacility)
 	}
+
+	if c.SSL == nil {
+		c.SSL = DefaultSSLConfig()
+	}
+	c.SSL.Finalize()
+
+	if c.Protocol == nil {
+		if BoolVal(c.SSL.Enabled) {
+			c.Protocol = String("tcp")
+		} else {
+			c.Protocol = String(DefaultSyslogProtocol)
+		}
+	}
 }
 
 // GoString defines the printable version of this struct.
@@ -78,10 +130,16 @@ func (c *SyslogConfig) GoString() string {
 	}
 
 	return fmt.Sprintf("&SyslogConfig{"+
+		"Address:%s, "+
 		"Enabled:%s, "+
-		"Facility:%s"+
+		"Facility:%s, "+
+		"Protocol:%s, "+
+		"SSL:%#v"+
 		"}",
+		StringGoString(c.Address),
 		BoolGoString(c.Enabled),
 		StringGoString(c.Facility),
+		StringGoString(c.Protocol),
+		c.SSL,
 	)
 }
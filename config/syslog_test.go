@@ -22,8 +22,11 @@ func TestSyslogConfig_Copy(t *testing.T) {
 		{
 			"same_enabled",
 			&SyslogConfig{
+				Address:  String("logs.example.com:514"),
 				Enabled:  Bool(true),
 				Facility: String("facility"),
+				Protocol: String("tcp"),
+				SSL:      &SSLConfig{Enabled: Bool(true)},
 			},
 		},
 	}
@@ -69,6 +72,24 @@ func TestSyslogConfig_Merge(t *testing.T) {
 			&SyslogConfig{},
 			&SyslogConfig{},
 		},
+		{
+			"address_overrides",
+			&SyslogConfig{Address: String("a:514")},
+			&SyslogConfig{Address: String("b:514")},
+			&SyslogConfig{Address: String("b:514")},
+		},
+		{
+			"address_empty_one",
+			&SyslogConfig{Address: String("a:514")},
+			&SyslogConfig{},
+			&SyslogConfig{Address: String("a:514")},
+		},
+		{
+			"address_empty_two",
+			&SyslogConfig{},
+			&SyslogConfig{Address: String("a:514")},
+			&SyslogConfig{Address: String("a:514")},
+		},
 		{
 			"enabled_overrides",
 			&SyslogConfig{Enabled: Bool(true)},
@@ -117,6 +138,24 @@ func TestSyslogConfig_Merge(t *testing.T) {
 			&SyslogConfig{Facility: String("facility")},
 			&SyslogConfig{Facility: String("facility")},
 		},
+		{
+			"protocol_overrides",
+			&SyslogConfig{Protocol: String("udp")},
+			&SyslogConfig{Protocol: String("tcp")},
+			&SyslogConfig{Protocol: String("tcp")},
+		},
+		{
+			"protocol_empty_one",
+			&SyslogConfig{Protocol: String("tcp")},
+			&SyslogConfig{},
+			&SyslogConfig{Protocol: String("tcp")},
+		},
+		{
+			"ssl_merges",
+			&SyslogConfig{SSL: &SSLConfig{Enabled: Bool(true)}},
+			&SyslogConfig{SSL: &SSLConfig{Verify: Bool(false)}},
+			&SyslogConfig{SSL: &SSLConfig{Enabled: Bool(true), Verify: Bool(false)}},
+		},
 	}
 
 	for i, tc := range cases {
@@ -139,8 +178,19 @@ func TestSyslogConfig_Finalize(t *testing.T) {
 			"empty",
 			&SyslogConfig{},
 			&SyslogConfig{
+				Address:  String(""),
 				Enabled:  Bool(false),
 				Facility: String(DefaultSyslogFacility),
+				Protocol: String(DefaultSyslogProtocol),
+				SSL: &SSLConfig{
+					CaCert:     String(""),
+					CaPath:     String(""),
+					Cert:       String(""),
+					Enabled:    Bool(false),
+					Key:        String(""),
+					ServerName: String(""),
+					Verify:     Bool(true),
+				},
 			},
 		},
 		{
@@ -149,8 +199,62 @@ func TestSyslogConfig_Finalize(t *testing.T) {
 				Facility: String("facility"),
 			},
 			&SyslogConfig{
+				Address:  String(""),
 				Enabled:  Bool(true),
 				Facility: String("facility"),
+				Protocol: String(DefaultSyslogProtocol),
+				SSL: &SSLConfig{
+					CaCert:     String(""),
+					CaPath:     String(""),
+					Cert:       String(""),
+					Enabled:    Bool(false),
+					Key:        String(""),
+					ServerName: String(""),
+					Verify:     Bool(true),
+				},
+			},
+		},
+		{
+			"with_address",
+			&SyslogConfig{
+				Address: String("logs.example.com:514"),
+			},
+			&SyslogConfig{
+				Address:  String("logs.example.com:514"),
+				Enabled:  Bool(true),
+				Facility: String(DefaultSyslogFacility),
+				Protocol: String(DefaultSyslogProtocol),
+				SSL: &SSLConfig{
+					CaCert:     String(""),
+					CaPath:     String(""),
+					Cert:       String(""),
+					Enabled:    Bool(false),
+					Key:        String(""),
+					ServerName: String(""),
+					Verify:     Bool(true),
+				},
+			},
+		},
+		{
+			"with_ssl",
+			&SyslogConfig{
+				Address: String("logs.example.com:6514"),
+				SSL:     &SSLConfig{Enabled: Bool(true)},
+			},
+			&SyslogConfig{
+				Address:  String("logs.example.com:6514"),
+				Enabled:  Bool(true),
+				Facility: String(DefaultSyslogFacility),
+				Protocol: String("tcp"),
+				SSL: &SSLConfig{
+					CaCert:     String(""),
+					CaPath:     String(""),
+					Cert:       String(""),
+					Enabled:    Bool(true),
+					Key:        String(""),
+					ServerName: String(""),
+					Verify:     Bool(true),
+				},
 			},
 		},
 	}
@@ -0,0 +1,169 @@
+package config
+
+import "fmt"
+
+const (
+	// DefaultMetricsPrefix is the default prefix for all emitted metrics.
+	DefaultMetricsPrefix = "consul_template"
+)
+
+// TelemetryConfig is the configuration for emitting metrics.
+type TelemetryConfig struct {
+	// Enabled controls whether metrics are emitted.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// DisableHostname controls whether gauges are prefixed with the hostname.
+	DisableHostname *bool `mapstructure:"disable_hostname"`
+
+	// DogStatsdAddress is the address (host:port) of a DogStatsD agent.
+	DogStatsdAddress *string `mapstructure:"dogstatsd_address"`
+
+	// DogStatsdTags is the list of tags (in the "key:value" format) added to
+	// every metric sent to DogStatsD.
+	DogStatsdTags []string `mapstructure:"dogstatsd_tags"`
+
+	// MetricsPrefix is the prefix added to the name of every metric.
+	MetricsPrefix *string `mapstructure:"metrics_prefix"`
+
+	// PrometheusAddress is the address (host:port) where an in-process
+	// Prometheus endpoint is served.
+	PrometheusAddress *string `mapstructure:"prometheus_address"`
+
+	// StatsdAddress is the address (host:port) of a statsd server.
+	StatsdAddress *string `mapstructure:"statsd_address"`
+}
+
+// DefaultTelemetryConfig returns a configuration that is populated with the
+// default values.
+func DefaultTelemetryConfig() *TelemetryConfig {
+	return &TelemetryConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *TelemetryConfig) Copy() *TelemetryConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o TelemetryConfig
+	o.Enabled = c.Enabled
+	o.DisableHostname = c.DisableHostname
+	o.DogStatsdAddress = c.DogStatsdAddress
+
+	if c.DogStatsdTags != nil {
+		o.DogStatsdTags = append([]string{}, c.DogStatsdTags...)
+	}
+
+	o.MetricsPrefix = c.MetricsPrefix
+	o.PrometheusAddress = c.PrometheusAddress
+	o.StatsdAddress = c.StatsdAddress
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *TelemetryConfig) Merge(o *TelemetryConfig) *TelemetryConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.DisableHostname != nil {
+		r.DisableHostname = o.DisableHostname
+	}
+
+	if o.DogStatsdAddress != nil {
+		r.DogStatsdAddress = o.DogStatsdAddress
+	}
+
+	if o.DogStatsdTags != nil {
+		r.DogStatsdTags = append(r.DogStatsdTags, o.DogStatsdTags...)
+	}
+
+	if o.MetricsPrefix != nil {
+		r.MetricsPrefix = o.MetricsPrefix
+	}
+
+	if o.PrometheusAddress != nil {
+		r.PrometheusAddress = o.PrometheusAddress
+	}
+
+	if o.StatsdAddress != nil {
+		r.StatsdAddress = o.StatsdAddress
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *TelemetryConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(false ||
+			StringPresent(c.DogStatsdAddress) ||
+			StringPresent(c.PrometheusAddress) ||
+			StringPresent(c.StatsdAddress))
+	}
+
+	if c.DisableHostname == nil {
+		c.DisableHostname = Bool(false)
+	}
+
+	if c.DogStatsdAddress == nil {
+		c.DogStatsdAddress = String("")
+	}
+
+	if c.DogStatsdTags == nil {
+		c.DogStatsdTags = []string{}
+	}
+
+	if c.MetricsPrefix == nil {
+		c.MetricsPrefix = String(DefaultMetricsPrefix)
+	}
+
+	if c.PrometheusAddress == nil {
+		c.PrometheusAddress = String("")
+	}
+
+	if c.StatsdAddress == nil {
+		c.StatsdAddress = String("")
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *TelemetryConfig) GoString() string {
+	if c == nil {
+		return "(*TelemetryConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&TelemetryConfig{"+
+		"Enabled:%s, "+
+		"DisableHostname:%s, "+
+		"DogStatsdAddress:%s, "+
+		"DogStatsdTags:%v, "+
+		"MetricsPrefix:%s, "+
+		"PrometheusAddress:%s, "+
+		"StatsdAddress:%s"+
+		"}",
+		BoolGoString(c.Enabled),
+		BoolGoString(c.DisableHostname),
+		StringGoString(c.DogStatsdAddress),
+		c.DogStatsdTags,
+		StringGoString(c.MetricsPrefix),
+		StringGoString(c.PrometheusAddress),
+		StringGoString(c.StatsdAddress),
+	)
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTelemetryConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *TelemetryConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&TelemetryConfig{},
+		},
+		{
+			"same_enabled",
+			&TelemetryConfig{
+				Enabled:           Bool(true),
+				DisableHostname:   Bool(true),
+				DogStatsdAddress:  String("127.0.0.1:8125"),
+				DogStatsdTags:     []string{"env:prod"},
+				MetricsPrefix:     String("prefix"),
+				PrometheusAddress: String("127.0.0.1:9102"),
+				StatsdAddress:     String("127.0.0.1:8126"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestTelemetryConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *TelemetryConfig
+		b    *TelemetryConfig
+		r    *TelemetryConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&TelemetryConfig{},
+			&TelemetryConfig{},
+		},
+		{
+			"nil_b",
+			&TelemetryConfig{},
+			nil,
+			&TelemetryConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&TelemetryConfig{},
+			&TelemetryConfig{},
+			&TelemetryConfig{},
+		},
+		{
+			"enabled_overrides",
+			&TelemetryConfig{Enabled: Bool(true)},
+			&TelemetryConfig{Enabled: Bool(false)},
+			&TelemetryConfig{Enabled: Bool(false)},
+		},
+		{
+			"enabled_empty_one",
+			&TelemetryConfig{Enabled: Bool(true)},
+			&TelemetryConfig{},
+			&TelemetryConfig{Enabled: Bool(true)},
+		},
+		{
+			"disable_hostname_overrides",
+			&TelemetryConfig{DisableHostname: Bool(true)},
+			&TelemetryConfig{DisableHostname: Bool(false)},
+			&TelemetryConfig{DisableHostname: Bool(false)},
+		},
+		{
+			"dogstatsd_address_overrides",
+			&TelemetryConfig{DogStatsdAddress: String("a")},
+			&TelemetryConfig{DogStatsdAddress: String("b")},
+			&TelemetryConfig{DogStatsdAddress: String("b")},
+		},
+		{
+			"dogstatsd_tags_merges",
+			&TelemetryConfig{DogStatsdTags: []string{"a:b"}},
+			&TelemetryConfig{DogStatsdTags: []string{"c:d"}},
+			&TelemetryConfig{DogStatsdTags: []string{"a:b", "c:d"}},
+		},
+		{
+			"dogstatsd_tags_empty_one",
+			&TelemetryConfig{DogStatsdTags: []string{"a:b"}},
+			&TelemetryConfig{},
+			&TelemetryConfig{DogStatsdTags: []string{"a:b"}},
+		},
+		{
+			"metrics_prefix_overrides",
+			&TelemetryConfig{MetricsPrefix: String("a")},
+			&TelemetryConfig{MetricsPrefix: String("")},
+			&TelemetryConfig{MetricsPrefix: String("")},
+		},
+		{
+			"prometheus_address_overrides",
+			&TelemetryConfig{PrometheusAddress: String("a")},
+			&TelemetryConfig{PrometheusAddress: String("b")},
+			&TelemetryConfig{PrometheusAddress: String("b")},
+		},
+		{
+			"statsd_address_overrides",
+			&TelemetryConfig{StatsdAddress: String("a")},
+			&TelemetryConfig{StatsdAddress: String("b")},
+			&TelemetryConfig{StatsdAddress: String("b")},
+		},
+		{
+			"statsd_address_empty_two",
+			&TelemetryConfig{},
+			&TelemetryConfig{StatsdAddress: String("b")},
+			&TelemetryConfig{StatsdAddress: String("b")},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestTelemetryConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *TelemetryConfig
+		r    *TelemetryConfig
+	}{
+		{
+			"empty",
+			&TelemetryConfig{},
+			&TelemetryConfig{
+				Enabled:           Bool(false),
+				DisableHostname:   Bool(false),
+				DogStatsdAddress:  String(""),
+				DogStatsdTags:     []string{},
+				MetricsPrefix:     String(DefaultMetricsPrefix),
+				PrometheusAddress: String(""),
+				StatsdAddress:     String(""),
+			},
+		},
+		{
+			"with_prometheus_address",
+			&TelemetryConfig{
+				PrometheusAddress: String("127.0.0.1:9102"),
+			},
+			&TelemetryConfig{
+				Enabled:           Bool(true),
+				DisableHostname:   Bool(false),
+				DogStatsdAddress:  String(""),
+				DogStatsdTags:     []string{},
+				MetricsPrefix:     String(DefaultMetricsPrefix),
+				PrometheusAddress: String("127.0.0.1:9102"),
+				StatsdAddress:     String(""),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
@@ -18,6 +18,15 @@ const (
 	// DefaultTemplateCommandTimeout is the amount of time to wait for a command
 	// to return.
 	DefaultTemplateCommandTimeout = 30 * time.Second
+
+	// OnFailureContinue, OnFailureStop and OnFailureRestoreBackup are the
+	// actions which can be taken when the command of a template fails.
+	// Continue runs the remaining commands, Stop skips the commands which come
+	// after it, and RestoreBackup restores the backup of the destination before
+	// continuing.
+	OnFailureContinue      = "continue"
+	OnFailureStop          = "stop"
+	OnFailureRestoreBackup = "restore_backup"
 )
 
 var (
@@ -40,6 +49,10 @@ type TemplateConfig struct {
 	// value is false.
 	Backup *bool `mapstructure:"backup"`
 
+	// Check is the configuration for validating the rendered template before it
+	// replaces the file on disk.
+	Check *CheckConfig `mapstructure:"check"`
+
 	// Command is the arbitrary command to execute after a template has
 	// successfully rendered. This is DEPRECATED. Use Exec instead.
 	Command *string `mapstructure:"command"`
@@ -64,15 +77,69 @@ type TemplateConfig struct {
 	// successfully.
 	Exec *ExecConfig `mapstructure:"exec"`
 
+	// Group is the name or numeric ID of the group which should own the file on
+	// disk. The default value is empty, which keeps the group of the process, or
+	// the group of the existing file when PreserveOwnership applies.
+	Group *string `mapstructure:"group"`
+
+	// OnFailure is the action to take when the command fails, once any retries
+	// are exhausted. The default value is "continue".
+	OnFailure *string `mapstructure:"on_failure"`
+
+	// ParallelGroup is the name of the group of commands this command runs
+	// concurrently with. Each group runs in the position of its first command,
+	// so commands still run in the order they are declared across groups.
+	ParallelGroup *string `mapstructure:"parallel_group"`
+
 	// Perms are the file system permissions to use when creating the file on
 	// disk. This is useful for when files contain sensitive information, such as
 	// secrets from Vault.
 	Perms *os.FileMode `mapstructure:"perms"`
 
+	// PreserveOwnership determines if the owner and group of an existing file
+	// on disk are kept when the template is rendered. This only applies when
+	// running as root, and User and Group take precedence. The default value is
+	// true.
+	PreserveOwnership *bool `mapstructure:"preserve_ownership"`
+
+	// ReloadChild determines if a change to this template reloads the child
+	// process in exec mode. The default value is true.
+	ReloadChild *bool `mapstructure:"reload_child"`
+
+	// ReloadChildSignal is the signal sent to the child process in exec mode
+	// when this template changes, instead of the reload signal of the exec
+	// configuration.
+	ReloadChildSignal *os.Signal `mapstructure:"reload_child_signal"`
+
+	// RenderTimeout is the maximum amount of time to wait for this template to
+	// render for the first time. The default value is 0, which uses the
+	// top-level render timeout.
+	RenderTimeout *time.Duration `mapstructure:"render_timeout"`
+
+	// RestartChild determines if a change to this template restarts the child
+	// process in exec mode, instead of sending it a signal. The default value is
+	// false.
+	RestartChild *bool `mapstructure:"restart_child"`
+
+	// Retry is the configuration for retrying the command when it fails. By
+	// default a command is not retried.
+	Retry *RetryConfig `mapstructure:"retry"`
+
 	// Source is the path on disk to the template contents to evaluate. Either
 	// this or Contents should be specified, but not both.
 	Source *string `mapstructure:"source"`
 
+	// TemplateGroup is the name of the template group this template belongs to.
+	// The Destination of a member of a group is relative to the directory of
+	// each generation of the group, and its command runs once each time the
+	// group is published.
+	TemplateGroup *string `mapstructure:"template_group"`
+
+	// User is the name or numeric ID of the user which should own the file on
+	// disk. The default value is empty, which keeps the user of the process, or
+	// the owner of the existing file when PreserveOwnership applies.
+	User *string `mapstructure:"user"`
+
 	// Wait configures per-template quiescence timers.
 	Wait *WaitConfig `mapstructure:"wait"`
 
@@ -86,8 +153,9 @@ type TemplateConfig struct {
 // default values.
 func DefaultTemplateConfig() *TemplateConfig {
 	return &TemplateConfig{
-		Exec: DefaultExecConfig(),
-		Wait: DefaultWaitConfig(),
+		Check: DefaultCheckConfig(),
+		Exec:  DefaultExecConfig(),
+		Wait:  DefaultWaitConfig(),
 	}
 }
 
@@ -101,6 +169,10 @@ func (c *TemplateConfig) Copy() *TemplateConfig {
 
 	o.Backup = c.Backup
 
+	if c.Check != nil {
+		o.Check = c.Check.Copy()
+	}
+
 	o.Command = c.Command
 
 	o.CommandTimeout = c.CommandTimeout
@@ -115,10 +187,34 @@ func (c *TemplateConfig) Copy() *TemplateConfig {
 		o.Exec = c.Exec.Copy()
 	}
 
+	o.Group = c.Group
+
+	o.OnFailure = c.OnFailure
+
+	o.ParallelGroup = c.ParallelGroup
+
 	o.Perms = c.Perms
 
+	o.PreserveOwnership = c.PreserveOwnership
+
+	o.ReloadChild = c.ReloadChild
+
+	o.ReloadChildSignal = c.ReloadChildSignal
+
+	o.RenderTimeout = c.RenderTimeout
+
+	o.RestartChild = c.RestartChild
+
+	if c.Retry != nil {
+		o.Retry = c.Retry.Copy()
+	}
+
 	o.Source = c.Source
 
+	o.TemplateGroup = c.TemplateGroup
+
+	o.User = c.User
+
 	if c.Wait != nil {
 		o.Wait = c.Wait.Copy()
 	}
@@ -151,6 +247,10 @@ func (c *TemplateConfig) Merge(o *TemplateConfig) *TemplateConfig {
 		r.Backup = o.Backup
 	}
 
+	if o.Check != nil {
+		r.Check = r.Check.Merge(o.Check)
+	}
+
 	if o.Command != nil {
 		r.Command = o.Command
 	}
@@ -175,14 +275,58 @@ func (c *TemplateConfig) Merge(o *TemplateConfig) *TemplateConfig {
 		r.Exec = r.Exec.Merge(o.Exec)
 	}
 
+	if o.Group != nil {
+		r.Group = o.Group
+	}
+
+	if o.OnFailure != nil {
+		r.OnFailure = o.OnFailure
+	}
+
+	if o.ParallelGroup != nil {
+		r.ParallelGroup = o.ParallelGroup
+	}
+
 	if o.Perms != nil {
 		r.Perms = o.Perms
 	}
 
+	if o.PreserveOwnership != nil {
+		r.PreserveOwnership = o.PreserveOwnership
+	}
+
+	if o.ReloadChild != nil {
+		r.ReloadChild = o.ReloadChild
+	}
+
+	if o.ReloadChildSignal != nil {
+		r.ReloadChildSignal = o.ReloadChildSignal
+	}
+
+	if o.RenderTimeout != nil {
+		r.RenderTimeout = o.RenderTimeout
+	}
+
+	if o.RestartChild != nil {
+		r.RestartChild = o.RestartChild
+	}
+
+	if o.Retry != nil {
+		r.Retry = r.Retry.Merge(o.Retry)
+	}
+
 	if o.Source != nil {
 		r.Source = o.Source
 	}
 
+	if o.TemplateGroup != nil {
+		r.TemplateGroup = o.TemplateGroup
+	}
+
+	if o.User != nil {
+		r.User = o.User
+	}
+
 	if o.Wait != nil {
 		r.Wait = r.Wait.Merge(o.Wait)
 	}
@@ -205,6 +349,11 @@ func (c *TemplateConfig) Finalize() {
 		c.Backup = Bool(false)
 	}
 
+	if c.Check == nil {
+		c.Check = DefaultCheckConfig()
+	}
+	c.Check.Finalize()
+
 	if c.Command == nil {
 		c.Command = String("")
 	}
@@ -238,14 +387,60 @@ func (c *TemplateConfig) Finalize() {
 	}
 	c.Exec.Finalize()
 
+	if c.Group == nil {
+		c.Group = String("")
+	}
+
+	if c.OnFailure == nil {
+		c.OnFailure = String(OnFailureContinue)
+	}
+
+	if c.ParallelGroup == nil {
+		c.ParallelGroup = String("")
+	}
+
 	if c.Perms == nil {
 		c.Perms = FileMode(DefaultTemplateFilePerms)
 	}
 
+	if c.PreserveOwnership == nil {
+		c.PreserveOwnership = Bool(true)
+	}
+
+	if c.ReloadChild == nil {
+		c.ReloadChild = Bool(true)
+	}
+
+	if c.ReloadChildSignal == nil {
+		c.ReloadChildSignal = Signal(nil)
+	}
+
+	if c.RenderTimeout == nil {
+		c.RenderTimeout = TimeDuration(0)
+	}
+
+	if c.RestartChild == nil {
+		c.RestartChild = Bool(false)
+	}
+
+	// Unlike the retries of upstreams, commands are only retried when asked.
+	if c.Retry == nil {
+		c.Retry = &RetryConfig{Enabled: Bool(false)}
+	}
+	c.Retry.Finalize()
+
 	if c.Source == nil {
 		c.Source = String("")
 	}
 
+	if c.TemplateGroup == nil {
+		c.TemplateGroup = String("")
+	}
+
+	if c.User == nil {
+		c.User = String("")
+	}
+
 	if c.Wait == nil {
 		c.Wait = DefaultWaitConfig()
 	}
@@ -268,27 +463,51 @@ func (c *TemplateConfig) GoString() string {
 
 	return fmt.Sprintf("&TemplateConfig{"+
 		"Backup:%s, "+
+		"Check:%#v, "+
 		"Command:%s, "+
 		"CommandTimeout:%s, "+
 		"Contents:%s, "+
 		"Destination:%s, "+
 		"ErrMissingKey:%s, "+
 		"Exec:%#v, "+
+		"Group:%s, "+
+		"OnFailure:%s, "+
+		"ParallelGroup:%s, "+
 		"Perms:%s, "+
+		"PreserveOwnership:%s, "+
+		"ReloadChild:%s, "+
+		"ReloadChildSignal:%s, "+
+		"RenderTimeout:%s, "+
+		"RestartChild:%s, "+
+		"Retry:%#v, "+
 		"Source:%s, "+
+		"TemplateGroup:%s, "+
+		"User:%s, "+
 		"Wait:%#v, "+
 		"LeftDelim:%s, "+
 		"RightDelim:%s"+
 		"}",
 		BoolGoString(c.Backup),
+		c.Check,
 		StringGoString(c.Command),
 		TimeDurationGoString(c.CommandTimeout),
 		StringGoString(c.Contents),
 		StringGoString(c.Destination),
 		BoolGoString(c.ErrMissingKey),
 		c.Exec,
+		StringGoString(c.Group),
+		StringGoString(c.OnFailure),
+		StringGoString(c.ParallelGroup),
 		FileModeGoString(c.Perms),
+		BoolGoString(c.PreserveOwnership),
+		BoolGoString(c.ReloadChild),
+		SignalGoString(c.ReloadChildSignal),
+		TimeDurationGoString(c.RenderTimeout),
+		BoolGoString(c.RestartChild),
+		c.Retry,
 		StringGoString(c.Source),
+		StringGoString(c.TemplateGroup),
+		StringGoString(c.User),
 		c.Wait,
 		StringGoString(c.LeftDelim),
 		StringGoString(c.RightDelim),
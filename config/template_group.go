@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultTemplateGroupKeep is the default number of generations of a
+	// template group to keep on disk, including the published one.
+	DefaultTemplateGroupKeep = 3
+)
+
+// TemplateGroupConfig is a set of templates which are published together. The
+// members of the group are rendered into a new directory, which is published
+// by atomically pointing a symlink at it once every member has rendered.
+type TemplateGroupConfig struct {
+	// Check is the configuration for validating the new directory before it is
+	// published. The path to the directory is available to the check command as
+	// {{.TempPath}}.
+	Check *CheckConfig `mapstructure:"check"`
+
+	// Keep is the number of generations to keep on disk, including the
+	// published one. Older generations are removed after a new one is
+	// published.
+	Keep *int `mapstructure:"keep"`
+
+	// Name is the name of the group, which templates use to join it.
+	Name *string `mapstructure:"name"`
+
+	// Path is the location on disk of the symlink which points at the published
+	// generation. Each generation is a directory next to it, named after the
+	// symlink with an increasing number appended.
+	Path *string `mapstructure:"path"`
+}
+
+// DefaultTemplateGroupConfig returns a configuration that is populated with
+// the default values.
+func DefaultTemplateGroupConfig() *TemplateGroupConfig {
+	return &TemplateGroupConfig{
+		Check: DefaultCheckConfig(),
+	}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *TemplateGroupConfig) Copy() *TemplateGroupConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o TemplateGroupConfig
+
+	if c.Check != nil {
+		o.Check = c.Check.Copy()
+	}
+
+	o.Keep = c.Keep
+
+	o.Name = c.Name
+
+	o.Path = c.Path
+
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *TemplateGroupConfig) Merge(o *TemplateGroupConfig) *TemplateGroupConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Check != nil {
+		r.Check = r.Check.Merge(o.Check)
+	}
+
+	if o.Keep != nil {
+		r.Keep = o.Keep
+	}
+
+	if o.Name != nil {
+		r.Name = o.Name
+	}
+
+	if o.Path != nil {
+		r.Path = o.Path
+	}
+
+	return r
+}
+
+// Finalize ensures the configuration has no nil pointers and sets default
+// values.
+func (c *TemplateGroupConfig) Finalize() {
+	if c.Check == nil {
+		c.Check = DefaultCheckConfig()
+	}
+	c.Check.Finalize()
+
+	if c.Keep == nil {
+		c.Keep = Int(DefaultTemplateGroupKeep)
+	}
+
+	if c.Name == nil {
+		c.Name = String("")
+	}
+
+	if c.Path == nil {
+		c.Path = String("")
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *TemplateGroupConfig) GoString() string {
+	if c == nil {
+		return "(*TemplateGroupConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&TemplateGroupConfig{"+
+		"Check:%#v, "+
+		"Keep:%s, "+
+		"Name:%s, "+
+		"Path:%s"+
+		"}",
+		c.Check,
+		IntGoString(c.Keep),
+		StringGoString(c.Name),
+		StringGoString(c.Path),
+	)
+}
+
+// Display is the human-friendly form of this configuration.
+func (c *TemplateGroupConfig) Display() string {
+	if c == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("group %q => %q",
+		StringVal(c.Name),
+		StringVal(c.Path),
+	)
+}
+
+// TemplateGroupConfigs is a collection of TemplateGroupConfigs.
+type TemplateGroupConfigs []*TemplateGroupConfig
+
+// DefaultTemplateGroupConfigs returns a configuration that is populated with
+// the default values.
+func DefaultTemplateGroupConfigs() *TemplateGroupConfigs {
+	return &TemplateGroupConfigs{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *TemplateGroupConfigs) Copy() *TemplateGroupConfigs {
+	o := make(TemplateGroupConfigs, len(*c))
+	for i, t := range *c {
+		o[i] = t.Copy()
+	}
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *TemplateGroupConfigs) Merge(o *TemplateGroupConfigs) *TemplateGroupConfigs {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	*r = append(*r, *o...)
+
+	return r
+}
+
+// Finalize ensures the configuration has no nil pointers and sets default
+// values.
+func (c *TemplateGroupConfigs) Finalize() {
+	if c == nil {
+		*c = *DefaultTemplateGroupConfigs()
+	}
+
+	for _, t := range *c {
+		t.Finalize()
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *TemplateGroupConfigs) GoString() string {
+	if c == nil {
+		return "(*TemplateGroupConfigs)(nil)"
+	}
+
+	s := make([]string, len(*c))
+	for i, t := range *c {
+		s[i] = t.GoString()
+	}
+
+	return "{" + strings.Join(s, ", ") + "}"
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTemplateGroupConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *TemplateGroupConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&TemplateGroupConfig{},
+		},
+		{
+			"same_enabled",
+			&TemplateGroupConfig{
+				Check: &CheckConfig{Format: String("json")},
+				Keep:  Int(5),
+				Name:  String("name"),
+				Path:  String("path"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestTemplateGroupConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *TemplateGroupConfig
+		b    *TemplateGroupConfig
+		r    *TemplateGroupConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{},
+		},
+		{
+			"nil_b",
+			&TemplateGroupConfig{},
+			nil,
+			&TemplateGroupConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{},
+		},
+		{
+			"check_overrides",
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("yaml")}},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("yaml")}},
+		},
+		{
+			"check_empty_one",
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+		},
+		{
+			"check_empty_two",
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+		},
+		{
+			"check_same",
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateGroupConfig{Check: &CheckConfig{Format: String("json")}},
+		},
+		{
+			"keep_overrides",
+			&TemplateGroupConfig{Keep: Int(5)},
+			&TemplateGroupConfig{Keep: Int(0)},
+			&TemplateGroupConfig{Keep: Int(0)},
+		},
+		{
+			"keep_empty_one",
+			&TemplateGroupConfig{Keep: Int(5)},
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Keep: Int(5)},
+		},
+		{
+			"keep_empty_two",
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Keep: Int(5)},
+			&TemplateGroupConfig{Keep: Int(5)},
+		},
+		{
+			"keep_same",
+			&TemplateGroupConfig{Keep: Int(5)},
+			&TemplateGroupConfig{Keep: Int(5)},
+			&TemplateGroupConfig{Keep: Int(5)},
+		},
+		{
+			"name_overrides",
+			&TemplateGroupConfig{Name: String("name")},
+			&TemplateGroupConfig{Name: String("")},
+			&TemplateGroupConfig{Name: String("")},
+		},
+		{
+			"name_empty_one",
+			&TemplateGroupConfig{Name: String("name")},
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Name: String("name")},
+		},
+		{
+			"name_empty_two",
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Name: String("name")},
+			&TemplateGroupConfig{Name: String("name")},
+		},
+		{
+			"name_same",
+			&TemplateGroupConfig{Name: String("name")},
+			&TemplateGroupConfig{Name: String("name")},
+			&TemplateGroupConfig{Name: String("name")},
+		},
+		{
+			"path_overrides",
+			&TemplateGroupConfig{Path: String("path")},
+			&TemplateGroupConfig{Path: String("")},
+			&TemplateGroupConfig{Path: String("")},
+		},
+		{
+			"path_empty_one",
+			&TemplateGroupConfig{Path: String("path")},
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Path: String("path")},
+		},
+		{
+			"path_empty_two",
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{Path: String("path")},
+			&TemplateGroupConfig{Path: String("path")},
+		},
+		{
+			"path_same",
+			&TemplateGroupConfig{Path: String("path")},
+			&TemplateGroupConfig{Path: String("path")},
+			&TemplateGroupConfig{Path: String("path")},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestTemplateGroupConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *TemplateGroupConfig
+		r    *TemplateGroupConfig
+	}{
+		{
+			"empty",
+			&TemplateGroupConfig{},
+			&TemplateGroupConfig{
+				Check: &CheckConfig{
+					Command: String(""),
+					Enabled: Bool(false),
+					Format:  String(""),
+					Timeout: TimeDuration(DefaultCheckTimeout),
+				},
+				Keep: Int(DefaultTemplateGroupKeep),
+				Name: String(""),
+				Path: String(""),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -24,13 +25,22 @@ func TestTemplateConfig_Copy(t *testing.T) {
 			"same_enabled",
 			&TemplateConfig{
 				Backup:         Bool(true),
+				Check:          &CheckConfig{Format: String("json")},
 				Command:        String("command"),
 				CommandTimeout: TimeDuration(10 * time.Second),
 				Contents:       String("contents"),
 				Destination:    String("destination"),
 				Exec:           &ExecConfig{Command: String("command")},
+				Group:          String("group"),
+				OnFailure:      String(OnFailureStop),
+				ParallelGroup:  String("parallel"),
 				Perms:          FileMode(0600),
+				ReloadChild:    Bool(false),
+				RestartChild:   Bool(true),
+				Retry:          &RetryConfig{Attempts: Int(3)},
 				Source:         String("source"),
+				TemplateGroup:  String("group"),
+				User:           String("user"),
 				Wait:           &WaitConfig{Min: TimeDuration(10)},
 				LeftDelim:      String("left_delim"),
 				RightDelim:     String("right_delim"),
@@ -103,6 +113,30 @@ func TestTemplateConfig_Merge(t *testing.T) {
 			&TemplateConfig{Backup: Bool(true)},
 			&TemplateConfig{Backup: Bool(true)},
 		},
+		{
+			"check_overrides",
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("yaml")}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("yaml")}},
+		},
+		{
+			"check_empty_one",
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateConfig{Check: &CheckConfig{}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+		},
+		{
+			"check_empty_two",
+			&TemplateConfig{Check: &CheckConfig{}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+		},
+		{
+			"check_same",
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+			&TemplateConfig{Check: &CheckConfig{Format: String("json")}},
+		},
 		{
 			"command_overrides",
 			&TemplateConfig{Command: String("command")},
@@ -271,6 +305,105 @@ func TestTemplateConfig_Merge(t *testing.T) {
 			&TemplateConfig{Perms: FileMode(0600)},
 			&TemplateConfig{Perms: FileMode(0600)},
 		},
+		{
+			"group_overrides",
+			&TemplateConfig{Group: String("group")},
+			&TemplateConfig{Group: String("")},
+			&TemplateConfig{Group: String("")},
+		},
+		{
+			"group_empty_one",
+			&TemplateConfig{Group: String("group")},
+			&TemplateConfig{},
+			&TemplateConfig{Group: String("group")},
+		},
+		{
+			"group_empty_two",
+			&TemplateConfig{},
+			&TemplateConfig{Group: String("group")},
+			&TemplateConfig{Group: String("group")},
+		},
+		{
+			"on_failure_overrides",
+			&TemplateConfig{OnFailure: String(OnFailureContinue)},
+			&TemplateConfig{OnFailure: String(OnFailureRestoreBackup)},
+			&TemplateConfig{OnFailure: String(OnFailureRestoreBackup)},
+		},
+		{
+			"on_failure_empty_one",
+			&TemplateConfig{OnFailure: String(OnFailureStop)},
+			&TemplateConfig{},
+			&TemplateConfig{OnFailure: String(OnFailureStop)},
+		},
+		{
+			"parallel_group_overrides",
+			&TemplateConfig{ParallelGroup: String("one")},
+			&TemplateConfig{ParallelGroup: String("two")},
+			&TemplateConfig{ParallelGroup: String("two")},
+		},
+		{
+			"parallel_group_empty_two",
+			&TemplateConfig{},
+			&TemplateConfig{ParallelGroup: String("one")},
+			&TemplateConfig{ParallelGroup: String("one")},
+		},
+		{
+			"preserve_ownership_overrides",
+			&TemplateConfig{PreserveOwnership: Bool(true)},
+			&TemplateConfig{PreserveOwnership: Bool(false)},
+			&TemplateConfig{PreserveOwnership: Bool(false)},
+		},
+		{
+			"preserve_ownership_empty_one",
+			&TemplateConfig{PreserveOwnership: Bool(false)},
+			&TemplateConfig{},
+			&TemplateConfig{PreserveOwnership: Bool(false)},
+		},
+		{
+			"preserve_ownership_empty_two",
+			&TemplateConfig{},
+			&TemplateConfig{PreserveOwnership: Bool(false)},
+			&TemplateConfig{PreserveOwnership: Bool(false)},
+		},
+		{
+			"reload_child_overrides",
+			&TemplateConfig{ReloadChild: Bool(true)},
+			&TemplateConfig{ReloadChild: Bool(false)},
+			&TemplateConfig{ReloadChild: Bool(false)},
+		},
+		{
+			"reload_child_empty_one",
+			&TemplateConfig{ReloadChild: Bool(false)},
+			&TemplateConfig{},
+			&TemplateConfig{ReloadChild: Bool(false)},
+		},
+		{
+			"reload_child_signal_overrides",
+			&TemplateConfig{ReloadChildSignal: Signal(syscall.SIGUSR1)},
+			&TemplateConfig{ReloadChildSignal: Signal(syscall.SIGUSR2)},
+			&TemplateConfig{ReloadChildSignal: Signal(syscall.SIGUSR2)},
+		},
+		{
+			"render_timeout_overrides",
+			&TemplateConfig{RenderTimeout: TimeDuration(10 * time.Second)},
+			&TemplateConfig{RenderTimeout: TimeDuration(20 * time.Second)},
+			&TemplateConfig{RenderTimeout: TimeDuration(20 * time.Second)},
+		},
+		{
+			"restart_child_overrides",
+			&TemplateConfig{RestartChild: Bool(false)},
+			&TemplateConfig{RestartChild: Bool(true)},
+			&TemplateConfig{RestartChild: Bool(true)},
+		},
+		{
+			"retry_merges",
+			&TemplateConfig{Retry: &RetryConfig{Attempts: Int(3)}},
+			&TemplateConfig{Retry: &RetryConfig{Backoff: TimeDuration(time.Second)}},
+			&TemplateConfig{Retry: &RetryConfig{
+				Attempts: Int(3),
+				Backoff:  TimeDuration(time.Second),
+			}},
+		},
 		{
 			"source_overrides",
 			&TemplateConfig{Source: String("source")},
@@ -295,6 +428,42 @@ func TestTemplateConfig_Merge(t *testing.T) {
 			&TemplateConfig{Source: String("source")},
 			&TemplateConfig{Source: String("source")},
 		},
+		{
+			"template_group_overrides",
+			&TemplateConfig{TemplateGroup: String("group")},
+			&TemplateConfig{TemplateGroup: String("")},
+			&TemplateConfig{TemplateGroup: String("")},
+		},
+		{
+			"template_group_empty_one",
+			&TemplateConfig{TemplateGroup: String("group")},
+			&TemplateConfig{},
+			&TemplateConfig{TemplateGroup: String("group")},
+		},
+		{
+			"template_group_empty_two",
+			&TemplateConfig{},
+			&TemplateConfig{TemplateGroup: String("group")},
+			&TemplateConfig{TemplateGroup: String("group")},
+		},
+		{
+			"user_overrides",
+			&TemplateConfig{User: String("user")},
+			&TemplateConfig{User: String("")},
+			&TemplateConfig{User: String("")},
+		},
+		{
+			"user_empty_one",
+			&TemplateConfig{User: String("user")},
+			&TemplateConfig{},
+			&TemplateConfig{User: String("user")},
+		},
+		{
+			"user_empty_two",
+			&TemplateConfig{},
+			&TemplateConfig{User: String("user")},
+			&TemplateConfig{User: String("user")},
+		},
 		{
 			"wait_overrides",
 			&TemplateConfig{Wait: &WaitConfig{Min: TimeDuration(10)}},
@@ -389,7 +558,13 @@ func TestTemplateConfig_Finalize(t *testing.T) {
 			"empty",
 			&TemplateConfig{},
 			&TemplateConfig{
-				Backup:         Bool(false),
+				Backup: Bool(false),
+				Check: &CheckConfig{
+					Command: String(""),
+					Enabled: Bool(false),
+					Format:  String(""),
+					Timeout: TimeDuration(DefaultCheckTimeout),
+				},
 				Command:        String(""),
 				CommandTimeout: TimeDuration(DefaultTemplateCommandTimeout),
 				Contents:       String(""),
@@ -397,21 +572,61 @@ func TestTemplateConfig_Finalize(t *testing.T) {
 				ErrMissingKey:  Bool(false),
 				Exec: &ExecConfig{
 					Command: String(""),
-					Enabled: Bool(false),
+					Concurrency: &ConcurrencyConfig{
+						Enabled: Bool(false),
+						Limit:   Int(DefaultConcurrencyLimit),
+						Prefix:  String(""),
+					},
+					Critical: Bool(true),
+					Enabled:  Bool(false),
 					Env: &EnvConfig{
 						Blacklist: []string{},
 						Custom:    []string{},
 						Pristine:  Bool(false),
 						Whitelist: []string{},
 					},
-					KillSignal:   Signal(DefaultExecKillSignal),
-					KillTimeout:  TimeDuration(DefaultExecKillTimeout),
+					KillSignal:  Signal(DefaultExecKillSignal),
+					KillTimeout: TimeDuration(DefaultExecKillTimeout),
+					LivenessProbe: &ProbeConfig{
+						Command:          String(""),
+						Enabled:          Bool(false),
+						FailureThreshold: Int(DefaultProbeFailureThreshold),
+						HTTP:             String(""),
+						InitialDelay:     TimeDuration(0),
+						Interval:         TimeDuration(DefaultProbeInterval),
+						TCP:              String(""),
+						Timeout:          TimeDuration(DefaultProbeTimeout),
+					},
+					Name:         String(""),
 					ReloadSignal: Signal(DefaultExecReloadSignal),
-					Splay:        TimeDuration(0 * time.Second),
-					Timeout:      TimeDuration(DefaultTemplateCommandTimeout),
+					Restart: &RestartConfig{
+						Backoff:     TimeDuration(DefaultRestartBackoff),
+						MaxAttempts: Int(DefaultRestartMaxAttempts),
+						MaxBackoff:  TimeDuration(DefaultRestartMaxBackoff),
+						Policy:      String(RestartPolicyNever),
+					},
+					Splay:     TimeDuration(0 * time.Second),
+					Templates: []string{},
+					Timeout:   TimeDuration(DefaultTemplateCommandTimeout),
+				},
+				Group:             String(""),
+				OnFailure:         String(OnFailureContinue),
+				ParallelGroup:     String(""),
+				Perms:             FileMode(DefaultTemplateFilePerms),
+				PreserveOwnership: Bool(true),
+				ReloadChild:       Bool(true),
+				ReloadChildSignal: Signal(nil),
+				RenderTimeout:     TimeDuration(0),
+				RestartChild:      Bool(false),
+				Retry: &RetryConfig{
+					Attempts:   Int(DefaultRetryAttempts),
+					Backoff:    TimeDuration(DefaultRetryBackoff),
+					MaxBackoff: TimeDuration(DefaultRetryMaxBackoff),
+					Enabled:    Bool(false),
 				},
-				Perms:  FileMode(DefaultTemplateFilePerms),
-				Source: String(""),
+				Source:        String(""),
+				TemplateGroup: String(""),
+				User:          String(""),
 				Wait: &WaitConfig{
 					Enabled: Bool(false),
 					Max:     TimeDuration(0 * time.Second),
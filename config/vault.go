@@ -35,6 +35,10 @@ type VaultConfig struct {
 	// Address is the URI to the Vault server.
 	Address *string `mapstructure:"address"`
 
+	// Auth is the configuration for logging in to Vault with an auth method.
+	// When enabled, the resulting token takes precedence over Token.
+	Auth *VaultAuthConfig `mapstructure:"auth"`
+
 	// Enabled controls whether the Vault integration is active.
 	Enabled *bool `mapstructure:"enabled"`
 
@@ -67,6 +71,7 @@ type VaultConfig struct {
 // default values.
 func DefaultVaultConfig() *VaultConfig {
 	v := &VaultConfig{
+		Auth:      DefaultVaultAuthConfig(),
 		Retry:     DefaultRetryConfig(),
 		SSL:       DefaultSSLConfig(),
 		Transport: DefaultTransportConfig(),
@@ -87,6 +92,10 @@ func (c *VaultConfig) Copy() *VaultConfig {
 	var o VaultConfig
 	o.Address = c.Address
 
+	if c.Auth != nil {
+		o.Auth = c.Auth.Copy()
+	}
+
 	o.Enabled = c.Enabled
 
 	o.Grace = c.Grace
@@ -134,6 +143,10 @@ func (c *VaultConfig) Merge(o *VaultConfig) *VaultConfig {
 		r.Address = o.Address
 	}
 
+	if o.Auth != nil {
+		r.Auth = r.Auth.Merge(o.Auth)
+	}
+
 	if o.Enabled != nil {
 		r.Enabled = o.Enabled
 	}
@@ -177,6 +190,11 @@ func (c *VaultConfig) Finalize() {
 		}, "")
 	}
 
+	if c.Auth == nil {
+		c.Auth = DefaultVaultAuthConfig()
+	}
+	c.Auth.Finalize()
+
 	if c.Grace == nil {
 		c.Grace = TimeDuration(DefaultVaultGrace)
 	}
@@ -257,6 +275,7 @@ func (c *VaultConfig) GoString() string {
 
 	return fmt.Sprintf("&VaultConfig{"+
 		"Address:%s, "+
+		"Auth:%#v, "+
 		"Enabled:%s, "+
 		"Grace:%s, "+
 		"RenewToken:%s, "+
@@ -267,8 +286,9 @@ func (c *VaultConfig) GoString() string {
 		"UnwrapToken:%s"+
 		"}",
 		StringGoString(c.Address),
-		TimeDurationGoString(c.Grace),
+		c.Auth,
 		BoolGoString(c.Enabled),
+		TimeDurationGoString(c.Grace),
 		BoolGoString(c.RenewToken),
 		c.Retry,
 		c.SSL,
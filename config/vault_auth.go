@@ -0,0 +1,137 @@
+package config
+
+import "fmt"
+
+const (
+	// VaultAuthMethodAppRole is the name of Vault's AppRole auth method.
+	VaultAuthMethodAppRole = "approle"
+)
+
+// VaultAuthConfig is the configuration for logging in to Vault with an auth
+// method instead of a static token.
+type VaultAuthConfig struct {
+	// Enabled controls whether Consul Template logs in to Vault.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// Method is the name of the auth method to use. Only "approle" is currently
+	// supported.
+	Method *string `mapstructure:"method"`
+
+	// MountPath is the path where the auth method is mounted in Vault. The
+	// default value is the name of the method.
+	MountPath *string `mapstructure:"mount_path"`
+
+	// RoleIDFile is the path on disk to a file containing the AppRole role ID.
+	RoleIDFile *string `mapstructure:"role_id_file"`
+
+	// SecretIDFile is the path on disk to a file containing the AppRole secret
+	// ID. The file is re-read on each login, so the secret ID may be rotated
+	// out-of-band.
+	SecretIDFile *string `mapstructure:"secret_id_file"`
+}
+
+// DefaultVaultAuthConfig returns a configuration that is populated with the
+// default values.
+func DefaultVaultAuthConfig() *VaultAuthConfig {
+	return &VaultAuthConfig{}
+}
+
+// Copy returns a deep copy of this configuration.
+func (c *VaultAuthConfig) Copy() *VaultAuthConfig {
+	if c == nil {
+		return nil
+	}
+
+	var o VaultAuthConfig
+	o.Enabled = c.Enabled
+	o.Method = c.Method
+	o.MountPath = c.MountPath
+	o.RoleIDFile = c.RoleIDFile
+	o.SecretIDFile = c.SecretIDFile
+	return &o
+}
+
+// Merge combines all values in this configuration with the values in the other
+// configuration, with values in the other configuration taking precedence.
+// Maps and slices are merged, most other values are overwritten. Complex
+// structs define their own merge functionality.
+func (c *VaultAuthConfig) Merge(o *VaultAuthConfig) *VaultAuthConfig {
+	if c == nil {
+		if o == nil {
+			return nil
+		}
+		return o.Copy()
+	}
+
+	if o == nil {
+		return c.Copy()
+	}
+
+	r := c.Copy()
+
+	if o.Enabled != nil {
+		r.Enabled = o.Enabled
+	}
+
+	if o.Method != nil {
+		r.Method = o.Method
+	}
+
+	if o.MountPath != nil {
+		r.MountPath = o.MountPath
+	}
+
+	if o.RoleIDFile != nil {
+		r.RoleIDFile = o.RoleIDFile
+	}
+
+	if o.SecretIDFile != nil {
+		r.SecretIDFile = o.SecretIDFile
+	}
+
+	return r
+}
+
+// Finalize ensures there no nil pointers.
+func (c *VaultAuthConfig) Finalize() {
+	if c.Enabled == nil {
+		c.Enabled = Bool(StringPresent(c.Method))
+	}
+
+	if c.Method == nil {
+		c.Method = String("")
+	}
+
+	if c.MountPath == nil {
+		c.MountPath = String(StringVal(c.Method))
+	}
+
+	if c.RoleIDFile == nil {
+		c.RoleIDFile = String("")
+	}
+
+	if c.SecretIDFile == nil {
+		c.SecretIDFile = String("")
+	}
+}
+
+// GoString defines the printable version of this struct.
+func (c *VaultAuthConfig) GoString() string {
+	if c == nil {
+		return "(*VaultAuthConfig)(nil)"
+	}
+
+	return fmt.Sprintf("&VaultAuthConfig{"+
+		"Enabled:%s, "+
+		"Method:%s, "+
+		"MountPath:%s, "+
+		"RoleIDFile:%s, "+
+		"SecretIDFile:%s"+
+		"}",
+		BoolGoString(c.Enabled),
+		StringGoString(c.Method),
+		StringGoString(c.MountPath),
+		StringGoString(c.RoleIDFile),
+		StringGoString(c.SecretIDFile),
+	)
+}
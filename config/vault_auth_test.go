@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestVaultAuthConfig_Copy(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *VaultAuthConfig
+	}{
+		{
+			"nil",
+			nil,
+		},
+		{
+			"empty",
+			&VaultAuthConfig{},
+		},
+		{
+			"same_enabled",
+			&VaultAuthConfig{
+				Enabled:      Bool(true),
+				Method:       String("approle"),
+				MountPath:    String("approle"),
+				RoleIDFile:   String("role_id"),
+				SecretIDFile: String("secret_id"),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Copy()
+			if !reflect.DeepEqual(tc.a, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.a, r)
+			}
+		})
+	}
+}
+
+func TestVaultAuthConfig_Merge(t *testing.T) {
+	cases := []struct {
+		name string
+		a    *VaultAuthConfig
+		b    *VaultAuthConfig
+		r    *VaultAuthConfig
+	}{
+		{
+			"nil_a",
+			nil,
+			&VaultAuthConfig{},
+			&VaultAuthConfig{},
+		},
+		{
+			"nil_b",
+			&VaultAuthConfig{},
+			nil,
+			&VaultAuthConfig{},
+		},
+		{
+			"nil_both",
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"empty",
+			&VaultAuthConfig{},
+			&VaultAuthConfig{},
+			&VaultAuthConfig{},
+		},
+		{
+			"enabled_overrides",
+			&VaultAuthConfig{Enabled: Bool(true)},
+			&VaultAuthConfig{Enabled: Bool(false)},
+			&VaultAuthConfig{Enabled: Bool(false)},
+		},
+		{
+			"method_overrides",
+			&VaultAuthConfig{Method: String("approle")},
+			&VaultAuthConfig{Method: String("")},
+			&VaultAuthConfig{Method: String("")},
+		},
+		{
+			"method_empty_one",
+			&VaultAuthConfig{Method: String("approle")},
+			&VaultAuthConfig{},
+			&VaultAuthConfig{Method: String("approle")},
+		},
+		{
+			"mount_path_overrides",
+			&VaultAuthConfig{MountPath: String("approle")},
+			&VaultAuthConfig{MountPath: String("custom")},
+			&VaultAuthConfig{MountPath: String("custom")},
+		},
+		{
+			"role_id_file_overrides",
+			&VaultAuthConfig{RoleIDFile: String("a")},
+			&VaultAuthConfig{RoleIDFile: String("b")},
+			&VaultAuthConfig{RoleIDFile: String("b")},
+		},
+		{
+			"secret_id_file_empty_two",
+			&VaultAuthConfig{},
+			&VaultAuthConfig{SecretIDFile: String("b")},
+			&VaultAuthConfig{SecretIDFile: String("b")},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			r := tc.a.Merge(tc.b)
+			if !reflect.DeepEqual(tc.r, r) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, r)
+			}
+		})
+	}
+}
+
+func TestVaultAuthConfig_Finalize(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *VaultAuthConfig
+		r    *VaultAuthConfig
+	}{
+		{
+			"empty",
+			&VaultAuthConfig{},
+			&VaultAuthConfig{
+				Enabled:      Bool(false),
+				Method:       String(""),
+				MountPath:    String(""),
+				RoleIDFile:   String(""),
+				SecretIDFile: String(""),
+			},
+		},
+		{
+			"with_method",
+			&VaultAuthConfig{
+				Method: String("approle"),
+			},
+			&VaultAuthConfig{
+				Enabled:      Bool(true),
+				Method:       String("approle"),
+				MountPath:    String("approle"),
+				RoleIDFile:   String(""),
+				SecretIDFile: String(""),
+			},
+		},
+		{
+			"with_mount_path",
+			&VaultAuthConfig{
+				Method:    String("approle"),
+				MountPath: String("custom"),
+			},
+			&VaultAuthConfig{
+				Enabled:      Bool(true),
+				Method:       String("approle"),
+				MountPath:    String("custom"),
+				RoleIDFile:   String(""),
+				SecretIDFile: String(""),
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.i.Finalize()
+			if !reflect.DeepEqual(tc.r, tc.i) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.r, tc.i)
+			}
+		})
+	}
+}
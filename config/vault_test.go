@@ -319,7 +319,14 @@ func TestVaultConfig_Finalize(t *testing.T) {
 			"empty",
 			&VaultConfig{},
 			&VaultConfig{
-				Address:    String(""),
+				Address: String(""),
+				Auth: &VaultAuthConfig{
+					Enabled:      Bool(false),
+					Method:       String(""),
+					MountPath:    String(""),
+					RoleIDFile:   String(""),
+					SecretIDFile: String(""),
+				},
 				Enabled:    Bool(false),
 				Grace:      TimeDuration(DefaultVaultGrace),
 				RenewToken: Bool(DefaultVaultRenewToken),
@@ -357,7 +364,14 @@ func TestVaultConfig_Finalize(t *testing.T) {
 				Address: String("address"),
 			},
 			&VaultConfig{
-				Address:    String("address"),
+				Address: String("address"),
+				Auth: &VaultAuthConfig{
+					Enabled:      Bool(false),
+					Method:       String(""),
+					MountPath:    String(""),
+					RoleIDFile:   String(""),
+					SecretIDFile: String(""),
+				},
 				Enabled:    Bool(true),
 				Grace:      TimeDuration(DefaultVaultGrace),
 				RenewToken: Bool(DefaultVaultRenewToken),
@@ -395,7 +409,14 @@ func TestVaultConfig_Finalize(t *testing.T) {
 				Address: String("address"),
 			},
 			&VaultConfig{
-				Address:    String("address"),
+				Address: String("address"),
+				Auth: &VaultAuthConfig{
+					Enabled:      Bool(false),
+					Method:       String(""),
+					MountPath:    String(""),
+					RoleIDFile:   String(""),
+					SecretIDFile: String(""),
+				},
 				Enabled:    Bool(true),
 				Grace:      TimeDuration(DefaultVaultGrace),
 				RenewToken: Bool(DefaultVaultRenewToken),
@@ -37,22 +37,33 @@ type Node struct {
 type CatalogNodesQuery struct {
 	stopCh chan struct{}
 
-	dc   string
-	near string
+	dc       string
+	near     string
+	nodeMeta map[string]string
 }
 
 // NewCatalogNodesQuery parses the given string into a dependency. If the name is
-// empty then the name of the local agent is used.
-func NewCatalogNodesQuery(s string) (*CatalogNodesQuery, error) {
+// empty then the name of the local agent is used. Optional "node-meta=key:value"
+// arguments filter the nodes which are returned.
+func NewCatalogNodesQuery(s string, opts ...string) (*CatalogNodesQuery, error) {
 	if !CatalogNodesQueryRe.MatchString(s) {
 		return nil, fmt.Errorf("catalog.nodes: invalid format: %q", s)
 	}
 
+	qf, err := parseQueryFilters("", opts)
+	if err != nil {
+		return nil, fmt.Errorf("catalog.nodes: %s in %q", err, s)
+	}
+	if len(qf.tags) > 0 || len(qf.excludeTags) > 0 {
+		return nil, fmt.Errorf("catalog.nodes: tags are not supported in %q", s)
+	}
+
 	m := regexpMatch(CatalogNodesQueryRe, s)
 	return &CatalogNodesQuery{
-		dc:     m["dc"],
-		near:   m["near"],
-		stopCh: make(chan struct{}, 1),
+		dc:       m["dc"],
+		near:     m["near"],
+		nodeMeta: qf.nodeMeta,
+		stopCh:   make(chan struct{}, 1),
 	}, nil
 }
 
@@ -68,6 +79,7 @@ func (d *CatalogNodesQuery) Fetch(clients *ClientSet, opts *QueryOptions) (inter
 	opts = opts.Merge(&QueryOptions{
 		Datacenter: d.dc,
 		Near:       d.near,
+		NodeMeta:   d.nodeMeta,
 	})
 
 	log.Printf("[TRACE] %s: GET %s", d, &url.URL{
@@ -116,6 +128,9 @@ func (d *CatalogNodesQuery) String() string {
 	if d.near != "" {
 		name = name + "~" + d.near
 	}
+	if len(d.nodeMeta) > 0 {
+		name = name + "|" + nodeMetaString(d.nodeMeta)
+	}
 
 	if name == "" {
 		return "catalog.nodes"
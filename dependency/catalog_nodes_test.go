@@ -71,6 +71,58 @@ func TestNewCatalogNodesQuery(t *testing.T) {
 	}
 }
 
+func TestNewCatalogNodesQuery_options(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		opts []string
+		exp  *CatalogNodesQuery
+		err  bool
+	}{
+		{
+			"node_meta",
+			"@dc1",
+			[]string{"node-meta=rack:r1"},
+			&CatalogNodesQuery{
+				dc:       "dc1",
+				nodeMeta: map[string]string{"rack": "r1"},
+			},
+			false,
+		},
+		{
+			"tag",
+			"",
+			[]string{"tag=v2"},
+			nil,
+			true,
+		},
+		{
+			"invalid",
+			"",
+			[]string{"node-meta="},
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := NewCatalogNodesQuery(tc.i, tc.opts...)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				act.stopCh = nil
+			}
+
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
 func TestCatalogNodesQuery_Fetch(t *testing.T) {
 	t.Parallel()
 
@@ -159,4 +211,12 @@ func TestCatalogNodesQuery_String(t *testing.T) {
 			assert.Equal(t, tc.exp, d.String())
 		})
 	}
+
+	t.Run("node_meta", func(t *testing.T) {
+		d, err := NewCatalogNodesQuery("@dc1", "node-meta=rack:r1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "catalog.nodes(@dc1|node-meta=rack:r1)", d.String())
+	})
 }
@@ -42,25 +42,37 @@ type CatalogService struct {
 type CatalogServiceQuery struct {
 	stopCh chan struct{}
 
-	dc   string
-	name string
-	near string
-	tag  string
+	dc          string
+	excludeTags []string
+	name        string
+	near        string
+	nodeMeta    map[string]string
+	tags        []string
 }
 
-// NewCatalogServiceQuery parses a string into a CatalogServiceQuery.
-func NewCatalogServiceQuery(s string) (*CatalogServiceQuery, error) {
+// NewCatalogServiceQuery parses a string into a CatalogServiceQuery. Optional
+// "key=value" arguments, such as "node-meta=rack:r1", "tag=v2" or
+// "exclude-tag=canary", further filter the services which are returned.
+func NewCatalogServiceQuery(s string, opts ...string) (*CatalogServiceQuery, error) {
 	if !CatalogServiceQueryRe.MatchString(s) {
 		return nil, fmt.Errorf("catalog.service: invalid format: %q", s)
 	}
 
 	m := regexpMatch(CatalogServiceQueryRe, s)
+
+	qf, err := parseQueryFilters(m["tag"], opts)
+	if err != nil {
+		return nil, fmt.Errorf("catalog.service: %s in %q", err, s)
+	}
+
 	return &CatalogServiceQuery{
-		stopCh: make(chan struct{}, 1),
-		dc:     m["dc"],
-		name:   m["name"],
-		near:   m["near"],
-		tag:    m["tag"],
+		stopCh:      make(chan struct{}, 1),
+		dc:          m["dc"],
+		excludeTags: qf.excludeTags,
+		name:        m["name"],
+		near:        m["near"],
+		nodeMeta:    qf.nodeMeta,
+		tags:        qf.tags,
 	}, nil
 }
 
@@ -76,20 +88,25 @@ func (d *CatalogServiceQuery) Fetch(clients *ClientSet, opts *QueryOptions) (int
 	opts = opts.Merge(&QueryOptions{
 		Datacenter: d.dc,
 		Near:       d.near,
+		NodeMeta:   d.nodeMeta,
 	})
 
+	// Consul only filters by a single tag, so any other required or excluded
+	// tags are filtered client-side.
+	tag := serverTag(d.tags)
+
 	u := &url.URL{
 		Path:     "/v1/catalog/service/" + d.name,
 		RawQuery: opts.String(),
 	}
-	if d.tag != "" {
+	if tag != "" {
 		q := u.Query()
-		q.Set("tag", d.tag)
+		q.Set("tag", tag)
 		u.RawQuery = q.Encode()
 	}
 	log.Printf("[TRACE] %s: GET %s", d, u)
 
-	entries, qm, err := clients.Consul().Catalog().Service(d.name, d.tag, opts.ToConsulOpts())
+	entries, qm, err := clients.Consul().Catalog().Service(d.name, tag, opts.ToConsulOpts())
 	if err != nil {
 		return nil, nil, errors.Wrap(err, d.String())
 	}
@@ -98,6 +115,10 @@ func (d *CatalogServiceQuery) Fetch(clients *ClientSet, opts *QueryOptions) (int
 
 	var list []*CatalogService
 	for _, s := range entries {
+		if !acceptTags(s.ServiceTags, d.tags, d.excludeTags) {
+			continue
+		}
+
 		list = append(list, &CatalogService{
 			ID:              s.ID,
 			Node:            s.Node,
@@ -128,16 +149,18 @@ func (d *CatalogServiceQuery) CanShare() bool {
 
 // String returns the human-friendly version of this dependency.
 func (d *CatalogServiceQuery) String() string {
-	name := d.name
-	if d.tag != "" {
-		name = d.tag + "." + name
-	}
+	prefix, suffix := tagsString(d.tags, d.excludeTags)
+	name := prefix + d.name
 	if d.dc != "" {
 		name = name + "@" + d.dc
 	}
 	if d.near != "" {
 		name = name + "~" + d.near
 	}
+	name = name + suffix
+	if len(d.nodeMeta) > 0 {
+		name = name + "|" + nodeMetaString(d.nodeMeta)
+	}
 	return fmt.Sprintf("catalog.service(%s)", name)
 }
 
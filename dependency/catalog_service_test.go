@@ -81,7 +81,7 @@ func TestNewCatalogServiceQuery(t *testing.T) {
 			"tag.name",
 			&CatalogServiceQuery{
 				name: "name",
-				tag:  "tag",
+				tags: []string{"tag"},
 			},
 			false,
 		},
@@ -91,7 +91,7 @@ func TestNewCatalogServiceQuery(t *testing.T) {
 			&CatalogServiceQuery{
 				dc:   "dc",
 				name: "name",
-				tag:  "tag",
+				tags: []string{"tag"},
 			},
 			false,
 		},
@@ -101,7 +101,7 @@ func TestNewCatalogServiceQuery(t *testing.T) {
 			&CatalogServiceQuery{
 				name: "name",
 				near: "near",
-				tag:  "tag",
+				tags: []string{"tag"},
 			},
 			false,
 		},
@@ -112,7 +112,7 @@ func TestNewCatalogServiceQuery(t *testing.T) {
 				dc:   "dc",
 				name: "name",
 				near: "near",
-				tag:  "tag",
+				tags: []string{"tag"},
 			},
 			false,
 		},
@@ -248,3 +248,60 @@ func TestCatalogServiceQuery_String(t *testing.T) {
 		})
 	}
 }
+
+func TestNewCatalogServiceQuery_options(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		opts []string
+		exp  *CatalogServiceQuery
+		err  bool
+	}{
+		{
+			"node_meta_tag",
+			"name",
+			[]string{"node-meta=rack:r1", "tag=v2"},
+			&CatalogServiceQuery{
+				name:     "name",
+				nodeMeta: map[string]string{"rack": "r1"},
+				tags:     []string{"v2"},
+			},
+			false,
+		},
+		{
+			"tags_exclude_tags",
+			"v1.name",
+			[]string{"tag=v2", "exclude-tag=canary"},
+			&CatalogServiceQuery{
+				excludeTags: []string{"canary"},
+				name:        "name",
+				tags:        []string{"v1", "v2"},
+			},
+			false,
+		},
+		{
+			"required_and_excluded",
+			"name",
+			[]string{"tag=v2", "exclude-tag=v2"},
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := NewCatalogServiceQuery(tc.i, tc.opts...)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				act.stopCh = nil
+			}
+
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
@@ -3,9 +3,11 @@ package dependency
 import (
 	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +23,14 @@ type ClientSet struct {
 
 	vault  *vaultClient
 	consul *consulClient
+
+	// vaultMounts caches the Vault secrets engine mounts that have been looked
+	// up, keyed by mount path. vaultMountMisses holds when failed lookups may be
+	// retried, keyed by the first segment of the path. Both are protected by
+	// vaultMountsLock.
+	vaultMounts      map[string]*vaultMount
+	vaultMountMisses map[string]time.Time
+	vaultMountsLock  sync.Mutex
 }
 
 // consulClient is a wrapper around a real Consul API client.
@@ -33,6 +43,20 @@ type consulClient struct {
 type vaultClient struct {
 	client     *vaultapi.Client
 	httpClient *http.Client
+
+	// auth is the auth method used to obtain the client token. This is nil if
+	// a static token was given. authSecret is the secret of the last login.
+	auth       *vaultAuth
+	authSecret *vaultapi.Secret
+}
+
+// vaultAuth is the information required to log in to Vault with an auth
+// method and obtain a fresh client token.
+type vaultAuth struct {
+	method       string
+	mountPath    string
+	roleIDFile   string
+	secretIDFile string
 }
 
 // CreateConsulClientInput is used as input to the CreateConsulClient function.
@@ -61,16 +85,21 @@ type CreateConsulClientInput struct {
 
 // CreateVaultClientInput is used as input to the CreateVaultClient function.
 type CreateVaultClientInput struct {
-	Address     string
-	Token       string
-	UnwrapToken bool
-	SSLEnabled  bool
-	SSLVerify   bool
-	SSLCert     string
-	SSLKey      string
-	SSLCACert   string
-	SSLCAPath   string
-	ServerName  string
+	Address          string
+	Token            string
+	UnwrapToken      bool
+	AuthEnabled      bool
+	AuthMethod       string
+	AuthMountPath    string
+	AuthRoleIDFile   string
+	AuthSecretIDFile string
+	SSLEnabled       bool
+	SSLVerify        bool
+	SSLCert          string
+	SSLKey           string
+	SSLCACert        string
+	SSLCAPath        string
+	ServerName       string
 
 	TransportDialKeepAlive       time.Duration
 	TransportDialTimeout         time.Duration
@@ -292,11 +321,33 @@ func (c *ClientSet) CreateVaultClient(i *CreateVaultClientInput) error {
 		client.SetToken(secret.Auth.ClientToken)
 	}
 
+	// Log in with the auth method, if one was given. The resulting token takes
+	// precedence over any static token.
+	var auth *vaultAuth
+	var authSecret *vaultapi.Secret
+	if i.AuthEnabled {
+		auth = &vaultAuth{
+			method:       i.AuthMethod,
+			mountPath:    i.AuthMountPath,
+			roleIDFile:   i.AuthRoleIDFile,
+			secretIDFile: i.AuthSecretIDFile,
+		}
+
+		secret, err := auth.login(client)
+		if err != nil {
+			return fmt.Errorf("client set: vault auth: %s", err)
+		}
+		client.SetToken(secret.Auth.ClientToken)
+		authSecret = secret
+	}
+
 	// Save the data on ourselves
 	c.Lock()
 	c.vault = &vaultClient{
 		client:     client,
 		httpClient: vaultConfig.HttpClient,
+		auth:       auth,
+		authSecret: authSecret,
 	}
 	c.Unlock()
 
@@ -317,6 +368,144 @@ func (c *ClientSet) Vault() *vaultapi.Client {
 	return c.vault.client
 }
 
+// vaultAuthEnabled returns true if the Vault client obtains its token by
+// logging in with an auth method.
+func (c *ClientSet) vaultAuthEnabled() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.vault != nil && c.vault.auth != nil
+}
+
+// vaultAuthSecret returns the secret of the last login with the auth method,
+// or nil if the Vault client does not use an auth method.
+func (c *ClientSet) vaultAuthSecret() *vaultapi.Secret {
+	c.RLock()
+	defer c.RUnlock()
+	if c.vault == nil {
+		return nil
+	}
+	return c.vault.authSecret
+}
+
+// vaultLogin logs in to Vault again using the configured auth method and
+// replaces the token on the shared Vault client, so every dependency picks up
+// the new token on its next request. The login secret is returned so the
+// caller can renew the new token.
+func (c *ClientSet) vaultLogin() (*vaultapi.Secret, error) {
+	// Log in with a copy of the client, so the lock is not held while the login
+	// request is in flight and the other dependencies can still use the client.
+	// The copy is shallow: Clone would configure the shared transport again.
+	c.RLock()
+	vault := c.vault
+	var client vaultapi.Client
+	if vault != nil && vault.auth != nil {
+		client = *vault.client
+	}
+	c.RUnlock()
+
+	if vault == nil || vault.auth == nil {
+		return nil, fmt.Errorf("client set: vault auth: no auth method configured")
+	}
+
+	secret, err := vault.auth.login(&client)
+	if err != nil {
+		return nil, fmt.Errorf("client set: vault auth: %s", err)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	// The Vault client may have been replaced during the login, in which case
+	// it has a token of its own.
+	if c.vault != vault {
+		return nil, fmt.Errorf("client set: vault auth: vault client was replaced during login")
+	}
+	vault.client.SetToken(secret.Auth.ClientToken)
+	vault.authSecret = secret
+
+	log.Printf("[INFO] (clients) logged in to vault using %s auth", vault.auth.method)
+	return secret, nil
+}
+
+// login authenticates against Vault with the auth method and returns the
+// resulting secret. The credentials are read from disk on every login so they
+// may be rotated while Consul Template is running.
+func (a *vaultAuth) login(client *vaultapi.Client) (*vaultapi.Secret, error) {
+	var data map[string]interface{}
+
+	switch a.method {
+	case "approle":
+		roleID, err := readCredentialFile(a.roleIDFile)
+		if err != nil {
+			return nil, fmt.Errorf("role_id: %s", err)
+		}
+		data = map[string]interface{}{"role_id": roleID}
+
+		// The secret ID is optional when the role does not bind it.
+		if a.secretIDFile != "" {
+			secretID, err := readCredentialFile(a.secretIDFile)
+			if err != nil {
+				return nil, fmt.Errorf("secret_id: %s", err)
+			}
+			data["secret_id"] = secretID
+		}
+	default:
+		return nil, fmt.Errorf("unsupported method %q", a.method)
+	}
+
+	mountPath := a.mountPath
+	if mountPath == "" {
+		mountPath = a.method
+	}
+	mountPath = strings.Trim(mountPath, "/")
+
+	// Build the request by hand so the (possibly expired) token currently set
+	// on the client is not sent along with the login request.
+	r := client.NewRequest("PUT", "/v1/auth/"+mountPath+"/login")
+	r.ClientToken = ""
+	if err := r.SetJSONBody(data); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.RawRequest(r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := vaultapi.ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("no token returned")
+	}
+
+	return secret, nil
+}
+
+// readCredentialFile reads the file at the given path and returns its
+// contents with surrounding whitespace removed.
+func readCredentialFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("missing file path")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return s, nil
+}
+
 // Stop closes all idle connections for any attached clients.
 func (c *ClientSet) Stop() {
 	c.Lock()
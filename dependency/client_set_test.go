@@ -1,7 +1,16 @@
 package dependency
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 )
@@ -53,3 +62,147 @@ func TestClientSet_unwrapVaultToken(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestClientSet_vaultAuthAppRole(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	roleIDFile := filepath.Join(dir, "role_id")
+	if err := ioutil.WriteFile(roleIDFile, []byte("my-role\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secretIDFile := filepath.Join(dir, "secret_id")
+	if err := ioutil.WriteFile(secretIDFile, []byte("my-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake Vault hands out a new token on every login.
+	var logins int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/approle/login" || r.Method != "PUT" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != "" {
+			t.Errorf("expected no token on login request")
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body["role_id"] != "my-role" || body["secret_id"] != "my-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		n := atomic.AddInt32(&logins, 1)
+		fmt.Fprintf(w, `{"auth":{"client_token":"token-%d","lease_duration":60,"renewable":true}}`, n)
+	}))
+	defer ts.Close()
+
+	clients := NewClientSet()
+	if err := clients.CreateVaultClient(&CreateVaultClientInput{
+		Address:          ts.URL,
+		Token:            "static-token",
+		AuthEnabled:      true,
+		AuthMethod:       "approle",
+		AuthRoleIDFile:   roleIDFile,
+		AuthSecretIDFile: secretIDFile,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !clients.vaultAuthEnabled() {
+		t.Fatal("expected vault auth to be enabled")
+	}
+
+	if act := clients.Vault().Token(); act != "token-1" {
+		t.Errorf("expected %q to be %q", act, "token-1")
+	}
+
+	secret, err := clients.vaultLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Auth.LeaseDuration != 60 {
+		t.Errorf("expected %d to be %d", secret.Auth.LeaseDuration, 60)
+	}
+
+	if act := clients.Vault().Token(); act != "token-2" {
+		t.Errorf("expected %q to be %q", act, "token-2")
+	}
+}
+
+func TestClientSet_vaultLoginUnlocked(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	roleIDFile := filepath.Join(dir, "role_id")
+	if err := ioutil.WriteFile(roleIDFile, []byte("my-role"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake Vault reads the client set while handling the second login,
+	// which blocks if the login holds the lock.
+	clients := NewClientSet()
+	var logins int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&logins, 1)
+		if n > 1 {
+			doneCh := make(chan struct{})
+			go func() {
+				clients.Vault()
+				close(doneCh)
+			}()
+			select {
+			case <-doneCh:
+			case <-time.After(2 * time.Second):
+				t.Error("client set locked during login")
+			}
+		}
+		fmt.Fprintf(w, `{"auth":{"client_token":"token-%d","lease_duration":60,"renewable":true}}`, n)
+	}))
+	defer ts.Close()
+
+	if err := clients.CreateVaultClient(&CreateVaultClientInput{
+		Address:        ts.URL,
+		AuthEnabled:    true,
+		AuthMethod:     "approle",
+		AuthRoleIDFile: roleIDFile,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := clients.vaultLogin(); err != nil {
+		t.Fatal(err)
+	}
+	if act := clients.Vault().Token(); act != "token-2" {
+		t.Errorf("expected %q to be %q", act, "token-2")
+	}
+}
+
+func TestClientSet_vaultAuthUnsupportedMethod(t *testing.T) {
+	t.Parallel()
+
+	clients := NewClientSet()
+	err := clients.CreateVaultClient(&CreateVaultClientInput{
+		Address:     "http://127.0.0.1:0",
+		AuthEnabled: true,
+		AuthMethod:  "nope",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -1,10 +1,12 @@
 package dependency
 
 import (
+	"fmt"
 	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	consulapi "github.com/hashicorp/consul/api"
@@ -28,6 +30,20 @@ const (
 	TypeLocal
 )
 
+// String returns the name of the upstream for this type.
+func (t Type) String() string {
+	switch t {
+	case TypeConsul:
+		return "consul"
+	case TypeVault:
+		return "vault"
+	case TypeLocal:
+		return "local"
+	default:
+		return "unknown"
+	}
+}
+
 // Dependency is an interface for a dependency that Consul Template is capable
 // of watching.
 type Dependency interface {
@@ -48,6 +64,7 @@ type QueryOptions struct {
 	AllowStale        bool
 	Datacenter        string
 	Near              string
+	NodeMeta          map[string]string
 	RequireConsistent bool
 	VaultGrace        time.Duration
 	WaitIndex         uint64
@@ -83,6 +100,10 @@ func (q *QueryOptions) Merge(o *QueryOptions) *QueryOptions {
 		r.Near = o.Near
 	}
 
+	if len(o.NodeMeta) > 0 {
+		r.NodeMeta = o.NodeMeta
+	}
+
 	if o.RequireConsistent != false {
 		r.RequireConsistent = o.RequireConsistent
 	}
@@ -103,6 +124,7 @@ func (q *QueryOptions) ToConsulOpts() *consulapi.QueryOptions {
 		AllowStale:        q.AllowStale,
 		Datacenter:        q.Datacenter,
 		Near:              q.Near,
+		NodeMeta:          q.NodeMeta,
 		RequireConsistent: q.RequireConsistent,
 		WaitIndex:         q.WaitIndex,
 		WaitTime:          q.WaitTime,
@@ -124,6 +146,10 @@ func (q *QueryOptions) String() string {
 		u.Add("near", q.Near)
 	}
 
+	for _, k := range sortedKeys(q.NodeMeta) {
+		u.Add("node-meta", k+":"+q.NodeMeta[k])
+	}
+
 	if q.RequireConsistent {
 		u.Add("consistent", strconv.FormatBool(q.RequireConsistent))
 	}
@@ -158,6 +184,156 @@ func deepCopyAndSortTags(tags []string) []string {
 	return newTags
 }
 
+// queryFilters are the optional filters accepted by the catalog and health
+// queries, in addition to those in the query string itself.
+type queryFilters struct {
+	nodeMeta    map[string]string
+	tags        []string
+	excludeTags []string
+}
+
+// parseQueryFilters parses the optional "key=value" arguments accepted by the
+// catalog and health queries. The "node-meta" key takes a "key:value" pair. The
+// "tag" and "exclude-tag" keys filter services by tag. Each key may be given
+// more than once. The given tag, if any, is the tag from the query string and
+// is added to the required tags.
+func parseQueryFilters(tag string, opts []string) (*queryFilters, error) {
+	var f queryFilters
+	if tag != "" {
+		f.tags = append(f.tags, tag)
+	}
+
+	for _, opt := range opts {
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid option: %q", opt)
+		}
+
+		switch k, v := parts[0], parts[1]; k {
+		case "node-meta":
+			meta := strings.SplitN(v, ":", 2)
+			if len(meta) != 2 || meta[0] == "" {
+				return nil, fmt.Errorf("invalid node-meta: %q, expected key:value", v)
+			}
+			if f.nodeMeta == nil {
+				f.nodeMeta = make(map[string]string)
+			}
+			if _, ok := f.nodeMeta[meta[0]]; ok {
+				return nil, fmt.Errorf("duplicate node-meta key: %q", meta[0])
+			}
+			f.nodeMeta[meta[0]] = meta[1]
+		case "tag":
+			f.tags = append(f.tags, v)
+		case "exclude-tag":
+			f.excludeTags = append(f.excludeTags, v)
+		default:
+			return nil, fmt.Errorf("unknown option: %q", k)
+		}
+	}
+
+	f.tags = uniqueSortedStrings(f.tags)
+	f.excludeTags = uniqueSortedStrings(f.excludeTags)
+
+	for _, t := range f.excludeTags {
+		if containsString(f.tags, t) {
+			return nil, fmt.Errorf("tag %q is both required and excluded", t)
+		}
+	}
+
+	return &f, nil
+}
+
+// acceptTags returns true if the given service tags include every required tag
+// and none of the excluded tags.
+func acceptTags(tags, required, excluded []string) bool {
+	for _, t := range required {
+		if !containsString(tags, t) {
+			return false
+		}
+	}
+	for _, t := range excluded {
+		if containsString(tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// serverTag returns the tag to send to Consul, which only supports filtering by
+// a single tag. Any other tags are filtered by acceptTags.
+func serverTag(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return tags[0]
+}
+
+// tagsString returns the tag filters as a prefix and a suffix suitable for use
+// in a dependency's String. The required tags are returned in the same form as
+// the "tag." prefix in the query string, such as "primary,v2.", and the
+// excluded tags as an option, such as "|exclude-tag=canary".
+func tagsString(tags, excluded []string) (string, string) {
+	var prefix, suffix string
+	if len(tags) > 0 {
+		prefix = strings.Join(tags, ",") + "."
+	}
+	if len(excluded) > 0 {
+		suffix = "|exclude-tag=" + strings.Join(excluded, ",")
+	}
+	return prefix, suffix
+}
+
+// containsString returns true if the slice contains the given string.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueSortedStrings returns a sorted copy of the list with any duplicates
+// removed, or nil if the list is empty.
+func uniqueSortedStrings(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+
+	sorted := deepCopyAndSortTags(list)
+	result := sorted[:1]
+	for _, v := range sorted[1:] {
+		if v != result[len(result)-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// nodeMetaString returns the node metadata filter as a string suitable for
+// use in a dependency's String, or the empty string if there is no filter.
+func nodeMetaString(m map[string]string) string {
+	if len(m) == 0 {
+		return ""
+	}
+
+	list := make([]string, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		list = append(list, k+":"+m[k])
+	}
+	return "node-meta=" + strings.Join(list, ",")
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // respWithMetadata is a short wrapper to return the given interface with fake
 // response metadata for non-Consul dependencies.
 func respWithMetadata(i interface{}) (interface{}, *ResponseMetadata, error) {
@@ -70,6 +70,7 @@ func TestCanShare(t *testing.T) {
 
 	deps := []Dependency{
 		&CatalogNodeQuery{},
+		&FileGlobQuery{},
 		&FileQuery{},
 		&VaultListQuery{},
 		&VaultReadQuery{},
@@ -96,6 +97,79 @@ func TestDeepCopyAndSortTags(t *testing.T) {
 	}
 }
 
+func TestQueryOptions_String(t *testing.T) {
+	t.Parallel()
+
+	opts := &QueryOptions{
+		Datacenter: "dc1",
+		NodeMeta: map[string]string{
+			"zone": "a",
+			"rack": "r1",
+		},
+	}
+
+	expected := "dc=dc1&node-meta=rack%3Ar1&node-meta=zone%3Aa"
+	if result := opts.String(); result != expected {
+		t.Errorf("expected %q to be %q", result, expected)
+	}
+}
+
+func TestAcceptTags(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		tags     []string
+		required []string
+		excluded []string
+		exp      bool
+	}{
+		{
+			"no_filters",
+			[]string{"a"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"all_required",
+			[]string{"primary", "v2", "east"},
+			[]string{"primary", "v2"},
+			nil,
+			true,
+		},
+		{
+			"missing_required",
+			[]string{"primary"},
+			[]string{"primary", "v2"},
+			nil,
+			false,
+		},
+		{
+			"excluded",
+			[]string{"primary", "v2", "canary"},
+			[]string{"primary", "v2"},
+			[]string{"canary"},
+			false,
+		},
+		{
+			"not_excluded",
+			[]string{"primary", "v2"},
+			[]string{"primary"},
+			[]string{"canary"},
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			if act := acceptTags(tc.tags, tc.required, tc.excluded); act != tc.exp {
+				t.Errorf("expected %t to be %t", act, tc.exp)
+			}
+		})
+	}
+}
+
 type vaultServer struct {
 	Address string
 	Token   string
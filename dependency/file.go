@@ -1,11 +1,15 @@
 package dependency
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,8 +19,9 @@ var (
 	// Ensure implements
 	_ Dependency = (*FileQuery)(nil)
 
-	// FileQuerySleepTime is the amount of time to sleep between queries, since
-	// the fsnotify library is not compatible with solaris and other OSes yet.
+	// FileQuerySleepTime is the amount of time to sleep between queries when
+	// filesystem events are not available. Filesystem events are only supported
+	// on Linux.
 	FileQuerySleepTime = 2 * time.Second
 )
 
@@ -26,6 +31,12 @@ type FileQuery struct {
 
 	path string
 	stat os.FileInfo
+	hash []byte
+
+	// sub is the subscription to filesystem events for the file's directory.
+	// It is nil if filesystem events are not available.
+	subLock sync.Mutex
+	sub     *fileSubscription
 }
 
 // NewFileQuery creates a file dependency from the given path.
@@ -46,24 +57,36 @@ func NewFileQuery(s string) (*FileQuery, error) {
 func (d *FileQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interface{}, *ResponseMetadata, error) {
 	log.Printf("[TRACE] %s: READ %s", d, d.path)
 
-	select {
-	case <-d.stopCh:
-		log.Printf("[TRACE] %s: stopped", d)
-		return "", nil, ErrStopped
-	case r := <-d.watch(d.stat):
-		if r.err != nil {
-			return "", nil, errors.Wrap(r.err, d.String())
-		}
+	for {
+		select {
+		case <-d.stopCh:
+			log.Printf("[TRACE] %s: stopped", d)
+			return "", nil, ErrStopped
+		case r := <-d.watch(d.stat):
+			if r.err != nil {
+				return "", nil, errors.Wrap(r.err, d.String())
+			}
 
-		log.Printf("[TRACE] %s: reported change", d)
+			log.Printf("[TRACE] %s: reported change", d)
 
-		data, err := ioutil.ReadFile(d.path)
-		if err != nil {
-			return "", nil, errors.Wrap(err, d.String())
-		}
+			data, err := ioutil.ReadFile(d.path)
+			if err != nil {
+				return "", nil, errors.Wrap(err, d.String())
+			}
 
-		d.stat = r.stat
-		return respWithMetadata(string(data))
+			d.stat = r.stat
+
+			// Filesystem events fire for any change in the directory, so only
+			// return the data if the contents actually changed.
+			sum := sha256.Sum256(data)
+			if d.hash != nil && bytes.Equal(d.hash, sum[:]) {
+				log.Printf("[TRACE] %s: contents unchanged", d)
+				continue
+			}
+			d.hash = sum[:]
+
+			return respWithMetadata(string(data))
+		}
 	}
 }
 
@@ -74,6 +97,15 @@ func (d *FileQuery) CanShare() bool {
 
 // Stop halts the dependency's fetch function.
 func (d *FileQuery) Stop() {
+	d.subLock.Lock()
+	defer d.subLock.Unlock()
+
+	if d.sub != nil {
+		if w, err := getFileWatcher(); err == nil {
+			w.unsubscribe(d.sub)
+		}
+		d.sub = nil
+	}
 	close(d.stopCh)
 }
 
@@ -92,11 +124,16 @@ type watchResult struct {
 	err  error
 }
 
-// watch watchers the file for changes
+// watch watches the file for changes. If filesystem events are available, the
+// file is checked each time its directory changes. Otherwise the file is polled
+// every FileQuerySleepTime.
 func (d *FileQuery) watch(lastStat os.FileInfo) <-chan *watchResult {
 	ch := make(chan *watchResult, 1)
+	sub := d.subscribe()
 
 	go func(lastStat os.FileInfo) {
+		notified := false
+
 		for {
 			stat, err := os.Stat(d.path)
 			if err != nil {
@@ -108,7 +145,11 @@ func (d *FileQuery) watch(lastStat os.FileInfo) <-chan *watchResult {
 				}
 			}
 
-			changed := lastStat == nil ||
+			// An event may not change the size or modification time, for example
+			// when a file is rewritten within the modification time granularity, so
+			// always report a change after an event.
+			changed := notified ||
+				lastStat == nil ||
 				lastStat.Size() != stat.Size() ||
 				lastStat.ModTime() != stat.ModTime()
 
@@ -121,9 +162,65 @@ func (d *FileQuery) watch(lastStat os.FileInfo) <-chan *watchResult {
 				}
 			}
 
-			time.Sleep(FileQuerySleepTime)
+			if sub == nil {
+				select {
+				case <-d.stopCh:
+					return
+				case <-time.After(FileQuerySleepTime):
+				}
+				continue
+			}
+
+			select {
+			case <-d.stopCh:
+				return
+			case <-sub.ch:
+				notified = true
+			case <-sub.invalidCh:
+				log.Printf("[TRACE] %s: directory no longer watched, polling", d)
+				sub = nil
+			}
 		}
 	}(lastStat)
 
 	return ch
 }
+
+// subscribe returns the subscription to filesystem events for the file's
+// directory, creating it if needed. It returns nil if filesystem events are not
+// available, in which case the file must be polled.
+func (d *FileQuery) subscribe() *fileSubscription {
+	d.subLock.Lock()
+	defer d.subLock.Unlock()
+
+	select {
+	case <-d.stopCh:
+		return nil
+	default:
+	}
+
+	if d.sub != nil {
+		select {
+		case <-d.sub.invalidCh:
+			// The directory was removed or the source failed, so try to watch it
+			// again below.
+			d.sub = nil
+		default:
+			return d.sub
+		}
+	}
+
+	w, err := getFileWatcher()
+	if err != nil {
+		return nil
+	}
+
+	sub, err := w.subscribe(filepath.Dir(d.path), make(chan struct{}, 1))
+	if err != nil {
+		log.Printf("[TRACE] %s: failed to watch directory, polling: %s", d, err)
+		return nil
+	}
+
+	d.sub = sub
+	return sub
+}
@@ -0,0 +1,350 @@
+package dependency
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Ensure implements
+	_ Dependency = (*FileGlobQuery)(nil)
+)
+
+func init() {
+	gob.Register([]*FileEntry{})
+}
+
+// FileEntry is a single file returned by a FileGlobQuery.
+type FileEntry struct {
+	// Path is the path to the file on disk.
+	Path string
+
+	// Name is the path to the file relative to the root of a tree, or the base
+	// name of the file for a glob.
+	Name string
+
+	Contents string
+	Mode     os.FileMode
+	ModTime  time.Time
+}
+
+// FileGlobQuery represents a local dependency on all files which match a glob,
+// or all files in a directory tree.
+type FileGlobQuery struct {
+	stopCh chan struct{}
+
+	pattern   string
+	recursive bool
+
+	entries []*FileEntry
+	fetched bool
+
+	// subs are the subscriptions to filesystem events for each directory which
+	// may contain matching files. All subscriptions notify eventCh.
+	subLock sync.Mutex
+	subs    map[string]*fileSubscription
+	eventCh chan struct{}
+	rescan  bool
+}
+
+// NewFileGlobQuery creates a dependency on the files which match the given
+// glob pattern, as understood by filepath.Match.
+func NewFileGlobQuery(s string) (*FileGlobQuery, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("file.glob: invalid format: %q", s)
+	}
+
+	if _, err := filepath.Match(s, ""); err != nil {
+		return nil, fmt.Errorf("file.glob: invalid pattern: %q: %s", s, err)
+	}
+
+	return &FileGlobQuery{
+		stopCh:  make(chan struct{}, 1),
+		pattern: filepath.Clean(s),
+	}, nil
+}
+
+// NewFileTreeQuery creates a dependency on every file in the given directory
+// and all of its subdirectories.
+func NewFileTreeQuery(s string) (*FileGlobQuery, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("file.tree: invalid format: %q", s)
+	}
+
+	return &FileGlobQuery{
+		stopCh:    make(chan struct{}, 1),
+		pattern:   filepath.Clean(s),
+		recursive: true,
+	}, nil
+}
+
+// Fetch retrieves this dependency and returns the result or any errors that
+// occur in the process. The first call returns immediately. Later calls block
+// until a file is added, removed or changed.
+func (d *FileGlobQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interface{}, *ResponseMetadata, error) {
+	log.Printf("[TRACE] %s: READ %s", d, d.pattern)
+
+	for {
+		if d.fetched {
+			if err := d.wait(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		entries, dirs, err := d.scan()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, d.String())
+		}
+		d.watch(dirs)
+
+		if d.fetched && reflect.DeepEqual(entries, d.entries) {
+			log.Printf("[TRACE] %s: no changes", d)
+			continue
+		}
+
+		log.Printf("[TRACE] %s: returned %d results", d, len(entries))
+
+		d.entries = entries
+		d.fetched = true
+		return respWithMetadata(entries)
+	}
+}
+
+// CanShare returns a boolean if this dependency is shareable.
+func (d *FileGlobQuery) CanShare() bool {
+	return false
+}
+
+// Stop halts the dependency's fetch function.
+func (d *FileGlobQuery) Stop() {
+	d.subLock.Lock()
+	defer d.subLock.Unlock()
+
+	if w, err := getFileWatcher(); err == nil {
+		for _, sub := range d.subs {
+			w.unsubscribe(sub)
+		}
+	}
+	d.subs = nil
+	close(d.stopCh)
+}
+
+// String returns the human-friendly version of this dependency.
+func (d *FileGlobQuery) String() string {
+	if d.recursive {
+		return fmt.Sprintf("file.tree(%s)", d.pattern)
+	}
+	return fmt.Sprintf("file.glob(%s)", d.pattern)
+}
+
+// Type returns the type of this dependency.
+func (d *FileGlobQuery) Type() Type {
+	return TypeLocal
+}
+
+// scan returns the matching files, sorted by path, and the directories which
+// must be watched to see new files.
+func (d *FileGlobQuery) scan() ([]*FileEntry, []string, error) {
+	var paths, dirs []string
+
+	if d.recursive {
+		err := filepath.Walk(d.pattern, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// A missing root is the same as an empty tree.
+				if path == d.pattern && os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if info.IsDir() {
+				dirs = append(dirs, path)
+			} else {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		matches, err := filepath.Glob(d.pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		paths = matches
+
+		// Watch every directory which matches the directory part of the pattern.
+		dirs, err = filepath.Glob(filepath.Dir(d.pattern))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	entries := make([]*FileEntry, 0, len(paths))
+	for _, path := range paths {
+		// Follow symlinks, and skip anything which is not a regular file.
+		stat, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		if !stat.Mode().IsRegular() {
+			continue
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := filepath.Base(path)
+		if d.recursive {
+			if rel, err := filepath.Rel(d.pattern, path); err == nil {
+				name = filepath.ToSlash(rel)
+			}
+		}
+
+		entries = append(entries, &FileEntry{
+			Path:     path,
+			Name:     name,
+			Contents: string(contents),
+			Mode:     stat.Mode(),
+			ModTime:  stat.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, dirs, nil
+}
+
+// watch makes sure there is a subscription to filesystem events for each of the
+// given directories, and removes subscriptions which are no longer needed. If
+// a subscription is added, the next call to wait returns immediately, so that
+// any change made before the subscription was added is not missed.
+func (d *FileGlobQuery) watch(dirs []string) {
+	d.subLock.Lock()
+	defer d.subLock.Unlock()
+
+	select {
+	case <-d.stopCh:
+		return
+	default:
+	}
+
+	w, err := getFileWatcher()
+	if err != nil {
+		return
+	}
+
+	if d.subs == nil {
+		d.subs = make(map[string]*fileSubscription)
+		d.eventCh = make(chan struct{}, 1)
+	}
+
+	want := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		want[dir] = struct{}{}
+
+		if sub, ok := d.subs[dir]; ok {
+			select {
+			case <-sub.invalidCh:
+				delete(d.subs, dir)
+			default:
+				continue
+			}
+		}
+
+		sub, err := w.subscribe(dir, d.eventCh)
+		if err != nil {
+			log.Printf("[TRACE] %s: failed to watch %s: %s", d, dir, err)
+			continue
+		}
+		d.subs[dir] = sub
+		d.rescan = true
+	}
+
+	for dir, sub := range d.subs {
+		if _, ok := want[dir]; !ok {
+			w.unsubscribe(sub)
+			delete(d.subs, dir)
+		}
+	}
+}
+
+// wait blocks until any watched directory changes. Files are polled every
+// FileQuerySleepTime if filesystem events are not available, or if they cannot
+// catch every change, such as when the directory part of a glob contains a
+// wildcard or a directory could not be watched.
+func (d *FileGlobQuery) wait() error {
+	d.subLock.Lock()
+	eventCh, rescan := d.eventCh, d.rescan
+	d.rescan = false
+	poll := eventCh == nil || d.polling()
+	d.subLock.Unlock()
+
+	if rescan {
+		return nil
+	}
+
+	var pollCh <-chan time.Time
+	if poll {
+		pollCh = time.After(FileQuerySleepTime)
+	}
+
+	select {
+	case <-d.stopCh:
+		log.Printf("[TRACE] %s: stopped", d)
+		return ErrStopped
+	case <-eventCh:
+		log.Printf("[TRACE] %s: reported change", d)
+	case <-pollCh:
+	}
+	return nil
+}
+
+// polling returns true if the files must be polled because filesystem events
+// may not catch every change. The caller must hold the lock.
+func (d *FileGlobQuery) polling() bool {
+	if !d.recursive && hasGlobMeta(filepath.Dir(d.pattern)) {
+		return true
+	}
+
+	if len(d.subs) == 0 {
+		return true
+	}
+
+	for _, sub := range d.subs {
+		select {
+		case <-sub.invalidCh:
+			return true
+		default:
+		}
+	}
+	return false
+}
+
+// hasGlobMeta returns true if the path contains any of the special characters
+// recognized by filepath.Match.
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
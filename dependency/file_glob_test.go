@@ -0,0 +1,404 @@
+package dependency
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFileGlobQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		exp  *FileGlobQuery
+		err  bool
+	}{
+		{
+			"empty",
+			"",
+			nil,
+			true,
+		},
+		{
+			"bad_pattern",
+			"/etc/[",
+			nil,
+			true,
+		},
+		{
+			"pattern",
+			"/etc/app/conf.d/*.json",
+			&FileGlobQuery{
+				pattern: "/etc/app/conf.d/*.json",
+			},
+			false,
+		},
+		{
+			"cleans",
+			"/etc/app//conf.d/./*.json",
+			&FileGlobQuery{
+				pattern: "/etc/app/conf.d/*.json",
+			},
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := NewFileGlobQuery(tc.i)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				act.stopCh = nil
+			}
+
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+func TestNewFileTreeQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		exp  *FileGlobQuery
+		err  bool
+	}{
+		{
+			"empty",
+			"",
+			nil,
+			true,
+		},
+		{
+			"dir",
+			"/etc/app/",
+			&FileGlobQuery{
+				pattern:   "/etc/app",
+				recursive: true,
+			},
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := NewFileTreeQuery(tc.i)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				act.stopCh = nil
+			}
+
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+// testFileTree creates a temporary directory with the given files, and returns
+// the path to it.
+func testFileTree(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// fileEntryNames returns the name and contents of each entry.
+func fileEntryNames(i interface{}) []string {
+	var result []string
+	for _, e := range i.([]*FileEntry) {
+		result = append(result, e.Name+"="+e.Contents)
+	}
+	return result
+}
+
+func TestFileGlobQuery_Fetch(t *testing.T) {
+	t.Parallel()
+
+	dir := testFileTree(t, map[string]string{
+		"conf.d/b.json":       "b",
+		"conf.d/a.json":       "a",
+		"conf.d/c.txt":        "c",
+		"conf.d/sub/d.json":   "d",
+		"conf.d/sub/e/f.json": "f",
+	})
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name string
+		f    func(string) (*FileGlobQuery, error)
+		i    string
+		exp  []string
+	}{
+		{
+			"glob",
+			NewFileGlobQuery,
+			filepath.Join(dir, "conf.d", "*.json"),
+			[]string{"a.json=a", "b.json=b"},
+		},
+		{
+			"glob_no_matches",
+			NewFileGlobQuery,
+			filepath.Join(dir, "conf.d", "*.yaml"),
+			nil,
+		},
+		{
+			"glob_skips_dirs",
+			NewFileGlobQuery,
+			filepath.Join(dir, "conf.d", "*"),
+			[]string{"a.json=a", "b.json=b", "c.txt=c"},
+		},
+		{
+			"tree",
+			NewFileTreeQuery,
+			filepath.Join(dir, "conf.d"),
+			[]string{"a.json=a", "b.json=b", "c.txt=c", "sub/d.json=d", "sub/e/f.json=f"},
+		},
+		{
+			"tree_missing",
+			NewFileTreeQuery,
+			filepath.Join(dir, "nope"),
+			nil,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			d, err := tc.f(tc.i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.Stop()
+
+			act, _, err := d.Fetch(nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.exp, fileEntryNames(act))
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		d, err := NewFileGlobQuery(filepath.Join(dir, "conf.d", "a.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Stop()
+
+		act, _, err := d.Fetch(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join(dir, "conf.d", "a.json")
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []*FileEntry{
+			&FileEntry{
+				Path:     path,
+				Name:     "a.json",
+				Contents: "a",
+				Mode:     stat.Mode(),
+				ModTime:  stat.ModTime(),
+			},
+		}, act)
+	})
+
+	t.Run("stops", func(t *testing.T) {
+		d, err := NewFileGlobQuery(filepath.Join(dir, "conf.d", "*.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			for {
+				_, _, err := d.Fetch(nil, nil)
+				if err != nil {
+					errCh <- err
+					return
+				}
+			}
+		}()
+
+		d.Stop()
+
+		select {
+		case err := <-errCh:
+			if err != ErrStopped {
+				t.Fatal(err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("did not stop")
+		}
+	})
+}
+
+func TestFileGlobQuery_FetchChanges(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		f      func(string) (*FileGlobQuery, error)
+		i      string
+		change func(dir string) error
+		exp    []string
+	}{
+		{
+			"glob_added",
+			NewFileGlobQuery,
+			"*.json",
+			func(dir string) error {
+				return ioutil.WriteFile(filepath.Join(dir, "b.json"), []byte("b"), 0644)
+			},
+			[]string{"a.json=a", "b.json=b"},
+		},
+		{
+			"glob_removed",
+			NewFileGlobQuery,
+			"*.json",
+			func(dir string) error {
+				return os.Remove(filepath.Join(dir, "a.json"))
+			},
+			nil,
+		},
+		{
+			"glob_changed",
+			NewFileGlobQuery,
+			"*.json",
+			func(dir string) error {
+				return ioutil.WriteFile(filepath.Join(dir, "a.json"), []byte("z"), 0644)
+			},
+			[]string{"a.json=z"},
+		},
+		{
+			"tree_added_subdir",
+			NewFileTreeQuery,
+			"",
+			func(dir string) error {
+				if err := os.MkdirAll(filepath.Join(dir, "x", "y"), 0755); err != nil {
+					return err
+				}
+				return ioutil.WriteFile(filepath.Join(dir, "x", "y", "b.json"), []byte("b"), 0644)
+			},
+			[]string{"a.json=a", "x/y/b.json=b"},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			dir := testFileTree(t, map[string]string{
+				"a.json": "a",
+			})
+			defer os.RemoveAll(dir)
+
+			d, err := tc.f(filepath.Join(dir, tc.i))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dataCh := make(chan interface{}, 1)
+			errCh := make(chan error, 1)
+			go func() {
+				for {
+					data, _, err := d.Fetch(nil, nil)
+					if err != nil {
+						errCh <- err
+						return
+					}
+					dataCh <- data
+				}
+			}()
+			defer d.Stop()
+
+			select {
+			case err := <-errCh:
+				t.Fatal(err)
+			case <-dataCh:
+			}
+
+			if err := tc.change(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			// A change may be seen in several steps, such as a directory being
+			// created before the file in it, so wait for the expected result.
+			timeout := time.After(time.Second)
+			for {
+				select {
+				case err := <-errCh:
+					t.Fatal(err)
+				case data := <-dataCh:
+					if act := fileEntryNames(data); assert.ObjectsAreEqual(tc.exp, act) {
+						return
+					}
+				case <-timeout:
+					t.Fatal("did not fire")
+				}
+			}
+		})
+	}
+}
+
+func TestFileGlobQuery_String(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		f    func(string) (*FileGlobQuery, error)
+		i    string
+		exp  string
+	}{
+		{
+			"glob",
+			NewFileGlobQuery,
+			"/etc/app/conf.d/*.json",
+			"file.glob(/etc/app/conf.d/*.json)",
+		},
+		{
+			"tree",
+			NewFileTreeQuery,
+			"/etc/app",
+			"file.tree(/etc/app)",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			d, err := tc.f(tc.i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.exp, d.String())
+		})
+	}
+}
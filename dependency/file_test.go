@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -186,6 +187,214 @@ func TestFileQuery_Fetch(t *testing.T) {
 	})
 }
 
+func TestFileQuery_events(t *testing.T) {
+	t.Parallel()
+
+	if _, err := getFileWatcher(); err != nil {
+		t.Skipf("filesystem events unavailable: %s", err)
+	}
+
+	// fetch calls Fetch in a loop and sends the results on the returned
+	// channels until the query is stopped.
+	fetch := func(d *FileQuery) (<-chan interface{}, <-chan error) {
+		dataCh := make(chan interface{}, 1)
+		errCh := make(chan error, 1)
+		go func() {
+			for {
+				data, _, err := d.Fetch(nil, nil)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				dataCh <- data
+			}
+		}()
+		return dataCh, errCh
+	}
+
+	t.Run("fires_same_size_changes", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "file")
+		if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := NewFileQuery(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataCh, errCh := fetch(d)
+		defer d.Stop()
+
+		select {
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-dataCh:
+		}
+
+		// Keep the same size and modification time, which polling cannot detect.
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("world"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, stat.ModTime(), stat.ModTime()); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-errCh:
+			t.Fatal(err)
+		case data := <-dataCh:
+			assert.Equal(t, "world", data)
+		case <-time.After(time.Second):
+			t.Errorf("did not fire")
+		}
+	})
+
+	t.Run("fires_renames", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "file")
+		if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := NewFileQuery(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataCh, errCh := fetch(d)
+		defer d.Stop()
+
+		select {
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-dataCh:
+		}
+
+		tmp := filepath.Join(dir, "file.tmp")
+		if err := ioutil.WriteFile(tmp, []byte("goodbye"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-errCh:
+			t.Fatal(err)
+		case data := <-dataCh:
+			assert.Equal(t, "goodbye", data)
+		case <-time.After(time.Second):
+			t.Errorf("did not fire")
+		}
+	})
+
+	t.Run("ignores_unchanged_contents", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "file")
+		if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := NewFileQuery(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataCh, errCh := fetch(d)
+		defer d.Stop()
+
+		select {
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-dataCh:
+		}
+
+		if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "other"), []byte("other"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-errCh:
+			t.Fatal(err)
+		case data := <-dataCh:
+			t.Errorf("expected no change, got %q", data)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("shares_watcher", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		queries := make([]*FileQuery, 0, 20)
+		for i := 0; i < 20; i++ {
+			path := filepath.Join(dir, fmt.Sprintf("file%d", i))
+			if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			d, err := NewFileQuery(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			queries = append(queries, d)
+		}
+
+		subs := make(map[*watchedDir]struct{})
+		for _, d := range queries {
+			sub := d.subscribe()
+			if sub == nil {
+				t.Fatal("expected subscription")
+			}
+
+			w, _ := getFileWatcher()
+			w.Lock()
+			subs[w.dirs[filepath.Clean(dir)]] = struct{}{}
+			w.Unlock()
+		}
+
+		if len(subs) != 1 {
+			t.Errorf("expected %d to be %d", len(subs), 1)
+		}
+
+		for _, d := range queries {
+			d.Stop()
+		}
+
+		w, _ := getFileWatcher()
+		w.Lock()
+		_, ok := w.dirs[filepath.Clean(dir)]
+		w.Unlock()
+		if ok {
+			t.Errorf("expected directory to no longer be watched")
+		}
+	})
+}
+
 func TestFileQuery_String(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,225 @@
+package dependency
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// sharedFileWatcher is the watcher shared by every FileQuery in the process.
+	// It is created on first use.
+	sharedFileWatcher     *fileWatcher
+	sharedFileWatcherErr  error
+	sharedFileWatcherOnce sync.Once
+)
+
+// fsEventSource is a source of filesystem events, such as inotify. The source
+// reports events to the fileWatcher which created it by calling notify,
+// notifyAll and invalidate.
+type fsEventSource interface {
+	// addWatch starts watching the given directory and returns an identifier
+	// for the watch. Adding the same directory twice returns the same
+	// identifier.
+	addWatch(dir string) (int, error)
+
+	// removeWatch stops watching the directory with the given identifier.
+	removeWatch(wd int) error
+}
+
+// fileWatcher shares a single filesystem event source between any number of
+// subscribers. Each directory is watched once, no matter how many files in it
+// are being watched. Directories are watched instead of files so that files
+// which are replaced by an atomic rename are still caught.
+type fileWatcher struct {
+	sync.Mutex
+
+	source fsEventSource
+	dirs   map[string]*watchedDir
+	wds    map[int]*watchedDir
+}
+
+// watchedDir is a single watched directory and its subscribers.
+type watchedDir struct {
+	wd    int
+	paths []string
+	subs  map[*fileSubscription]struct{}
+}
+
+// fileSubscription receives a notification on ch when anything in the watched
+// directory changes. Notifications are sent without blocking, so ch should be
+// buffered and a single receive may stand for many events. The same channel may
+// be used for several subscriptions. invalidCh is closed when the directory is
+// no longer being watched, for example because it was removed.
+type fileSubscription struct {
+	dir       string
+	ch        chan struct{}
+	invalidCh chan struct{}
+}
+
+// getFileWatcher returns the watcher shared by every FileQuery, creating it if
+// needed. It returns an error if filesystem events are not available on this
+// platform.
+func getFileWatcher() (*fileWatcher, error) {
+	sharedFileWatcherOnce.Do(func() {
+		w := newFileWatcher()
+		source, err := newFSEventSource(w)
+		if err != nil {
+			log.Printf("[WARN] (file) filesystem events unavailable, polling instead: %s", err)
+			sharedFileWatcherErr = err
+			return
+		}
+		w.source = source
+		sharedFileWatcher = w
+	})
+	return sharedFileWatcher, sharedFileWatcherErr
+}
+
+// newFileWatcher creates a watcher with no source. The caller is responsible
+// for setting the source.
+func newFileWatcher() *fileWatcher {
+	return &fileWatcher{
+		dirs: make(map[string]*watchedDir),
+		wds:  make(map[int]*watchedDir),
+	}
+}
+
+// subscribe starts watching the given directory, if it is not already being
+// watched, and returns a new subscription which notifies the given channel.
+func (w *fileWatcher) subscribe(dir string, ch chan struct{}) (*fileSubscription, error) {
+	dir = filepath.Clean(dir)
+
+	w.Lock()
+	defer w.Unlock()
+
+	d, ok := w.dirs[dir]
+	if !ok {
+		wd, err := w.source.addWatch(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		// The same directory may be reached through different paths, such as a
+		// symlink, in which case the source returns the existing watch.
+		if d, ok = w.wds[wd]; ok {
+			d.paths = append(d.paths, dir)
+		} else {
+			d = &watchedDir{
+				wd:    wd,
+				paths: []string{dir},
+				subs:  make(map[*fileSubscription]struct{}),
+			}
+			w.wds[wd] = d
+		}
+		w.dirs[dir] = d
+		log.Printf("[TRACE] (file) watching %s", dir)
+	}
+
+	sub := &fileSubscription{
+		dir:       dir,
+		ch:        ch,
+		invalidCh: make(chan struct{}),
+	}
+	d.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// unsubscribe removes the subscription, and stops watching its directory if
+// there are no subscribers left.
+func (w *fileWatcher) unsubscribe(sub *fileSubscription) {
+	w.Lock()
+	defer w.Unlock()
+
+	d, ok := w.dirs[sub.dir]
+	if !ok {
+		return
+	}
+	if _, ok := d.subs[sub]; !ok {
+		return
+	}
+
+	delete(d.subs, sub)
+	if len(d.subs) > 0 {
+		return
+	}
+
+	w.remove(d)
+	if err := w.source.removeWatch(d.wd); err != nil {
+		log.Printf("[DEBUG] (file) failed to stop watching %s: %s", sub.dir, err)
+	}
+}
+
+// watching returns the number of directories being watched.
+func (w *fileWatcher) watching() int {
+	w.Lock()
+	defer w.Unlock()
+	return len(w.wds)
+}
+
+// notify notifies every subscriber of the directory with the given identifier.
+func (w *fileWatcher) notify(wd int) {
+	w.Lock()
+	defer w.Unlock()
+
+	if d, ok := w.wds[wd]; ok {
+		d.notify()
+	}
+}
+
+// notifyAll notifies every subscriber of every directory. This is used when
+// events may have been lost.
+func (w *fileWatcher) notifyAll() {
+	w.Lock()
+	defer w.Unlock()
+
+	for _, d := range w.wds {
+		d.notify()
+	}
+}
+
+// invalidate stops tracking the directory with the given identifier, because
+// the source is no longer watching it. Subscribers fall back to polling.
+func (w *fileWatcher) invalidate(wd int) {
+	w.Lock()
+	defer w.Unlock()
+
+	if d, ok := w.wds[wd]; ok {
+		w.remove(d)
+		for sub := range d.subs {
+			close(sub.invalidCh)
+		}
+	}
+}
+
+// invalidateAll stops tracking every directory. This is used when the source
+// fails.
+func (w *fileWatcher) invalidateAll() {
+	w.Lock()
+	defer w.Unlock()
+
+	for _, d := range w.wds {
+		w.remove(d)
+		for sub := range d.subs {
+			close(sub.invalidCh)
+		}
+	}
+}
+
+// remove deletes the directory from the watcher's maps. The caller must hold
+// the lock.
+func (w *fileWatcher) remove(d *watchedDir) {
+	delete(w.wds, d.wd)
+	for _, p := range d.paths {
+		delete(w.dirs, p)
+	}
+}
+
+// notify sends a notification to every subscriber without blocking.
+func (d *watchedDir) notify() {
+	for sub := range d.subs {
+		select {
+		case sub.ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// +build linux
+
+package dependency
+
+import (
+	"bytes"
+	"log"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask is the set of events which trigger a notification. Changes to any
+// file in a directory are reported, so that files which are replaced by a
+// rename or through a symlink are caught. Writes are reported when the file is
+// closed rather than on each modification, so that a partially written file is
+// not read.
+const inotifyMask = syscall.IN_ATTRIB |
+	syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE |
+	syscall.IN_DELETE |
+	syscall.IN_DELETE_SELF |
+	syscall.IN_MOVE_SELF |
+	syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO
+
+// inotifySource is a fsEventSource backed by a single inotify instance.
+type inotifySource struct {
+	fd int
+}
+
+// newFSEventSource creates an inotify instance and starts reading events from it
+// for the lifetime of the process.
+func newFSEventSource(w *fileWatcher) (fsEventSource, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &inotifySource{fd: fd}
+	go s.run(w)
+	return s, nil
+}
+
+func (s *inotifySource) addWatch(dir string) (int, error) {
+	return syscall.InotifyAddWatch(s.fd, dir, inotifyMask)
+}
+
+func (s *inotifySource) removeWatch(wd int) error {
+	_, err := syscall.InotifyRmWatch(s.fd, uint32(wd))
+	return err
+}
+
+// run reads events and dispatches them to the watcher until reading fails.
+func (s *inotifySource) run(w *fileWatcher) {
+	var buf [(syscall.SizeofInotifyEvent + syscall.NAME_MAX + 1) * 64]byte
+
+	for {
+		n, err := syscall.Read(s.fd, buf[:])
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil || n < syscall.SizeofInotifyEvent {
+			log.Printf("[ERR] (file) failed to read filesystem events, polling instead: %v", err)
+			w.invalidateAll()
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			name := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			switch {
+			case event.Mask&syscall.IN_Q_OVERFLOW != 0:
+				log.Printf("[DEBUG] (file) filesystem event queue overflowed")
+				w.notifyAll()
+			case event.Mask&syscall.IN_IGNORED != 0:
+				w.invalidate(int(event.Wd))
+			default:
+				log.Printf("[TRACE] (file) event 0x%x on %q", event.Mask, bytes.TrimRight(name, "\x00"))
+				w.notify(int(event.Wd))
+			}
+		}
+	}
+}
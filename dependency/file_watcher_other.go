@@ -0,0 +1,11 @@
+// +build !linux
+
+package dependency
+
+import "errors"
+
+// newFSEventSource returns an error, since filesystem events are only supported
+// on Linux. Files are polled instead.
+func newFSEventSource(w *fileWatcher) (fsEventSource, error) {
+	return nil, errors.New("not supported on this platform")
+}
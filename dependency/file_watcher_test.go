@@ -0,0 +1,146 @@
+package dependency
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testFSEventSource is a fsEventSource which records the watched directories.
+type testFSEventSource struct {
+	next    int
+	watches map[string]int
+}
+
+func (s *testFSEventSource) addWatch(dir string) (int, error) {
+	if wd, ok := s.watches[dir]; ok {
+		return wd, nil
+	}
+	s.next++
+	s.watches[dir] = s.next
+	return s.next, nil
+}
+
+func (s *testFSEventSource) removeWatch(wd int) error {
+	for dir, id := range s.watches {
+		if id == wd {
+			delete(s.watches, dir)
+			return nil
+		}
+	}
+	return fmt.Errorf("no watch %d", wd)
+}
+
+func testFileWatcher() (*fileWatcher, *testFSEventSource) {
+	source := &testFSEventSource{watches: make(map[string]int)}
+	w := newFileWatcher()
+	w.source = source
+	return w, source
+}
+
+func TestFileWatcher_shared(t *testing.T) {
+	t.Parallel()
+
+	w, source := testFileWatcher()
+
+	subs := make([]*fileSubscription, 0, 10)
+	for i := 0; i < 10; i++ {
+		sub, err := w.subscribe("/etc/app/", make(chan struct{}, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	other, err := w.subscribe("/etc/other", make(chan struct{}, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, act := 2, w.watching(); act != exp {
+		t.Errorf("expected %d to be %d", act, exp)
+	}
+
+	w.notify(source.watches["/etc/app"])
+
+	for i, sub := range subs {
+		select {
+		case <-sub.ch:
+		default:
+			t.Errorf("expected subscription %d to be notified", i)
+		}
+	}
+
+	select {
+	case <-other.ch:
+		t.Errorf("expected other subscription not to be notified")
+	default:
+	}
+
+	for _, sub := range subs {
+		w.unsubscribe(sub)
+	}
+
+	if _, ok := source.watches["/etc/app"]; ok {
+		t.Errorf("expected /etc/app to no longer be watched")
+	}
+	if exp, act := 1, w.watching(); act != exp {
+		t.Errorf("expected %d to be %d", act, exp)
+	}
+}
+
+func TestFileWatcher_coalesces(t *testing.T) {
+	t.Parallel()
+
+	w, source := testFileWatcher()
+
+	sub, err := w.subscribe("/etc/app", make(chan struct{}, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		w.notify(source.watches["/etc/app"])
+	}
+
+	<-sub.ch
+	select {
+	case <-sub.ch:
+		t.Errorf("expected notifications to be coalesced")
+	default:
+	}
+}
+
+func TestFileWatcher_invalidate(t *testing.T) {
+	t.Parallel()
+
+	w, source := testFileWatcher()
+
+	sub, err := w.subscribe("/etc/app", make(chan struct{}, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.invalidate(source.watches["/etc/app"])
+
+	select {
+	case <-sub.invalidCh:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("expected subscription to be invalidated")
+	}
+
+	if exp, act := 0, w.watching(); act != exp {
+		t.Errorf("expected %d to be %d", act, exp)
+	}
+
+	// Unsubscribing after invalidation is a no-op.
+	w.unsubscribe(sub)
+
+	// Subscribing again watches the directory again.
+	if _, err := w.subscribe("/etc/app", make(chan struct{}, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := 1, w.watching(); act != exp {
+		t.Errorf("expected %d to be %d", act, exp)
+	}
+}
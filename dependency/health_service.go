@@ -56,15 +56,19 @@ type HealthService struct {
 type HealthServiceQuery struct {
 	stopCh chan struct{}
 
-	dc      string
-	filters []string
-	name    string
-	near    string
-	tag     string
+	dc          string
+	excludeTags []string
+	filters     []string
+	name        string
+	near        string
+	nodeMeta    map[string]string
+	tags        []string
 }
 
 // NewHealthServiceQuery processes the strings to build a service dependency.
-func NewHealthServiceQuery(s string) (*HealthServiceQuery, error) {
+// Optional "key=value" arguments, such as "node-meta=rack:r1", "tag=v2" or
+// "exclude-tag=canary", further filter the services which are returned.
+func NewHealthServiceQuery(s string, opts ...string) (*HealthServiceQuery, error) {
 	if !HealthServiceQueryRe.MatchString(s) {
 		return nil, fmt.Errorf("health.service: invalid format: %q", s)
 	}
@@ -93,13 +97,20 @@ func NewHealthServiceQuery(s string) (*HealthServiceQuery, error) {
 		filters = []string{HealthPassing}
 	}
 
+	qf, err := parseQueryFilters(m["tag"], opts)
+	if err != nil {
+		return nil, fmt.Errorf("health.service: %s in %q", err, s)
+	}
+
 	return &HealthServiceQuery{
-		stopCh:  make(chan struct{}, 1),
-		dc:      m["dc"],
-		filters: filters,
-		name:    m["name"],
-		near:    m["near"],
-		tag:     m["tag"],
+		stopCh:      make(chan struct{}, 1),
+		dc:          m["dc"],
+		excludeTags: qf.excludeTags,
+		filters:     filters,
+		name:        m["name"],
+		near:        m["near"],
+		nodeMeta:    qf.nodeMeta,
+		tags:        qf.tags,
 	}, nil
 }
 
@@ -115,15 +126,20 @@ func (d *HealthServiceQuery) Fetch(clients *ClientSet, opts *QueryOptions) (inte
 	opts = opts.Merge(&QueryOptions{
 		Datacenter: d.dc,
 		Near:       d.near,
+		NodeMeta:   d.nodeMeta,
 	})
 
+	// Consul only filters by a single tag, so any other required or excluded
+	// tags are filtered client-side.
+	tag := serverTag(d.tags)
+
 	u := &url.URL{
 		Path:     "/v1/health/service/" + d.name,
 		RawQuery: opts.String(),
 	}
-	if d.tag != "" {
+	if tag != "" {
 		q := u.Query()
-		q.Set("tag", d.tag)
+		q.Set("tag", tag)
 		u.RawQuery = q.Encode()
 	}
 	log.Printf("[TRACE] %s: GET %s", d, u)
@@ -132,7 +148,7 @@ func (d *HealthServiceQuery) Fetch(clients *ClientSet, opts *QueryOptions) (inte
 	// more than healthy services, so we need to implement client-side filtering.
 	passingOnly := len(d.filters) == 1 && d.filters[0] == HealthPassing
 
-	entries, qm, err := clients.Consul().Health().Service(d.name, d.tag, passingOnly, opts.ToConsulOpts())
+	entries, qm, err := clients.Consul().Health().Service(d.name, tag, passingOnly, opts.ToConsulOpts())
 	if err != nil {
 		return nil, nil, errors.Wrap(err, d.String())
 	}
@@ -150,26 +166,11 @@ func (d *HealthServiceQuery) Fetch(clients *ClientSet, opts *QueryOptions) (inte
 			continue
 		}
 
-		// Get the address of the service, falling back to the address of the node.
-		address := entry.Service.Address
-		if address == "" {
-			address = entry.Node.Address
+		if !acceptTags(entry.Service.Tags, d.tags, d.excludeTags) {
+			continue
 		}
 
-		list = append(list, &HealthService{
-			Node:                entry.Node.Node,
-			NodeID:              entry.Node.ID,
-			NodeAddress:         entry.Node.Address,
-			NodeTaggedAddresses: entry.Node.TaggedAddresses,
-			NodeMeta:            entry.Node.Meta,
-			Address:             address,
-			ID:                  entry.Service.ID,
-			Name:                entry.Service.Service,
-			Tags:                ServiceTags(deepCopyAndSortTags(entry.Service.Tags)),
-			Status:              status,
-			Checks:              entry.Checks,
-			Port:                entry.Service.Port,
-		})
+		list = append(list, newHealthService(entry, status))
 	}
 
 	log.Printf("[TRACE] %s: returned %d results after filtering", d, len(list))
@@ -196,10 +197,8 @@ func (d *HealthServiceQuery) Stop() {
 
 // String returns the human-friendly version of this dependency.
 func (d *HealthServiceQuery) String() string {
-	name := d.name
-	if d.tag != "" {
-		name = d.tag + "." + name
-	}
+	prefix, suffix := tagsString(d.tags, d.excludeTags)
+	name := prefix + d.name
 	if d.dc != "" {
 		name = name + "@" + d.dc
 	}
@@ -209,6 +208,10 @@ func (d *HealthServiceQuery) String() string {
 	if len(d.filters) > 0 {
 		name = name + "|" + strings.Join(d.filters, ",")
 	}
+	name = name + suffix
+	if len(d.nodeMeta) > 0 {
+		name = name + "|" + nodeMetaString(d.nodeMeta)
+	}
 	return fmt.Sprintf("health.service(%s)", name)
 }
 
@@ -217,6 +220,31 @@ func (d *HealthServiceQuery) Type() Type {
 	return TypeConsul
 }
 
+// newHealthService converts the service entry into a HealthService with the
+// given aggregated status.
+func newHealthService(entry *api.ServiceEntry, status string) *HealthService {
+	// Get the address of the service, falling back to the address of the node.
+	address := entry.Service.Address
+	if address == "" {
+		address = entry.Node.Address
+	}
+
+	return &HealthService{
+		Node:                entry.Node.Node,
+		NodeID:              entry.Node.ID,
+		NodeAddress:         entry.Node.Address,
+		NodeTaggedAddresses: entry.Node.TaggedAddresses,
+		NodeMeta:            entry.Node.Meta,
+		Address:             address,
+		ID:                  entry.Service.ID,
+		Name:                entry.Service.Service,
+		Tags:                ServiceTags(deepCopyAndSortTags(entry.Service.Tags)),
+		Status:              status,
+		Checks:              entry.Checks,
+		Port:                entry.Service.Port,
+	}
+}
+
 // acceptStatus allows us to check if a slice of health checks pass this filter.
 func acceptStatus(list []string, s string) bool {
 	for _, status := range list {
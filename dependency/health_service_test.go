@@ -86,7 +86,7 @@ func TestNewHealthServiceQuery(t *testing.T) {
 			&HealthServiceQuery{
 				filters: []string{"passing"},
 				name:    "name",
-				tag:     "tag",
+				tags:    []string{"tag"},
 			},
 			false,
 		},
@@ -97,7 +97,7 @@ func TestNewHealthServiceQuery(t *testing.T) {
 				dc:      "dc",
 				filters: []string{"passing"},
 				name:    "name",
-				tag:     "tag",
+				tags:    []string{"tag"},
 			},
 			false,
 		},
@@ -108,7 +108,7 @@ func TestNewHealthServiceQuery(t *testing.T) {
 				filters: []string{"passing"},
 				name:    "name",
 				near:    "near",
-				tag:     "tag",
+				tags:    []string{"tag"},
 			},
 			false,
 		},
@@ -120,7 +120,7 @@ func TestNewHealthServiceQuery(t *testing.T) {
 				filters: []string{"passing"},
 				name:    "name",
 				near:    "near",
-				tag:     "tag",
+				tags:    []string{"tag"},
 			},
 			false,
 		},
@@ -142,6 +142,129 @@ func TestNewHealthServiceQuery(t *testing.T) {
 	}
 }
 
+func TestNewHealthServiceQuery_options(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		opts []string
+		exp  *HealthServiceQuery
+		err  bool
+	}{
+		{
+			"node_meta",
+			"name",
+			[]string{"node-meta=rack:r1"},
+			&HealthServiceQuery{
+				filters:  []string{"passing"},
+				name:     "name",
+				nodeMeta: map[string]string{"rack": "r1"},
+			},
+			false,
+		},
+		{
+			"node_meta_multi",
+			"name|any",
+			[]string{"node-meta=rack:r1", "node-meta=zone:a"},
+			&HealthServiceQuery{
+				filters:  []string{"any"},
+				name:     "name",
+				nodeMeta: map[string]string{"rack": "r1", "zone": "a"},
+			},
+			false,
+		},
+		{
+			"tag",
+			"name@dc1",
+			[]string{"tag=v2"},
+			&HealthServiceQuery{
+				dc:      "dc1",
+				filters: []string{"passing"},
+				name:    "name",
+				tags:    []string{"v2"},
+			},
+			false,
+		},
+		{
+			"tag_same_as_prefix",
+			"v2.name",
+			[]string{"tag=v2"},
+			&HealthServiceQuery{
+				filters: []string{"passing"},
+				name:    "name",
+				tags:    []string{"v2"},
+			},
+			false,
+		},
+		{
+			"tags_multi",
+			"v2.name",
+			[]string{"tag=primary", "tag=v2"},
+			&HealthServiceQuery{
+				filters: []string{"passing"},
+				name:    "name",
+				tags:    []string{"primary", "v2"},
+			},
+			false,
+		},
+		{
+			"exclude_tags",
+			"name",
+			[]string{"exclude-tag=canary", "exclude-tag=beta", "exclude-tag=canary"},
+			&HealthServiceQuery{
+				excludeTags: []string{"beta", "canary"},
+				filters:     []string{"passing"},
+				name:        "name",
+			},
+			false,
+		},
+		{
+			"required_and_excluded",
+			"canary.name",
+			[]string{"exclude-tag=canary"},
+			nil,
+			true,
+		},
+		{
+			"node_meta_no_value",
+			"name",
+			[]string{"node-meta=rack"},
+			nil,
+			true,
+		},
+		{
+			"node_meta_duplicate",
+			"name",
+			[]string{"node-meta=rack:r1", "node-meta=rack:r2"},
+			nil,
+			true,
+		},
+		{
+			"unknown",
+			"name",
+			[]string{"nope=yes"},
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := NewHealthServiceQuery(tc.i, tc.opts...)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				act.stopCh = nil
+			}
+
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
 func TestHealthServiceQuery_Fetch(t *testing.T) {
 	t.Parallel()
 
@@ -221,6 +344,20 @@ func TestHealthServiceQuery_Fetch(t *testing.T) {
 			assert.Equal(t, tc.exp, act)
 		})
 	}
+
+	t.Run("node_meta", func(t *testing.T) {
+		d, err := NewHealthServiceQuery("consul", "node-meta=rack:nope")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		act, _, err := d.Fetch(testClients, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []*HealthService{}, act)
+	})
 }
 
 func TestHealthServiceQuery_String(t *testing.T) {
@@ -303,3 +440,61 @@ func TestHealthServiceQuery_String(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthServiceQuery_StringOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		opts []string
+		exp  string
+	}{
+		{
+			"node_meta",
+			"name",
+			[]string{"node-meta=rack:r1"},
+			"health.service(name|passing|node-meta=rack:r1)",
+		},
+		{
+			"node_meta_sorted",
+			"name@dc",
+			[]string{"node-meta=zone:a", "node-meta=rack:r1"},
+			"health.service(name@dc|passing|node-meta=rack:r1,zone:a)",
+		},
+		{
+			"tag",
+			"name",
+			[]string{"tag=v2"},
+			"health.service(v2.name|passing)",
+		},
+		{
+			"tags_exclude_tags",
+			"v2.name",
+			[]string{"exclude-tag=canary", "tag=primary"},
+			"health.service(primary,v2.name|passing|exclude-tag=canary)",
+		},
+		{
+			"tags_canonical",
+			"primary.name",
+			[]string{"tag=v2", "exclude-tag=canary"},
+			"health.service(primary,v2.name|passing|exclude-tag=canary)",
+		},
+		{
+			"exclude_tags_node_meta",
+			"name@dc",
+			[]string{"node-meta=rack:r1", "exclude-tag=canary", "exclude-tag=beta"},
+			"health.service(name@dc|passing|exclude-tag=beta,canary|node-meta=rack:r1)",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			d, err := NewHealthServiceQuery(tc.i, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.exp, d.String())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package dependency
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// Ensure implements
+	_ Dependency = (*PreparedQueryQuery)(nil)
+
+	// PreparedQueryQueryRe is the regular expression to use.
+	PreparedQueryQueryRe = regexp.MustCompile(`\A` + nameRe + dcRe + nearRe + `(\|(?P<poll>[[:word:]\.]+))?` + `\z`)
+
+	// PreparedQueryQuerySleepTime is the default amount of time to sleep between
+	// queries, since the endpoint does not support blocking queries.
+	PreparedQueryQuerySleepTime = 15 * time.Second
+)
+
+// PreparedQueryQuery is the representation of a prepared query execution in
+// Consul.
+type PreparedQueryQuery struct {
+	stopCh chan struct{}
+
+	dc   string
+	name string
+	near string
+	poll time.Duration
+}
+
+// NewPreparedQueryQuery processes the strings to build a prepared query
+// dependency. The optional poll interval is given after a pipe, such as
+// "name@dc~near|30s".
+func NewPreparedQueryQuery(s string) (*PreparedQueryQuery, error) {
+	if !PreparedQueryQueryRe.MatchString(s) {
+		return nil, fmt.Errorf("prepared_query: invalid format: %q", s)
+	}
+
+	m := regexpMatch(PreparedQueryQueryRe, s)
+
+	var poll time.Duration
+	if raw := m["poll"]; raw != "" {
+		var err error
+		poll, err = time.ParseDuration(raw)
+		if err != nil || poll <= 0 {
+			return nil, fmt.Errorf("prepared_query: invalid poll interval: %q in %q", raw, s)
+		}
+	}
+
+	return &PreparedQueryQuery{
+		stopCh: make(chan struct{}, 1),
+		dc:     m["dc"],
+		name:   m["name"],
+		near:   m["near"],
+		poll:   poll,
+	}, nil
+}
+
+// Fetch queries the Consul API defined by the given client and returns a slice
+// of HealthService objects in the order returned by the prepared query.
+func (d *PreparedQueryQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interface{}, *ResponseMetadata, error) {
+	select {
+	case <-d.stopCh:
+		return nil, nil, ErrStopped
+	default:
+	}
+
+	opts = opts.Merge(&QueryOptions{
+		Datacenter: d.dc,
+		Near:       d.near,
+	})
+
+	// Prepared queries do not support blocking queries, so poll in the same way
+	// as the datacenters query. The first query returns immediately, and later
+	// queries sleep for the poll interval before asking Consul again.
+	if opts.WaitIndex != 0 {
+		sleep := d.pollInterval()
+		log.Printf("[TRACE] %s: long polling for %s", d, sleep)
+
+		select {
+		case <-d.stopCh:
+			return nil, nil, ErrStopped
+		case <-time.After(sleep):
+		}
+	}
+
+	// Do not send the index to Consul, since it is not meaningful here.
+	opts.WaitIndex = 0
+	opts.WaitTime = 0
+
+	log.Printf("[TRACE] %s: GET %s", d, &url.URL{
+		Path:     "/v1/query/" + d.name + "/execute",
+		RawQuery: opts.String(),
+	})
+
+	resp, _, err := clients.Consul().PreparedQuery().Execute(d.name, opts.ToConsulOpts())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, d.String())
+	}
+
+	log.Printf("[TRACE] %s: returned %d results from %s (%d failovers)",
+		d, len(resp.Nodes), resp.Datacenter, resp.Failovers)
+
+	list := make([]*HealthService, 0, len(resp.Nodes))
+	for i := range resp.Nodes {
+		entry := &resp.Nodes[i]
+		list = append(list, newHealthService(entry, entry.Checks.AggregatedStatus()))
+	}
+
+	return respWithMetadata(list)
+}
+
+// CanShare returns a boolean if this dependency is shareable.
+func (d *PreparedQueryQuery) CanShare() bool {
+	return true
+}
+
+// Stop halts the dependency's fetch function.
+func (d *PreparedQueryQuery) Stop() {
+	close(d.stopCh)
+}
+
+// String returns the human-friendly version of this dependency.
+func (d *PreparedQueryQuery) String() string {
+	name := d.name
+	if d.dc != "" {
+		name = name + "@" + d.dc
+	}
+	if d.near != "" {
+		name = name + "~" + d.near
+	}
+	if d.poll != 0 {
+		name = name + "|" + d.poll.String()
+	}
+	return fmt.Sprintf("prepared_query(%s)", name)
+}
+
+// Type returns the type of this dependency.
+func (d *PreparedQueryQuery) Type() Type {
+	return TypeConsul
+}
+
+// pollInterval returns the amount of time to sleep between queries.
+func (d *PreparedQueryQuery) pollInterval() time.Duration {
+	if d.poll != 0 {
+		return d.poll
+	}
+	return PreparedQueryQuerySleepTime
+}
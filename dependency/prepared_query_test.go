@@ -0,0 +1,256 @@
+package dependency
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	PreparedQueryQuerySleepTime = 50 * time.Millisecond
+}
+
+func TestNewPreparedQueryQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		exp  *PreparedQueryQuery
+		err  bool
+	}{
+		{
+			"empty",
+			"",
+			nil,
+			true,
+		},
+		{
+			"dc_only",
+			"@dc1",
+			nil,
+			true,
+		},
+		{
+			"name",
+			"name",
+			&PreparedQueryQuery{
+				name: "name",
+			},
+			false,
+		},
+		{
+			"name_dc",
+			"name@dc1",
+			&PreparedQueryQuery{
+				dc:   "dc1",
+				name: "name",
+			},
+			false,
+		},
+		{
+			"name_dc_near",
+			"name@dc1~near",
+			&PreparedQueryQuery{
+				dc:   "dc1",
+				name: "name",
+				near: "near",
+			},
+			false,
+		},
+		{
+			"name_poll",
+			"name|30s",
+			&PreparedQueryQuery{
+				name: "name",
+				poll: 30 * time.Second,
+			},
+			false,
+		},
+		{
+			"name_dc_near_poll",
+			"name@dc1~near|1m30s",
+			&PreparedQueryQuery{
+				dc:   "dc1",
+				name: "name",
+				near: "near",
+				poll: 90 * time.Second,
+			},
+			false,
+		},
+		{
+			"invalid_poll",
+			"name|nope",
+			nil,
+			true,
+		},
+		{
+			"zero_poll",
+			"name|0s",
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := NewPreparedQueryQuery(tc.i)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				act.stopCh = nil
+			}
+
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+func TestPreparedQueryQuery_Fetch(t *testing.T) {
+	t.Parallel()
+
+	id, _, err := testClients.Consul().PreparedQuery().Create(&api.PreparedQueryDefinition{
+		Name: "consul-failover",
+		Service: api.ServiceQuery{
+			Service: "consul",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testClients.Consul().PreparedQuery().Delete(id, nil)
+
+	exp := []*HealthService{
+		&HealthService{
+			Node:        testConsul.Config.NodeName,
+			NodeAddress: testConsul.Config.Bind,
+			NodeTaggedAddresses: map[string]string{
+				"lan": "127.0.0.1",
+				"wan": "127.0.0.1",
+			},
+			NodeMeta: map[string]string{},
+			Address:  testConsul.Config.Bind,
+			ID:       "consul",
+			Name:     "consul",
+			Tags:     []string{},
+			Status:   "passing",
+			Port:     testConsul.Config.Ports.Server,
+		},
+	}
+
+	cases := []struct {
+		name string
+		i    string
+	}{
+		{
+			"name",
+			"consul-failover",
+		},
+		{
+			"id",
+			id,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			d, err := NewPreparedQueryQuery(tc.i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			act, _, err := d.Fetch(testClients, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if act != nil {
+				for _, v := range act.([]*HealthService) {
+					v.NodeID = ""
+					v.Checks = nil
+				}
+			}
+
+			assert.Equal(t, exp, act)
+		})
+	}
+
+	t.Run("stops", func(t *testing.T) {
+		d, err := NewPreparedQueryQuery("consul-failover|1h")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dataCh := make(chan interface{}, 1)
+		errCh := make(chan error, 1)
+		go func() {
+			for {
+				data, _, err := d.Fetch(testClients, &QueryOptions{WaitIndex: 10})
+				if err != nil {
+					errCh <- err
+					return
+				}
+				dataCh <- data
+			}
+		}()
+
+		d.Stop()
+
+		select {
+		case err := <-errCh:
+			if err != ErrStopped {
+				t.Fatal(err)
+			}
+		case <-dataCh:
+			t.Errorf("expected stop")
+		case <-time.After(250 * time.Millisecond):
+			t.Errorf("did not stop")
+		}
+	})
+}
+
+func TestPreparedQueryQuery_String(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		i    string
+		exp  string
+	}{
+		{
+			"name",
+			"name",
+			"prepared_query(name)",
+		},
+		{
+			"name_dc",
+			"name@dc",
+			"prepared_query(name@dc)",
+		},
+		{
+			"name_dc_near",
+			"name@dc~near",
+			"prepared_query(name@dc~near)",
+		},
+		{
+			"name_poll",
+			"name|90s",
+			"prepared_query(name|1m30s)",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			d, err := NewPreparedQueryQuery(tc.i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.exp, d.String())
+		})
+	}
+}
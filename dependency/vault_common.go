@@ -1,8 +1,11 @@
 package dependency
 
 import (
+	"fmt"
 	"log"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/api"
@@ -39,6 +42,20 @@ type Secret struct {
 	// cubbyhole of the given token (which has a TTL of the given number of
 	// seconds)
 	WrapInfo *SecretWrapInfo
+
+	// Metadata, if non-nil, means that the secret was read from a KV version 2
+	// secrets engine. Data then holds the secret itself and Metadata holds the
+	// version information.
+	Metadata *SecretMetadata
+}
+
+// SecretMetadata is the version information of a secret stored in a KV
+// version 2 secrets engine.
+type SecretMetadata struct {
+	CreatedTime  time.Time
+	DeletionTime time.Time
+	Destroyed    bool
+	Version      int
 }
 
 // SecretAuth is the structure containing auth information if we have it.
@@ -193,3 +210,170 @@ func updateSecret(ours *Secret, theirs *api.Secret) {
 		}
 	}
 }
+
+// vaultMount is a secrets engine mounted in Vault.
+type vaultMount struct {
+	// path is the mount path, including the trailing slash.
+	path string
+
+	// kvVersion is the version of the KV secrets engine, or 0 if the mount is
+	// not a KV secrets engine.
+	kvVersion int
+}
+
+// vaultMountMissTTL is how long a failed mount lookup is remembered before the
+// mount is looked up again.
+const vaultMountMissTTL = time.Minute
+
+// vaultKVv2Mount returns the path of the KV version 2 mount the given path
+// belongs to, or the empty string if the path is not on a KV version 2 mount.
+// Mounts are looked up via sys/internal/ui/mounts and cached on the client set.
+// If the lookup fails (for example on older Vault versions), the path is
+// treated as a regular path, and the lookup is not retried for any path with
+// the same first segment until vaultMountMissTTL has passed.
+func vaultKVv2Mount(clients *ClientSet, path string) string {
+	prefix := vaultMountPrefix(path)
+
+	clients.vaultMountsLock.Lock()
+	mount := cachedVaultMount(clients, path)
+	missed := time.Now().Before(clients.vaultMountMisses[prefix])
+	clients.vaultMountsLock.Unlock()
+
+	if mount == nil {
+		if missed {
+			return ""
+		}
+
+		// The lookup is done without holding the lock, so a slow Vault does not
+		// block every other Vault dependency waiting for its mount.
+		m, err := lookupVaultMount(clients, path)
+
+		clients.vaultMountsLock.Lock()
+		if err != nil {
+			if clients.vaultMountMisses == nil {
+				clients.vaultMountMisses = make(map[string]time.Time)
+			}
+			clients.vaultMountMisses[prefix] = time.Now().Add(vaultMountMissTTL)
+			clients.vaultMountsLock.Unlock()
+
+			log.Printf("[TRACE] vault: could not look up mount for %s, "+
+				"assuming it is not a KV version 2 mount: %s", path, err)
+			return ""
+		}
+		if clients.vaultMounts == nil {
+			clients.vaultMounts = make(map[string]*vaultMount)
+		}
+		clients.vaultMounts[m.path] = m
+		delete(clients.vaultMountMisses, prefix)
+		clients.vaultMountsLock.Unlock()
+
+		mount = m
+	}
+
+	if mount.kvVersion != 2 {
+		return ""
+	}
+	return mount.path
+}
+
+// cachedVaultMount returns the longest cached mount the given path belongs to,
+// or nil if there is none. The caller must hold vaultMountsLock.
+func cachedVaultMount(clients *ClientSet, path string) *vaultMount {
+	var mount *vaultMount
+	for p, m := range clients.vaultMounts {
+		if strings.HasPrefix(path+"/", p) && (mount == nil || len(p) > len(mount.path)) {
+			mount = m
+		}
+	}
+	return mount
+}
+
+// vaultMountPrefix returns the first segment of the given path, including the
+// trailing slash, which failed mount lookups are cached by.
+func vaultMountPrefix(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.Index(path, "/"); i >= 0 {
+		return path[:i+1]
+	}
+	return path + "/"
+}
+
+// lookupVaultMount queries Vault for the mount the given path belongs to.
+func lookupVaultMount(clients *ClientSet, path string) (*vaultMount, error) {
+	client := clients.Vault()
+	r := client.NewRequest("GET", "/v1/sys/internal/ui/mounts/"+path)
+	resp, err := client.RawRequest(r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := api.ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no mount data")
+	}
+
+	mountPath, ok := secret.Data["path"].(string)
+	if !ok || mountPath == "" {
+		return nil, fmt.Errorf("no mount path")
+	}
+
+	m := &vaultMount{
+		path: strings.TrimSuffix(mountPath, "/") + "/",
+	}
+
+	if typ, _ := secret.Data["type"].(string); typ == "kv" || typ == "generic" {
+		m.kvVersion = 1
+		if options, ok := secret.Data["options"].(map[string]interface{}); ok {
+			if v, ok := options["version"].(string); ok && v == "2" {
+				m.kvVersion = 2
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// vaultKVv2Path rewrites a path on the given KV version 2 mount so it
+// addresses the given API prefix, for example "secret/foo" becomes
+// "secret/data/foo". Paths which already address the prefix are returned
+// unchanged, along with false.
+func vaultKVv2Path(mountPath, path, prefix string) (string, bool) {
+	rel := strings.TrimPrefix(path+"/", mountPath)
+	if strings.HasPrefix(rel, prefix+"/") {
+		return path, false
+	}
+	return strings.TrimSuffix(mountPath+prefix+"/"+rel, "/"), true
+}
+
+// unwrapKVv2Secret moves the contents of a KV version 2 response into place,
+// so Data holds the secret itself and Metadata holds the version information.
+func unwrapKVv2Secret(s *Secret) {
+	if s.Data == nil {
+		return
+	}
+
+	raw, ok := s.Data["metadata"].(map[string]interface{})
+	if ok {
+		var m SecretMetadata
+		if v, ok := raw["created_time"].(string); ok && v != "" {
+			m.CreatedTime, _ = time.Parse(time.RFC3339Nano, v)
+		}
+		if v, ok := raw["deletion_time"].(string); ok && v != "" {
+			m.DeletionTime, _ = time.Parse(time.RFC3339Nano, v)
+		}
+		if v, ok := raw["destroyed"].(bool); ok {
+			m.Destroyed = v
+		}
+		m.Version, _ = strconv.Atoi(fmt.Sprintf("%v", raw["version"]))
+		s.Metadata = &m
+	}
+
+	data, _ := s.Data["data"].(map[string]interface{})
+	s.Data = data
+}
@@ -1,5 +1,110 @@
 package dependency
 
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
 func init() {
 	VaultDefaultLeaseDuration = 0
 }
+
+func TestVaultKVv2Path(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		path    string
+		prefix  string
+		exp     string
+		rewrote bool
+	}{
+		{
+			"data",
+			"secret/foo/bar",
+			"data",
+			"secret/data/foo/bar",
+			true,
+		},
+		{
+			"metadata",
+			"secret/foo",
+			"metadata",
+			"secret/metadata/foo",
+			true,
+		},
+		{
+			"mount_root",
+			"secret",
+			"metadata",
+			"secret/metadata",
+			true,
+		},
+		{
+			"already_rewritten",
+			"secret/data/foo",
+			"data",
+			"secret/data/foo",
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, rewrote := vaultKVv2Path("secret/", tc.path, tc.prefix)
+			assert.Equal(t, tc.exp, act)
+			assert.Equal(t, tc.rewrote, rewrote)
+		})
+	}
+}
+
+func TestVaultKVv2Mount_lookupFailed(t *testing.T) {
+	t.Parallel()
+
+	// The fake Vault does not know the mounts endpoint, like older versions.
+	var lookups int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	clients := NewClientSet()
+	if err := clients.CreateVaultClient(&CreateVaultClientInput{
+		Address: ts.URL,
+		Token:   "token",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"secret/foo", "secret/bar/baz", "secret/foo"} {
+		if mount := vaultKVv2Mount(clients, path); mount != "" {
+			t.Errorf("%s: expected no mount, got %q", path, mount)
+		}
+	}
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("\nexp: %#v\nact: %#v", 1, n)
+	}
+
+	// Other mounts are still looked up.
+	vaultKVv2Mount(clients, "other/foo")
+	if n := atomic.LoadInt32(&lookups); n != 2 {
+		t.Errorf("\nexp: %#v\nact: %#v", 2, n)
+	}
+
+	// Once the failure expired, the mount is looked up again.
+	clients.vaultMountsLock.Lock()
+	clients.vaultMountMisses["secret/"] = time.Now().Add(-time.Second)
+	clients.vaultMountsLock.Unlock()
+
+	vaultKVv2Mount(clients, "secret/foo")
+	if n := atomic.LoadInt32(&lookups); n != 3 {
+		t.Errorf("\nexp: %#v\nact: %#v", 3, n)
+	}
+}
@@ -59,13 +59,19 @@ func (d *VaultListQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interfac
 		}
 	}
 
+	// Keys on a KV version 2 mount are listed from the metadata/ path.
+	path := d.path
+	if mountPath := vaultKVv2Mount(clients, d.path); mountPath != "" {
+		path, _ = vaultKVv2Path(mountPath, d.path, "metadata")
+	}
+
 	// If we got this far, we either didn't have a secret to renew, the secret was
 	// not renewable, or the renewal failed, so attempt a fresh list.
 	log.Printf("[TRACE] %s: LIST %s", d, &url.URL{
-		Path:     "/v1/" + d.path,
+		Path:     "/v1/" + path,
 		RawQuery: opts.String(),
 	})
-	secret, err := clients.Vault().Logical().List(d.path)
+	secret, err := clients.Vault().Logical().List(path)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, d.String())
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +24,10 @@ type VaultReadQuery struct {
 	path   string
 	secret *Secret
 
+	// queryValues are the query parameters sent with the read, such as the
+	// version of a KV version 2 secret.
+	queryValues url.Values
+
 	// vaultSecret is the actual Vault secret which we are renewing
 	vaultSecret *api.Secret
 }
@@ -35,10 +40,39 @@ func NewVaultReadQuery(s string) (*VaultReadQuery, error) {
 		return nil, fmt.Errorf("vault.read: invalid format: %q", s)
 	}
 
-	return &VaultReadQuery{
+	d := &VaultReadQuery{
 		stopCh: make(chan struct{}, 1),
 		path:   s,
-	}, nil
+	}
+
+	if i := strings.Index(s, "?"); i != -1 {
+		d.path = strings.Trim(s[:i], "/")
+		if d.path == "" {
+			return nil, fmt.Errorf("vault.read: invalid format: %q", s)
+		}
+
+		values, err := url.ParseQuery(s[i+1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "vault.read")
+		}
+
+		for k, v := range values {
+			switch k {
+			case "version":
+				if len(v) != 1 {
+					return nil, fmt.Errorf("vault.read: invalid version: %q", s)
+				}
+				if n, err := strconv.Atoi(v[0]); err != nil || n < 0 {
+					return nil, fmt.Errorf("vault.read: invalid version: %q", v[0])
+				}
+			default:
+				return nil, fmt.Errorf("vault.read: unsupported query parameter: %q", k)
+			}
+		}
+		d.queryValues = values
+	}
+
+	return d, nil
 }
 
 // Fetch queries the Vault API
@@ -95,8 +129,15 @@ func (d *VaultReadQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interfac
 		}
 	}
 
+	// Secrets on a KV version 2 mount are read from the data/ path, and the
+	// response is unwrapped before it is exposed to the template.
+	path, kvv2 := d.path, false
+	if mountPath := vaultKVv2Mount(clients, d.path); mountPath != "" {
+		path, kvv2 = vaultKVv2Path(mountPath, d.path, "data")
+	}
+
 	// We don't have a secret, or the prior renewal failed
-	vaultSecret, err := d.readSecret(clients, opts)
+	vaultSecret, err := d.readSecret(clients, path, opts)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, d.String())
 	}
@@ -107,6 +148,9 @@ func (d *VaultReadQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interfac
 	// Create the cloned secret which will be exposed to the template.
 	d.vaultSecret = vaultSecret
 	d.secret = transformSecret(vaultSecret)
+	if kvv2 {
+		unwrapKVv2Secret(d.secret)
+	}
 
 	return respWithMetadata(d.secret)
 }
@@ -123,6 +167,9 @@ func (d *VaultReadQuery) Stop() {
 
 // String returns the human-friendly version of this dependency.
 func (d *VaultReadQuery) String() string {
+	if len(d.queryValues) > 0 {
+		return fmt.Sprintf("vault.read(%s?%s)", d.path, d.queryValues.Encode())
+	}
 	return fmt.Sprintf("vault.read(%s)", d.path)
 }
 
@@ -131,17 +178,35 @@ func (d *VaultReadQuery) Type() Type {
 	return TypeVault
 }
 
-func (d *VaultReadQuery) readSecret(clients *ClientSet, opts *QueryOptions) (*api.Secret, error) {
+func (d *VaultReadQuery) readSecret(clients *ClientSet, path string, opts *QueryOptions) (*api.Secret, error) {
 	log.Printf("[TRACE] %s: GET %s", d, &url.URL{
-		Path:     "/v1/" + d.path,
+		Path:     "/v1/" + path,
 		RawQuery: opts.String(),
 	})
-	vaultSecret, err := clients.Vault().Logical().Read(d.path)
+
+	client := clients.Vault()
+	r := client.NewRequest("GET", "/v1/"+path)
+	for k, v := range d.queryValues {
+		r.Params[k] = v
+	}
+
+	resp, err := client.RawRequest(r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if resp != nil && resp.StatusCode == 404 {
+		return nil, fmt.Errorf("no secret exists at %s", path)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, d.String())
+	}
+
+	vaultSecret, err := api.ParseSecret(resp.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, d.String())
 	}
 	if vaultSecret == nil {
-		return nil, fmt.Errorf("no secret exists at %s", d.path)
+		return nil, fmt.Errorf("no secret exists at %s", path)
 	}
 	return vaultSecret, nil
 }
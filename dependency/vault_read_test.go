@@ -2,6 +2,9 @@ package dependency
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -47,6 +50,27 @@ func TestNewVaultReadQuery(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"version",
+			"secret/foo?version=3",
+			&VaultReadQuery{
+				path:        "secret/foo",
+				queryValues: url.Values{"version": []string{"3"}},
+			},
+			false,
+		},
+		{
+			"invalid_version",
+			"secret/foo?version=abc",
+			nil,
+			true,
+		},
+		{
+			"unsupported_query",
+			"secret/foo?nope=1",
+			nil,
+			true,
+		},
 	}
 
 	for i, tc := range cases {
@@ -207,6 +231,11 @@ func TestVaultReadQuery_String(t *testing.T) {
 			"path",
 			"vault.read(path)",
 		},
+		{
+			"version",
+			"secret/foo?version=3",
+			"vault.read(secret/foo?version=3)",
+		},
 	}
 
 	for i, tc := range cases {
@@ -219,3 +248,62 @@ func TestVaultReadQuery_String(t *testing.T) {
 		})
 	}
 }
+
+func TestVaultReadQuery_FetchKVv2(t *testing.T) {
+	t.Parallel()
+
+	// The fake Vault has a KV version 2 mount at secret/ and records the paths
+	// that were read.
+	var mountLookups int
+	paths := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/internal/ui/mounts/secret/foo":
+			mountLookups++
+			fmt.Fprint(w, `{"data":{"path":"secret/","type":"kv","options":{"version":"2"}}}`)
+		case "/v1/secret/data/foo":
+			paths <- r.URL.Path + "?" + r.URL.RawQuery
+			fmt.Fprint(w, `{"data":{"data":{"zip":"zap"},"metadata":{`+
+				`"created_time":"2018-03-22T02:24:06.945319214Z","deletion_time":"",`+
+				`"destroyed":false,"version":3}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	clients := NewClientSet()
+	if err := clients.CreateVaultClient(&CreateVaultClientInput{
+		Address: ts.URL,
+		Token:   "token",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"secret/foo", "secret/foo?version=3"} {
+		d, err := NewVaultReadQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		act, _, err := d.Fetch(clients, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exp := &Secret{
+			Data: map[string]interface{}{"zip": "zap"},
+			Metadata: &SecretMetadata{
+				CreatedTime: time.Date(2018, 3, 22, 2, 24, 6, 945319214, time.UTC),
+				Version:     3,
+			},
+		}
+		assert.Equal(t, exp, act)
+	}
+
+	assert.Equal(t, "/v1/secret/data/foo?", <-paths)
+	assert.Equal(t, "/v1/secret/data/foo?version=3", <-paths)
+
+	// The mount is looked up once and then cached.
+	assert.Equal(t, 1, mountLookups)
+}
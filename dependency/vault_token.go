@@ -18,6 +18,11 @@ type VaultTokenQuery struct {
 	stopCh      chan struct{}
 	secret      *Secret
 	vaultSecret *api.Secret
+
+	// noRenew disables renewing a token obtained through an auth method, which
+	// is used until its lease is almost expired and then replaced by logging
+	// in again.
+	noRenew bool
 }
 
 // NewVaultTokenQuery creates a new dependency.
@@ -36,6 +41,18 @@ func NewVaultTokenQuery(token string) (*VaultTokenQuery, error) {
 	}, nil
 }
 
+// NewVaultAuthTokenQuery creates a new dependency for a token obtained by
+// logging in with an auth method. The token is replaced by logging in again
+// before it expires, and is only renewed in the meantime if renew is true.
+func NewVaultAuthTokenQuery(token string, renew bool) (*VaultTokenQuery, error) {
+	d, err := NewVaultTokenQuery(token)
+	if err != nil {
+		return nil, err
+	}
+	d.noRenew = !renew
+	return d, nil
+}
+
 // Fetch queries the Vault API
 func (d *VaultTokenQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interface{}, *ResponseMetadata, error) {
 	select {
@@ -46,41 +63,90 @@ func (d *VaultTokenQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interfa
 
 	opts = opts.Merge(&QueryOptions{})
 
-	if vaultSecretRenewable(d.secret) {
-		log.Printf("[TRACE] %s: starting renewer", d)
+	// Start from the login which obtained the token, so its lease is known.
+	if s := clients.vaultAuthSecret(); s != nil && s.Auth != nil &&
+		s.Auth.ClientToken == d.vaultSecret.Auth.ClientToken {
+		d.vaultSecret = s
+		d.secret = transformSecret(s)
+	}
 
-		renewer, err := clients.Vault().NewRenewer(&api.RenewerInput{
-			Grace:  opts.VaultGrace,
-			Secret: d.vaultSecret,
-		})
-		if err != nil {
-			return nil, nil, errors.Wrap(err, d.String())
+	for {
+		renewable := !d.noRenew && vaultSecretRenewable(d.secret)
+		if renewable {
+			if err := d.renew(clients, opts); err != nil {
+				return nil, nil, err
+			}
 		}
-		go renewer.Renew()
-		defer renewer.Stop()
-
-	RENEW:
-		for {
-			select {
-			case err := <-renewer.DoneCh():
-				if err != nil {
-					log.Printf("[WARN] %s: failed to renew: %s", d, err)
-				}
-				log.Printf("[WARN] %s: renewer returned (maybe the lease expired)", d)
-				break RENEW
-			case renewal := <-renewer.RenewCh():
-				log.Printf("[TRACE] %s: successfully renewed", d)
-				printVaultWarnings(d, renewal.Secret.Warnings)
-				updateSecret(d.secret, renewal.Secret)
-			case <-d.stopCh:
-				return nil, nil, ErrStopped
+
+		if !clients.vaultAuthEnabled() {
+			break
+		}
+
+		// The token was obtained through an auth method, so log in again instead
+		// of giving up. Tokens that cannot be renewed are used until their lease
+		// is almost expired.
+		if !renewable {
+			if err := d.sleep(opts); err != nil {
+				return nil, nil, err
 			}
 		}
+
+		log.Printf("[INFO] %s: token expired or could not be renewed, logging in again", d)
+		secret, err := clients.vaultLogin()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, d.String())
+		}
+		printVaultWarnings(d, secret.Warnings)
+
+		d.vaultSecret = secret
+		d.secret = transformSecret(secret)
 	}
 
 	// The secret isn't renewable, probably the generic secret backend.
 	// TODO This is incorrect when given a non-renewable template. We should
 	// instead to a lookup self to determine the lease duration.
+	if err := d.sleep(opts); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, nil, ErrLeaseExpired
+}
+
+// renew renews the token until the renewer gives up, which happens when the
+// renewal fails or the lease is within the grace period.
+func (d *VaultTokenQuery) renew(clients *ClientSet, opts *QueryOptions) error {
+	log.Printf("[TRACE] %s: starting renewer", d)
+
+	renewer, err := clients.Vault().NewRenewer(&api.RenewerInput{
+		Grace:  opts.VaultGrace,
+		Secret: d.vaultSecret,
+	})
+	if err != nil {
+		return errors.Wrap(err, d.String())
+	}
+	go renewer.Renew()
+	defer renewer.Stop()
+
+	for {
+		select {
+		case err := <-renewer.DoneCh():
+			if err != nil {
+				log.Printf("[WARN] %s: failed to renew: %s", d, err)
+			}
+			log.Printf("[WARN] %s: renewer returned (maybe the lease expired)", d)
+			return nil
+		case renewal := <-renewer.RenewCh():
+			log.Printf("[TRACE] %s: successfully renewed", d)
+			printVaultWarnings(d, renewal.Secret.Warnings)
+			updateSecret(d.secret, renewal.Secret)
+		case <-d.stopCh:
+			return ErrStopped
+		}
+	}
+}
+
+// sleep waits until the lease on the token is almost expired.
+func (d *VaultTokenQuery) sleep(opts *QueryOptions) error {
 	dur := vaultRenewDuration(d.secret)
 	if dur < opts.VaultGrace {
 		dur = opts.VaultGrace
@@ -90,11 +156,10 @@ func (d *VaultTokenQuery) Fetch(clients *ClientSet, opts *QueryOptions) (interfa
 	select {
 	case <-time.After(dur):
 		// The lease is almost expired, it's time to request a new one.
+		return nil
 	case <-d.stopCh:
-		return nil, nil, ErrStopped
+		return ErrStopped
 	}
-
-	return nil, nil, ErrLeaseExpired
 }
 
 // CanShare returns if this dependency is shareable.
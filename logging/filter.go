@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"io"
+	"strings"
+
+	"github.com/hashicorp/logutils"
+)
+
+// SubsystemFilter is an io.Writer which drops log lines below the minimum
+// level, like a logutils.LevelFilter, except the minimum level may be
+// overridden for the lines of each subsystem. A line is expected to be in the
+// form "[LEVEL] (subsystem) message", optionally after a timestamp.
+type SubsystemFilter struct {
+	// MinLevel is the minimum level allowed through, and Overrides is the
+	// minimum level of each subsystem which has its own.
+	MinLevel  logutils.LogLevel
+	Overrides map[string]logutils.LogLevel
+
+	// Writer is where log lines which pass the filter are written.
+	Writer io.Writer
+}
+
+// Check returns true if the given line passes the filter.
+func (f *SubsystemFilter) Check(line []byte) bool {
+	level, subsystem, _ := parseLine(string(line))
+	return f.allows(level, subsystem)
+}
+
+// Write implements io.Writer. The log package always writes a single, whole
+// line at a time.
+func (f *SubsystemFilter) Write(p []byte) (int, error) {
+	if !f.Check(p) {
+		return len(p), nil
+	}
+	return f.Writer.Write(p)
+}
+
+// allows returns true if a line of the given level from the given subsystem
+// passes the filter. Lines without a known level always pass.
+func (f *SubsystemFilter) allows(level, subsystem string) bool {
+	min, ok := f.Overrides[subsystem]
+	if !ok {
+		min = f.MinLevel
+	}
+
+	i := levelIndex(level)
+	return i < 0 || i >= levelIndex(string(min))
+}
+
+// levelIndex returns the severity of the given level, or -1 if it is not known.
+func levelIndex(level string) int {
+	for i, l := range Levels {
+		if string(l) == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLine splits a line in the form "[LEVEL] (subsystem) message" into its
+// parts, ignoring anything before the level such as a timestamp. The level and
+// subsystem are empty if the line does not have them, in which case the message
+// is the whole line.
+func parseLine(line string) (level, subsystem, msg string) {
+	level, msg = parseLevel(line)
+	if level == "" {
+		return "", "", msg
+	}
+
+	if strings.HasPrefix(msg, "(") {
+		if z := strings.IndexByte(msg, ')'); z > 0 {
+			subsystem = msg[1:z]
+			msg = strings.TrimLeft(msg[z+1:], " ")
+		}
+	}
+	return level, subsystem, msg
+}
+
+// parseLevel splits a line in the form "[LEVEL] message" into its level and
+// message, as parseLine does.
+func parseLevel(line string) (level, msg string) {
+	line = strings.TrimRight(line, "\r\n")
+
+	x := strings.IndexByte(line, '[')
+	if x < 0 {
+		return "", line
+	}
+	y := strings.IndexByte(line[x:], ']')
+	if y < 0 || levelIndex(line[x+1:x+y]) < 0 {
+		return "", line
+	}
+	return line[x+1 : x+y], strings.TrimLeft(line[x+y+1:], " ")
+}
@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/logutils"
+)
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		name      string
+		line      string
+		level     string
+		subsystem string
+		msg       string
+	}{
+		{
+			"level_subsystem",
+			"[INFO] (runner) starting\n",
+			"INFO",
+			"runner",
+			"starting",
+		},
+		{
+			"timestamp",
+			"2017/01/01 00:00:00.000000 [DEBUG] (view) polling",
+			"DEBUG",
+			"view",
+			"polling",
+		},
+		{
+			"no_subsystem",
+			"[WARN] disk is full",
+			"WARN",
+			"",
+			"disk is full",
+		},
+		{
+			"no_level",
+			"(runner) starting",
+			"",
+			"",
+			"(runner) starting",
+		},
+		{
+			"unknown_level",
+			"[NOPE] (runner) starting",
+			"",
+			"",
+			"[NOPE] (runner) starting",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			level, subsystem, msg := parseLine(tc.line)
+			if level != tc.level {
+				t.Errorf("expected level %q to be %q", level, tc.level)
+			}
+			if subsystem != tc.subsystem {
+				t.Errorf("expected subsystem %q to be %q", subsystem, tc.subsystem)
+			}
+			if msg != tc.msg {
+				t.Errorf("expected msg %q to be %q", msg, tc.msg)
+			}
+		})
+	}
+}
+
+func TestSubsystemFilter(t *testing.T) {
+	cases := []struct {
+		name   string
+		line   string
+		logged bool
+	}{
+		{
+			"above_min",
+			"[WARN] (runner) slow",
+			true,
+		},
+		{
+			"below_min",
+			"[DEBUG] (runner) checking",
+			false,
+		},
+		{
+			"override_below_min",
+			"[DEBUG] (view) polling",
+			true,
+		},
+		{
+			"override_trace",
+			"[TRACE] (view) polling",
+			false,
+		},
+		{
+			"override_above_min",
+			"[INFO] (child) spawning",
+			false,
+		},
+		{
+			"no_level",
+			"(runner) starting",
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			var buf bytes.Buffer
+			f := &SubsystemFilter{
+				MinLevel: logutils.LogLevel("INFO"),
+				Overrides: map[string]logutils.LogLevel{
+					"view":  "DEBUG",
+					"child": "ERR",
+				},
+				Writer: &buf,
+			}
+
+			n, err := f.Write([]byte(tc.line))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(tc.line) {
+				t.Errorf("expected %d to be %d", n, len(tc.line))
+			}
+			if logged := buf.Len() > 0; logged != tc.logged {
+				t.Errorf("expected logged %t to be %t", logged, tc.logged)
+			}
+		})
+	}
+}
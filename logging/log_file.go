@@ -0,0 +1,165 @@
+package logging
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logFile is an io.Writer which appends to a file, and rotates the file once it
+// grows past a size or age. A rotated file is renamed with the time of the
+// rotation in nanoseconds, such as "consul-template-1507000000000000000.log",
+// and the oldest rotated files are removed once there are too many.
+type logFile struct {
+	path string
+
+	// maxBytes and duration are the size and age which rotate the file, and
+	// maxFiles is the number of rotated files to keep. Each is disabled when 0.
+	maxBytes int64
+	duration time.Duration
+	maxFiles int
+
+	// lock protects the open file, and the number of bytes written to it since
+	// it was created or opened.
+	lock     sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// newLogFile opens the log file at the given path, creating it if it does not
+// exist.
+func newLogFile(path string, maxBytes int, duration time.Duration, maxFiles int) (*logFile, error) {
+	f := &logFile{
+		path:     path,
+		maxBytes: int64(maxBytes),
+		duration: duration,
+		maxFiles: maxFiles,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer. The file is rotated before the write if the
+// write would grow it past its size, or if it is too old, unless it is empty.
+func (f *logFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return 0, fmt.Errorf("log file %s is closed", f.path)
+	}
+
+	now := time.Now()
+	bySize := f.maxBytes > 0 && f.size+int64(len(p)) > f.maxBytes
+	byAge := f.duration > 0 && now.Sub(f.openedAt) >= f.duration
+	if f.size > 0 && (bySize || byAge) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (f *logFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the file for appending. The lock must be held.
+func (f *logFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening log file: %s", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error opening log file: %s", err)
+	}
+
+	f.file = file
+	f.size = stat.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+// rotate renames the file with the given time and opens a new one, then removes
+// the oldest rotated files. The lock must be held.
+func (f *logFile) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error rotating log file: %s", err)
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	rotated := fmt.Sprintf("%s-%d%s", strings.TrimSuffix(f.path, ext), now.UnixNano(), ext)
+	if err := os.Rename(f.path, rotated); err != nil {
+		return fmt.Errorf("error rotating log file: %s", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune removes the oldest rotated files, keeping maxFiles of them.
+func (f *logFile) prune() error {
+	if f.maxFiles <= 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(f.path)
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error pruning log files: %s", err)
+	}
+
+	var rotated []string
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := strconv.ParseInt(stamp, 10, 64); err != nil {
+			continue
+		}
+		rotated = append(rotated, name)
+	}
+
+	// The names differ only by the time of the rotation, which has the same
+	// number of digits for centuries.
+	sort.Strings(rotated)
+	for len(rotated) > f.maxFiles {
+		if err := os.Remove(filepath.Join(dir, rotated[0])); err != nil {
+			return fmt.Errorf("error pruning log files: %s", err)
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogFile_rotate(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxBytes int
+		duration time.Duration
+		maxFiles int
+		files    int
+		contents string
+	}{
+		{
+			"none",
+			0,
+			0,
+			0,
+			1,
+			"first\nsecond\nthird\n",
+		},
+		{
+			"bytes",
+			10,
+			0,
+			0,
+			3,
+			"third\n",
+		},
+		{
+			"bytes_max_files",
+			10,
+			0,
+			1,
+			2,
+			"third\n",
+		},
+		{
+			"duration",
+			0,
+			time.Hour,
+			0,
+			2,
+			"second\nthird\n",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "consul-template.log")
+			f, err := newLogFile(path, tc.maxBytes, tc.duration, tc.maxFiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			for i, line := range []string{"first\n", "second\n", "third\n"} {
+				if _, err := f.Write([]byte(line)); err != nil {
+					t.Fatal(err)
+				}
+
+				// Age the file once, so the next write rotates it.
+				if i == 0 {
+					f.openedAt = f.openedAt.Add(-2 * time.Hour)
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			infos, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(infos) != tc.files {
+				t.Errorf("expected %d files to be %d", len(infos), tc.files)
+			}
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.contents {
+				t.Errorf("expected %q to be %q", b, tc.contents)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-syslog"
+)
+
+var (
+	// jsonOut is the output of log records when logging in JSON, or nil when
+	// logging in text.
+	jsonOut     *jsonOutput
+	jsonOutLock sync.RWMutex
+)
+
+// Logger writes the log lines of a subsystem, along with contextual fields
+// which are kept as structured data when logging in JSON. When logging in
+// text, lines are written with the log package as before, and the fields are
+// left out.
+type Logger struct {
+	subsystem string
+	fields    []interface{}
+}
+
+// New returns a logger for the given subsystem, such as "runner".
+func New(subsystem string) *Logger {
+	return &Logger{subsystem: subsystem}
+}
+
+// With returns a logger which adds the given pairs of keys and values to each
+// line, in addition to the fields of this logger.
+func (l *Logger) With(args ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(args))
+	fields = append(fields, l.fields...)
+	fields = append(fields, args...)
+	return &Logger{subsystem: l.subsystem, fields: fields}
+}
+
+// Printf logs a line in the same form as log.Printf, with the level in square
+// brackets at the start of the format, such as "[INFO] rendered %q". The
+// subsystem goes after the level.
+func (l *Logger) Printf(format string, v ...interface{}) {
+	level, msg := parseLevel(fmt.Sprintf(format, v...))
+
+	jsonOutLock.RLock()
+	out := jsonOut
+	jsonOutLock.RUnlock()
+
+	if out != nil {
+		out.log(level, l.subsystem, msg, l.fields)
+		return
+	}
+
+	switch {
+	case level == "":
+		log.Printf("(%s) %s", l.subsystem, msg)
+	default:
+		log.Printf("[%s] (%s) %s", level, l.subsystem, msg)
+	}
+}
+
+func setJSONOutput(out *jsonOutput) {
+	jsonOutLock.Lock()
+	defer jsonOutLock.Unlock()
+	jsonOut = out
+}
+
+// jsonOutput writes log records as JSON with hclog. It is also an io.Writer for
+// the log package, which parses each line into a record.
+type jsonOutput struct {
+	filter *SubsystemFilter
+	writer io.Writer
+	syslog gsyslog.Syslogger
+
+	// lock protects buf, which logger encodes each record into.
+	lock   sync.Mutex
+	buf    bytes.Buffer
+	logger hclog.Logger
+}
+
+func newJSONOutput(filter *SubsystemFilter, w io.Writer, syslog gsyslog.Syslogger) *jsonOutput {
+	o := &jsonOutput{
+		filter: filter,
+		writer: w,
+		syslog: syslog,
+	}
+	o.logger = hclog.New(&hclog.LoggerOptions{
+		Level:      hclog.Trace,
+		Output:     &o.buf,
+		JSONFormat: true,
+	})
+	return o
+}
+
+// Write implements io.Writer.
+func (o *jsonOutput) Write(p []byte) (int, error) {
+	level, subsystem, msg := parseLine(string(p))
+	o.log(level, subsystem, msg, nil)
+	return len(p), nil
+}
+
+// log writes a record, unless its level is filtered out.
+func (o *jsonOutput) log(level, subsystem, msg string, fields []interface{}) {
+	if !o.filter.allows(level, subsystem) {
+		return
+	}
+
+	args := make([]interface{}, 0, len(fields)+2)
+	if subsystem != "" {
+		args = append(args, "subsystem", subsystem)
+	}
+	args = append(args, fields...)
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.buf.Reset()
+	switch level {
+	case "TRACE":
+		o.logger.Trace(msg, args...)
+	case "DEBUG":
+		o.logger.Debug(msg, args...)
+	case "WARN":
+		o.logger.Warn(msg, args...)
+	case "ERR":
+		o.logger.Error(msg, args...)
+	default:
+		o.logger.Info(msg, args...)
+	}
+
+	o.writer.Write(o.buf.Bytes())
+	if o.syslog != nil {
+		priority, ok := syslogPriorityMap[level]
+		if !ok {
+			priority = gsyslog.LOG_NOTICE
+		}
+		o.syslog.WriteLevel(priority, o.buf.Bytes())
+	}
+}
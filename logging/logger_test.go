@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLogger_json(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Setup(&Config{
+		Level:           "INFO",
+		Format:          "json",
+		SubsystemLevels: map[string]string{"view": "DEBUG"},
+		Writer:          &buf,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer Setup(&Config{Level: "WARN", Writer: os.Stderr})
+
+	New("view").With("dependency", "kv.block(foo)").With("index", 5).
+		Printf("[DEBUG] received data")
+	New("runner").Printf("[DEBUG] dropped")
+	log.Printf("[WARN] (runner) watching %d dependencies", 200)
+
+	var records []map[string]interface{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]interface{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		delete(r, "@timestamp")
+		records = append(records, r)
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"@level":     "debug",
+			"@message":   "received data",
+			"subsystem":  "view",
+			"dependency": "kv.block(foo)",
+			"index":      float64(5),
+		},
+		{
+			"@level":    "warn",
+			"@message":  "watching 200 dependencies",
+			"subsystem": "runner",
+		},
+	}
+	if !reflect.DeepEqual(expected, records) {
+		t.Errorf("\nexp: %#v\nact: %#v", expected, records)
+	}
+}
+
+func TestSetup_invalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		config *Config
+	}{
+		{
+			"level",
+			&Config{Level: "NOPE"},
+		},
+		{
+			"subsystem_level",
+			&Config{Level: "INFO", SubsystemLevels: map[string]string{"view": "NOPE"}},
+		},
+		{
+			"format",
+			&Config{Level: "INFO", Format: "xml"},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			if err := Setup(tc.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package logging
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/go-rootcerts"
 	"github.com/hashicorp/go-syslog"
 	"github.com/hashicorp/logutils"
 )
@@ -14,6 +18,13 @@ import (
 // Levels are the log levels we respond to=o.
 var Levels = []logutils.LogLevel{"TRACE", "DEBUG", "INFO", "WARN", "ERR"}
 
+var (
+	// closers are the log file and syslog connection of the last setup, which
+	// are closed once they are replaced.
+	closers     []io.Closer
+	closersLock sync.Mutex
+)
+
 // Config is the configuration for this log setup.
 type Config struct {
 	// Name is the progname as it will appear in syslog output (if enabled).
@@ -22,51 +33,190 @@ type Config struct {
 	// Level is the log level to use.
 	Level string `json:"level"`
 
+	// Format is the format of each log line, either "text" or "json". The
+	// default is "text".
+	Format string `json:"format"`
+
+	// SubsystemLevels is the log level to use for the lines of each subsystem,
+	// such as "view", instead of Level.
+	SubsystemLevels map[string]string `json:"subsystem_levels"`
+
 	// Syslog and SyslogFacility are the syslog configuration options.
 	Syslog         bool   `json:"syslog"`
 	SyslogFacility string `json:"syslog_facility"`
 
+	// SyslogAddress is the address of a remote syslog server to send to instead
+	// of the local syslog socket, over SyslogProtocol, either "udp" or "tcp".
+	SyslogAddress  string `json:"syslog_address"`
+	SyslogProtocol string `json:"syslog_protocol"`
+
+	// SyslogSSL* are the TLS configuration options of the remote syslog server.
+	SyslogSSLEnabled    bool   `json:"syslog_ssl_enabled"`
+	SyslogSSLVerify     bool   `json:"syslog_ssl_verify"`
+	SyslogSSLCert       string `json:"syslog_ssl_cert"`
+	SyslogSSLKey        string `json:"syslog_ssl_key"`
+	SyslogSSLCACert     string `json:"syslog_ssl_ca_cert"`
+	SyslogSSLCAPath     string `json:"syslog_ssl_ca_path"`
+	SyslogSSLServerName string `json:"syslog_ssl_server_name"`
+
+	// LogFile is the path of a file to write logs to in addition to Writer. It
+	// is rotated once it grows past LogRotateBytes or LogRotateDuration, and
+	// LogRotateMaxFiles rotated files are kept. Each is disabled when 0.
+	LogFile           string        `json:"log_file"`
+	LogRotateBytes    int           `json:"log_rotate_bytes"`
+	LogRotateDuration time.Duration `json:"log_rotate_duration"`
+	LogRotateMaxFiles int           `json:"log_rotate_max_files"`
+
 	// Writer is the output where logs should go. If syslog is enabled, data will
 	// be written to writer in addition to syslog.
 	Writer io.Writer `json:"-"`
 }
 
 func Setup(config *Config) error {
-	var logOutput io.Writer
-
 	// Setup the default logging
 	logFilter := NewLogFilter()
 	logFilter.MinLevel = logutils.LogLevel(strings.ToUpper(config.Level))
-	logFilter.Writer = config.Writer
 	if !ValidateLevelFilter(logFilter.MinLevel, logFilter) {
-		levels := make([]string, 0, len(logFilter.Levels))
-		for _, level := range logFilter.Levels {
-			levels = append(levels, string(level))
-		}
 		return fmt.Errorf("invalid log level %q, valid log levels are %s",
-			config.Level, strings.Join(levels, ", "))
+			config.Level, validLevels(logFilter))
+	}
+
+	if f := strings.ToLower(config.Format); f != "" && f != "text" && f != "json" {
+		return fmt.Errorf("invalid log format %q, valid log formats are text, json",
+			config.Format)
+	}
+
+	filter := &SubsystemFilter{
+		MinLevel:  logFilter.MinLevel,
+		Overrides: make(map[string]logutils.LogLevel, len(config.SubsystemLevels)),
+	}
+	for subsystem, level := range config.SubsystemLevels {
+		l := logutils.LogLevel(strings.ToUpper(level))
+		if !ValidateLevelFilter(l, logFilter) {
+			return fmt.Errorf("invalid log level %q for %q, valid log levels are %s",
+				level, subsystem, validLevels(logFilter))
+		}
+		filter.Overrides[subsystem] = l
+	}
+
+	var outputs []io.Closer
+	fail := func(err error) error {
+		for _, c := range outputs {
+			c.Close()
+		}
+		return err
 	}
 
+	// Check if a log file is enabled
+	writer := config.Writer
+	if config.LogFile != "" {
+		f, err := newLogFile(config.LogFile, config.LogRotateBytes,
+			config.LogRotateDuration, config.LogRotateMaxFiles)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, f)
+		writer = f
+		if config.Writer != nil {
+			writer = io.MultiWriter(config.Writer, f)
+		}
+	}
+	filter.Writer = writer
+
 	// Check if syslog is enabled
+	var syslog gsyslog.Syslogger
 	if config.Syslog {
-		log.Printf("[DEBUG] (logging) enabling syslog on %s", config.SyslogFacility)
+		var l gsyslog.Syslogger
+		var err error
+		if config.SyslogAddress != "" {
+			log.Printf("[DEBUG] (logging) enabling syslog on %s://%s",
+				config.SyslogProtocol, config.SyslogAddress)
 
-		l, err := gsyslog.NewLogger(gsyslog.LOG_NOTICE, config.SyslogFacility, config.Name)
+			var tlsConfig *tls.Config
+			if config.SyslogSSLEnabled {
+				if tlsConfig, err = syslogTLSConfig(config); err != nil {
+					return fail(err)
+				}
+			}
+			l, err = newRemoteSyslog(config.SyslogProtocol, config.SyslogAddress,
+				tlsConfig, config.SyslogFacility, config.Name)
+		} else {
+			log.Printf("[DEBUG] (logging) enabling syslog on %s", config.SyslogFacility)
+			l, err = gsyslog.NewLogger(gsyslog.LOG_NOTICE, config.SyslogFacility, config.Name)
+		}
 		if err != nil {
-			return fmt.Errorf("error setting up syslog logger: %s", err)
+			return fail(fmt.Errorf("error setting up syslog logger: %s", err))
 		}
-		syslog := &SyslogWrapper{l, logFilter}
-		logOutput = io.MultiWriter(logFilter, syslog)
-	} else {
-		logOutput = io.MultiWriter(logFilter)
+		outputs = append(outputs, l)
+		syslog = l
 	}
 
-	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC)
-	log.SetOutput(logOutput)
+	switch strings.ToLower(config.Format) {
+	case "", "text":
+		var logOutput io.Writer = filter
+		if syslog != nil {
+			logOutput = io.MultiWriter(filter, &SyslogWrapper{syslog, filter})
+		}
+
+		setJSONOutput(nil)
+		log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC)
+		log.SetOutput(logOutput)
+	case "json":
+		// Lines still written with the log package are parsed into records, so
+		// the timestamp is left to the record.
+		out := newJSONOutput(filter, writer, syslog)
+		setJSONOutput(out)
+		log.SetFlags(0)
+		log.SetOutput(out)
+	}
+
+	closersLock.Lock()
+	defer closersLock.Unlock()
+	for _, c := range closers {
+		c.Close()
+	}
+	closers = outputs
 
 	return nil
 }
 
+// syslogTLSConfig returns the TLS configuration of the remote syslog server.
+func syslogTLSConfig(config *Config) (*tls.Config, error) {
+	var tlsConfig tls.Config
+
+	// Custom certificate or certificate and key
+	if config.SyslogSSLCert != "" {
+		key := config.SyslogSSLKey
+		if key == "" {
+			key = config.SyslogSSLCert
+		}
+		cert, err := tls.LoadX509KeyPair(config.SyslogSSLCert, key)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up syslog TLS: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// Custom CA certificate
+	if config.SyslogSSLCACert != "" || config.SyslogSSLCAPath != "" {
+		rootConfig := &rootcerts.Config{
+			CAFile: config.SyslogSSLCACert,
+			CAPath: config.SyslogSSLCAPath,
+		}
+		if err := rootcerts.ConfigureTLS(&tlsConfig, rootConfig); err != nil {
+			return nil, fmt.Errorf("error setting up syslog TLS: %s", err)
+		}
+	}
+
+	tlsConfig.ServerName = config.SyslogSSLServerName
+	if !config.SyslogSSLVerify {
+		log.Printf("[WARN] (logging) disabling syslog SSL verification")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return &tlsConfig, nil
+}
+
 // NewLogFilter returns a LevelFilter that is configured with the log levels that
 // we use.
 func NewLogFilter() *logutils.LevelFilter {
@@ -86,3 +236,11 @@ func ValidateLevelFilter(min logutils.LogLevel, filter *logutils.LevelFilter) bo
 	}
 	return false
 }
+
+func validLevels(filter *logutils.LevelFilter) string {
+	levels := make([]string, 0, len(filter.Levels))
+	for _, level := range filter.Levels {
+		levels = append(levels, string(level))
+	}
+	return strings.Join(levels, ", ")
+}
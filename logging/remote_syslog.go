@@ -0,0 +1,285 @@
+package logging
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-syslog"
+)
+
+const (
+	// remoteSyslogTimeout is the maximum amount of time to wait to connect to
+	// the remote syslog server, or to write a message to it.
+	remoteSyslogTimeout = 1 * time.Second
+
+	// remoteSyslogQueueSize is the number of messages which are queued while
+	// they are sent to the remote syslog server. Messages logged while the
+	// queue is full are dropped.
+	remoteSyslogQueueSize = 1024
+
+	// remoteSyslogMinBackoff and remoteSyslogMaxBackoff bound how long to wait
+	// before connecting again once the remote syslog server could not be
+	// reached. Messages logged in the meantime are dropped.
+	remoteSyslogMinBackoff = 1 * time.Second
+	remoteSyslogMaxBackoff = 1 * time.Minute
+)
+
+// syslogFacilities is used to map a syslog facility name to its code.
+var syslogFacilities = map[string]int{
+	"KERN":     0,
+	"USER":     1,
+	"MAIL":     2,
+	"DAEMON":   3,
+	"AUTH":     4,
+	"SYSLOG":   5,
+	"LPR":      6,
+	"NEWS":     7,
+	"UUCP":     8,
+	"CRON":     9,
+	"AUTHPRIV": 10,
+	"FTP":      11,
+	"LOCAL0":   16,
+	"LOCAL1":   17,
+	"LOCAL2":   18,
+	"LOCAL3":   19,
+	"LOCAL4":   20,
+	"LOCAL5":   21,
+	"LOCAL6":   22,
+	"LOCAL7":   23,
+}
+
+// remoteSyslog is a gsyslog.Syslogger which sends messages to a remote syslog
+// server in the RFC 5424 format, over UDP, TCP or TLS. Messages sent over TCP
+// or TLS are framed with octet counting, as described in RFC 6587.
+//
+// Messages are queued and sent from a separate goroutine, so logging never
+// waits for the server. While the server cannot be reached, messages are
+// dropped and counted, and the number dropped is sent once it is reached again.
+type remoteSyslog struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+
+	facility int
+	hostname string
+	tag      string
+
+	queue   chan []byte
+	dropped uint64
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+
+	// conn is nil until connected, or once a write fails. It is only used by
+	// the sending goroutine after the first connection.
+	conn net.Conn
+}
+
+// newRemoteSyslog connects to the syslog server at the given address, over the
+// given network, either "udp" or "tcp". A TLS configuration requires "tcp". If
+// the server cannot be reached yet, a warning is logged and the connection is
+// made once there are messages to send, so the server is not required to start.
+func newRemoteSyslog(network, address string, tlsConfig *tls.Config, facility, tag string) (*remoteSyslog, error) {
+	switch network {
+	case "udp":
+		if tlsConfig != nil {
+			return nil, fmt.Errorf("syslog over TLS requires the tcp protocol")
+		}
+	case "tcp":
+	default:
+		return nil, fmt.Errorf("invalid syslog protocol %q, valid protocols are udp, tcp",
+			network)
+	}
+
+	code, ok := syslogFacilities[strings.ToUpper(facility)]
+	if !ok {
+		return nil, fmt.Errorf("invalid syslog facility %q", facility)
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	s := &remoteSyslog{
+		network:   network,
+		address:   address,
+		tlsConfig: tlsConfig,
+		facility:  code,
+		hostname:  hostname,
+		tag:       tag,
+		queue:     make(chan []byte, remoteSyslogQueueSize),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	if err := s.connect(); err != nil {
+		log.Printf("[WARN] (logging) %s, messages are dropped until it can be reached", err)
+	}
+	go s.run()
+	return s, nil
+}
+
+// WriteLevel implements gsyslog.Syslogger. The message is queued to be sent,
+// or dropped if the queue is full.
+func (s *remoteSyslog) WriteLevel(p gsyslog.Priority, b []byte) error {
+	select {
+	case s.queue <- s.format(p, b):
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+	return nil
+}
+
+// Write implements gsyslog.Syslogger.
+func (s *remoteSyslog) Write(b []byte) (int, error) {
+	if err := s.WriteLevel(gsyslog.LOG_NOTICE, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close implements gsyslog.Syslogger. The messages which are already queued
+// are sent first.
+func (s *remoteSyslog) Close() error {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	<-s.doneCh
+	return nil
+}
+
+// Dropped returns the number of messages which were dropped because the queue
+// was full or the server could not be reached.
+func (s *remoteSyslog) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// run sends the queued messages until the writer is closed.
+func (s *remoteSyslog) run() {
+	defer close(s.doneCh)
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+
+	var backoff time.Duration
+	var retryAt time.Time
+	var reported uint64
+	send := func(msg []byte) {
+		// Do not try to connect again until the backoff has passed.
+		if s.conn == nil && time.Now().Before(retryAt) {
+			atomic.AddUint64(&s.dropped, 1)
+			return
+		}
+
+		// If the write fails, the connection is made again and the message is
+		// retried once.
+		for i := 0; i < 2; i++ {
+			if s.conn == nil {
+				if err := s.connect(); err != nil {
+					backoff *= 2
+					if backoff < remoteSyslogMinBackoff {
+						backoff = remoteSyslogMinBackoff
+					}
+					if backoff > remoteSyslogMaxBackoff {
+						backoff = remoteSyslogMaxBackoff
+					}
+					retryAt = time.Now().Add(backoff)
+					break
+				}
+				backoff = 0
+
+				if dropped := s.Dropped(); dropped != reported {
+					notice := s.format(gsyslog.LOG_WARNING, []byte(fmt.Sprintf(
+						"(logging) dropped %d messages while the syslog server was unavailable",
+						dropped-reported)))
+					if s.write(notice) == nil {
+						reported = dropped
+					}
+				}
+			}
+
+			if s.conn != nil && s.write(msg) == nil {
+				return
+			}
+		}
+		atomic.AddUint64(&s.dropped, 1)
+	}
+
+	for {
+		select {
+		case msg := <-s.queue:
+			send(msg)
+		case <-s.stopCh:
+			for {
+				select {
+				case msg := <-s.queue:
+					send(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes the message to the current connection, and closes the
+// connection if the write fails.
+func (s *remoteSyslog) write(msg []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(remoteSyslogTimeout))
+	_, err := s.conn.Write(msg)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+// connect makes the connection to the syslog server.
+func (s *remoteSyslog) connect() error {
+	dialer := &net.Dialer{Timeout: remoteSyslogTimeout}
+
+	var conn net.Conn
+	var err error
+	if s.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, s.network, s.address, s.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(s.network, s.address)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to syslog server %s: %s", s.address, err)
+	}
+
+	s.conn = conn
+	return nil
+}
+
+// format returns the message in the RFC 5424 format, without structured data,
+// and framed for the network.
+func (s *remoteSyslog) format(p gsyslog.Priority, b []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %d - - %s",
+		s.facility*8+int(p),
+		time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		s.tag,
+		os.Getpid(),
+		bytes.TrimRight(b, "\r\n"),
+	)
+
+	if s.network == "udp" {
+		return buf.Bytes()
+	}
+	return append([]byte(fmt.Sprintf("%d ", buf.Len())), buf.Bytes()...)
+}
@@ -0,0 +1,204 @@
+package logging
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-syslog"
+)
+
+func TestRemoteSyslog(t *testing.T) {
+	hostname, _ := os.Hostname()
+	exp := regexp.MustCompile(fmt.Sprintf(`^<132>1 \S+ %s consul-template %d - - \(runner\) slow$`,
+		regexp.QuoteMeta(hostname), os.Getpid()))
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		s, err := newRemoteSyslog("udp", conn.LocalAddr().String(), nil, "LOCAL0", "consul-template")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		if err := s.WriteLevel(gsyslog.LOG_WARNING, []byte("(runner) slow\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := make([]byte, 1024)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := string(buf[:n]); !exp.MatchString(msg) {
+			t.Errorf("expected %q to match %q", msg, exp)
+		}
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+
+		s, err := newRemoteSyslog("tcp", ln.Addr().String(), nil, "LOCAL0", "consul-template")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			if err := s.WriteLevel(gsyslog.LOG_WARNING, []byte("(runner) slow\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Each message is framed with its length.
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			l, err := r.ReadString(' ')
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(l))
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				t.Fatal(err)
+			}
+			if msg := string(buf); !exp.MatchString(msg) {
+				t.Errorf("expected %q to match %q", msg, exp)
+			}
+		}
+	})
+}
+
+func TestRemoteSyslog_unavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newRemoteSyslog("tcp", ln.Addr().String(), nil, "LOCAL0", "consul-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The server goes away after the first connection.
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+
+	// Logging does not wait for the server, and messages which cannot be sent
+	// are dropped.
+	start := time.Now()
+	n := 2 * remoteSyslogQueueSize
+	for i := 0; i < n; i++ {
+		if err := s.WriteLevel(gsyslog.LOG_WARNING, []byte("(runner) slow\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d > remoteSyslogTimeout {
+		t.Errorf("expected writes to not block, took %s", d)
+	}
+
+	s.Close()
+	if dropped := s.Dropped(); dropped < uint64(n-2) {
+		t.Errorf("expected at least %d messages to be dropped, dropped %d", n-2, dropped)
+	}
+}
+
+func TestRemoteSyslog_notStarted(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	// The server is not required to start.
+	s, err := newRemoteSyslog("tcp", addr, nil, "LOCAL0", "consul-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Once the server starts, the connection is made to send the next message.
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if err := s.WriteLevel(gsyslog.LOG_WARNING, []byte("(runner) slow\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); !strings.Contains(msg, "(runner) slow") {
+		t.Errorf("expected %q to contain %q", msg, "(runner) slow")
+	}
+}
+
+func TestNewRemoteSyslog_invalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		network  string
+		facility string
+	}{
+		{
+			"protocol",
+			"unix",
+			"LOCAL0",
+		},
+		{
+			"facility",
+			"udp",
+			"NOPE",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			if _, err := newRemoteSyslog(tc.network, "127.0.0.1:514", nil, tc.facility, "consul-template"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/burntsushi/toml"
+	"github.com/hashicorp/consul-template/config"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// CheckInput is used as input to the check function.
+type CheckInput struct {
+	// Command is the command to run to validate the file. The path to the file
+	// is available as {{.TempPath}}.
+	Command string
+
+	// Format is the name of a built-in syntax check: "json", "yaml" or "toml".
+	Format string
+
+	// Timeout is the maximum amount of time to wait for the command to exit.
+	Timeout time.Duration
+
+	Env    []string
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// checkCommandData is the data available to a check command.
+type checkCommandData struct {
+	TempPath string
+}
+
+// Check validates the file at the given path, which holds the rendered contents
+// of a template before they replace the destination. The built-in syntax check
+// is run first, followed by the command.
+func Check(i *CheckInput, path string) error {
+	if i.Format != "" {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed reading file")
+		}
+		if err := checkFormat(i.Format, contents); err != nil {
+			return err
+		}
+	}
+
+	if i.Command == "" {
+		return nil
+	}
+
+	tmpl, err := texttemplate.New("check").Parse(i.Command)
+	if err != nil {
+		return errors.Wrap(err, "failed parsing check command")
+	}
+	var command bytes.Buffer
+	if err := tmpl.Execute(&command, &checkCommandData{TempPath: path}); err != nil {
+		return errors.Wrap(err, "failed rendering check command")
+	}
+
+	// A timeout is required, since without one the command would be supervised
+	// instead of waited for.
+	timeout := i.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultCheckTimeout
+	}
+
+	if _, err := spawnChild(&spawnChildInput{
+		Stdout:  i.Stdout,
+		Stderr:  i.Stderr,
+		Command: command.String(),
+		Env:     i.Env,
+		Timeout: timeout,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to execute check command %q", command.String())
+	}
+	return nil
+}
+
+// checkFormat returns an error if the contents are not valid in the given
+// format.
+func checkFormat(format string, contents []byte) error {
+	var v interface{}
+
+	switch format {
+	case "json":
+		if err := json.Unmarshal(contents, &v); err != nil {
+			return errors.Wrap(err, "invalid json")
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(contents, &v); err != nil {
+			return errors.Wrap(err, "invalid yaml")
+		}
+	case "toml":
+		var m map[string]interface{}
+		if _, err := toml.Decode(string(contents), &m); err != nil {
+			return errors.Wrap(err, "invalid toml")
+		}
+	default:
+		return fmt.Errorf("unknown check format %q", format)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		i        *CheckInput
+		contents string
+		err      bool
+	}{
+		{
+			"empty",
+			&CheckInput{},
+			"anything",
+			false,
+		},
+		{
+			"json",
+			&CheckInput{Format: "json"},
+			`{"a": [1, 2]}`,
+			false,
+		},
+		{
+			"json_invalid",
+			&CheckInput{Format: "json"},
+			`{"a": [1, 2}`,
+			true,
+		},
+		{
+			"yaml",
+			&CheckInput{Format: "yaml"},
+			"a:\n  - 1\n  - 2\n",
+			false,
+		},
+		{
+			"yaml_invalid",
+			&CheckInput{Format: "yaml"},
+			"a: [1, 2\n",
+			true,
+		},
+		{
+			"toml",
+			&CheckInput{Format: "toml"},
+			"[a]\nb = 1\n",
+			false,
+		},
+		{
+			"toml_invalid",
+			&CheckInput{Format: "toml"},
+			"[a\nb = 1\n",
+			true,
+		},
+		{
+			"unknown_format",
+			&CheckInput{Format: "xml"},
+			"<a/>",
+			true,
+		},
+		{
+			"command",
+			&CheckInput{Command: "grep -q hello {{.TempPath}}"},
+			"hello world",
+			false,
+		},
+		{
+			"command_fails",
+			&CheckInput{Command: "grep -q hello {{.TempPath}}"},
+			"goodbye world",
+			true,
+		},
+		{
+			"command_bad_template",
+			&CheckInput{Command: "cat {{.TempPath"},
+			"hello",
+			true,
+		},
+		{
+			"format_before_command",
+			&CheckInput{Format: "json", Command: "true"},
+			"hello",
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			f, err := ioutil.TempFile("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(f.Name())
+			if _, err := f.WriteString(tc.contents); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			var out bytes.Buffer
+			tc.i.Stdout, tc.i.Stderr = &out, &out
+
+			if err := Check(tc.i, f.Name()); (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
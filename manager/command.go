@@ -0,0 +1,353 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/consul-template/config"
+	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/template"
+	"github.com/pkg/errors"
+)
+
+// commandRender describes the render of a template to the command it runs,
+// through the environment of the command.
+type commandRender struct {
+	// templateID is the ID of the template which rendered, and destination is
+	// the path it rendered to.
+	templateID  string
+	destination string
+
+	// previousChecksum and checksum are the checksums of the destination before
+	// and after the render. previousChecksum is empty if the destination did not
+	// exist.
+	previousChecksum string
+	checksum         string
+
+	// changed is the dependencies whose data changed since the template last
+	// rendered.
+	changed []string
+
+	// data is the data of each dependency used by this render, which is
+	// remembered once the render is written.
+	data map[string]interface{}
+
+	// vault is whether the render used any Vault dependency.
+	vault bool
+}
+
+// changedDepsFile is the contents of the file named by CT_CHANGED_DEPS_FILE.
+type changedDepsFile struct {
+	Dependencies []string `json:"dependencies"`
+}
+
+// newCommandRender describes a render of the given template, comparing the data
+// of each dependency it used with the data it used when it last rendered.
+func (r *Runner) newCommandRender(tmpl *template.Template, used *dep.Set) *commandRender {
+	last := r.renderedData[tmpl.ID()]
+
+	c := &commandRender{
+		templateID: tmpl.ID(),
+		data:       make(map[string]interface{}, used.Len()),
+	}
+	for _, d := range used.List() {
+		data, _ := r.brain.Recall(d)
+		c.data[d.String()] = data
+		if d.Type() == dep.TypeVault {
+			c.vault = true
+		}
+
+		if prev, ok := last[d.String()]; !ok || !reflect.DeepEqual(prev, data) {
+			c.changed = append(c.changed, d.String())
+		}
+	}
+	sort.Strings(c.changed)
+
+	return c
+}
+
+// recordRender records the destination and contents of the render, and
+// remembers the data it used to find what changed the next time the template
+// renders.
+func (r *Runner) recordRender(c *commandRender, destination string, existing, contents []byte) {
+	c.destination = destination
+	c.previousChecksum = ""
+	if existing != nil {
+		c.previousChecksum = checksum(existing)
+	}
+	c.checksum = checksum(contents)
+
+	r.renderedData[c.templateID] = c.data
+	r.renderedVault[destination] = c.vault
+}
+
+// redactRemoved returns whether every removed line is redacted from the diff of
+// the given render to the given destination. This is the case if the render or
+// the last render to the destination used Vault, since the lines it removes may
+// hold secrets even once the template no longer reads them.
+func (r *Runner) redactRemoved(c *commandRender, destination string) bool {
+	return c.vault || r.renderedVault[destination]
+}
+
+// env writes the list of changed dependencies to a temporary file, and returns
+// the environment describing the render to its command. The returned function
+// removes the file once the command has run.
+func (c *commandRender) env() ([]string, func(), error) {
+	f, err := ioutil.TempFile("", "consul-template-changed-")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed creating changed dependencies file")
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+
+	changed := c.changed
+	if changed == nil {
+		changed = []string{}
+	}
+	err = json.NewEncoder(f).Encode(&changedDepsFile{Dependencies: changed})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, errors.Wrap(err, "failed writing changed dependencies file")
+	}
+
+	return []string{
+		fmt.Sprintf("CT_TEMPLATE_ID=%s", c.templateID),
+		fmt.Sprintf("CT_DESTINATION=%s", c.destination),
+		fmt.Sprintf("CT_PREVIOUS_CHECKSUM=%s", c.previousChecksum),
+		fmt.Sprintf("CT_CHECKSUM=%s", c.checksum),
+		fmt.Sprintf("CT_CHANGED_DEPS_FILE=%s", f.Name()),
+	}, cleanup, nil
+}
+
+// validateCommands returns an error if the failure policy of the command of any
+// of the given templates is invalid, or if two commands of a parallel group
+// use the same concurrency prefix.
+func validateCommands(templates *config.TemplateConfigs) error {
+	// The slots of every semaphore are held by a single session, in which
+	// concurrent commands with the same prefix would count as one holder.
+	prefixes := make(map[string]*config.TemplateConfig)
+	for _, t := range *templates {
+		if group := config.StringVal(t.ParallelGroup); group != "" &&
+			config.BoolVal(t.Exec.Concurrency.Enabled) {
+			key := group + "\x00" + config.StringVal(t.Exec.Concurrency.Prefix)
+			if other, ok := prefixes[key]; ok {
+				return fmt.Errorf("%s: parallel_group %q already has a command with "+
+					"concurrency prefix %q (%s)", t.Display(), group,
+					config.StringVal(t.Exec.Concurrency.Prefix), other.Display())
+			}
+			prefixes[key] = t
+		}
+
+		switch onFailure := config.StringVal(t.OnFailure); onFailure {
+		case config.OnFailureContinue, config.OnFailureStop:
+		case config.OnFailureRestoreBackup:
+			if !config.BoolVal(t.Backup) {
+				return fmt.Errorf("%s: on_failure %q requires backup", t.Display(), onFailure)
+			}
+			if config.StringPresent(t.TemplateGroup) {
+				return fmt.Errorf("%s: on_failure %q cannot be used in a template group",
+					t.Display(), onFailure)
+			}
+		default:
+			return fmt.Errorf("%s: unknown on_failure %q", t.Display(), onFailure)
+		}
+	}
+	return nil
+}
+
+// commandBatches splits the given commands into the batches they run in, in
+// order. The commands of a parallel group run together in a single batch, in
+// the position of the first of them, and every other command runs alone.
+func commandBatches(commands []*config.TemplateConfig) [][]*config.TemplateConfig {
+	var batches [][]*config.TemplateConfig
+	parallel := make(map[string]int)
+	for _, t := range commands {
+		name := config.StringVal(t.ParallelGroup)
+		if name == "" {
+			batches = append(batches, []*config.TemplateConfig{t})
+			continue
+		}
+
+		if i, ok := parallel[name]; ok {
+			batches[i] = append(batches[i], t)
+			continue
+		}
+		parallel[name] = len(batches)
+		batches = append(batches, []*config.TemplateConfig{t})
+	}
+	return batches
+}
+
+// runCommands runs the commands of the templates which rendered, in batches,
+// and returns the errors of the commands which failed. Once a failed command
+// asks to stop, the batches after it are skipped.
+func (r *Runner) runCommands(runCtx *templateRunCtx) []error {
+	var errs []error
+	batches := commandBatches(runCtx.commands)
+	for i, batch := range batches {
+		results := make([]error, len(batch))
+		var wg sync.WaitGroup
+		for j, t := range batch {
+			wg.Add(1)
+			go func(j int, t *config.TemplateConfig) {
+				defer wg.Done()
+				results[j] = r.runCommand(t, runCtx)
+			}(j, t)
+		}
+		wg.Wait()
+
+		stop := false
+		for j, err := range results {
+			if err == nil {
+				continue
+			}
+			errs = append(errs, err)
+
+			t := batch[j]
+			switch config.StringVal(t.OnFailure) {
+			case config.OnFailureStop:
+				stop = true
+			case config.OnFailureRestoreBackup:
+				if err := r.restoreBackup(t, runCtx); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+
+		if stop {
+			for _, batch := range batches[i+1:] {
+				for _, t := range batch {
+					r.logger.With("template", t.Display()).Printf("[WARN] skipping command %q from %s (previous command failed)",
+						config.StringVal(t.Exec.Command), t.Display())
+				}
+			}
+			break
+		}
+	}
+	return errs
+}
+
+// runCommand runs the command of the given template, retrying it as configured
+// if it fails.
+func (r *Runner) runCommand(t *config.TemplateConfig, runCtx *templateRunCtx) error {
+	retryFunc := t.Retry.RetryFunc()
+	for retries := 0; ; retries++ {
+		err := r.runCommandOnce(t, runCtx)
+		if err == nil {
+			return nil
+		}
+
+		retry, sleep := retryFunc(retries)
+		if !retry {
+			return err
+		}
+		r.logger.With("template", t.Display(), "retry", retries+1).Printf("[WARN] %s (retry attempt %d after %q)",
+			err, retries+1, sleep)
+		select {
+		case <-time.After(sleep):
+			metrics.IncrCounter([]string{"runner", "command", "retry"}, 1)
+		case <-r.DoneCh:
+			return err
+		}
+	}
+}
+
+// runCommandOnce runs the command of the given template a single time.
+func (r *Runner) runCommandOnce(t *config.TemplateConfig, runCtx *templateRunCtx) error {
+	command := config.StringVal(t.Exec.Command)
+
+	// Wait for a slot in the semaphore shared with other instances, so the
+	// command rolls across the cluster.
+	release := func() {}
+	if r.semaphores != nil && config.BoolVal(t.Exec.Concurrency.Enabled) {
+		var err error
+		if release, err = r.semaphores.acquire(t.Exec.Concurrency, r.DoneCh); err != nil {
+			s := fmt.Sprintf("failed to limit concurrency of command %q from %s", command, t.Display())
+			return errors.Wrap(err, s)
+		}
+	}
+	defer release()
+
+	r.logger.With("template", t.Display()).Printf("[INFO] executing command %q from %s", command, t.Display())
+	env := t.Exec.Env.Copy()
+	custom := r.childEnv()
+
+	// Describe the render to the command, if the template rendered in this
+	// run rather than another member of its group.
+	cleanup := func() {}
+	if cr, ok := runCtx.renders[t]; ok && cr.checksum != "" {
+		renderEnv, c, err := cr.env()
+		if err != nil {
+			return err
+		}
+		custom = append(custom, renderEnv...)
+		cleanup = c
+	}
+	env.Custom = append(custom, env.Custom...)
+
+	child, err := spawnChild(&spawnChildInput{
+		Stdin:        r.inStream,
+		Stdout:       r.outStream,
+		Stderr:       r.errStream,
+		Command:      command,
+		Env:          env.Env(),
+		Timeout:      config.TimeDurationVal(t.Exec.Timeout),
+		ReloadSignal: config.SignalVal(t.Exec.ReloadSignal),
+		KillSignal:   config.SignalVal(t.Exec.KillSignal),
+		KillTimeout:  config.TimeDurationVal(t.Exec.KillTimeout),
+		Splay:        config.TimeDurationVal(t.Exec.Splay),
+	})
+
+	// Without a timeout the command keeps running, and may still read the
+	// file, so it is removed once the command exits.
+	if err == nil && config.TimeDurationVal(t.Exec.Timeout) == 0 {
+		go func() {
+			<-child.ExitCh()
+			cleanup()
+		}()
+	} else {
+		cleanup()
+	}
+
+	if err != nil {
+		s := fmt.Sprintf("failed to execute command %q from %s", command, t.Display())
+		return errors.Wrap(err, s)
+	}
+	return nil
+}
+
+// restoreBackup restores the destination of the given template from the backup
+// made when it rendered. The data it rendered with is forgotten, so every
+// dependency is reported as changed the next time it renders.
+func (r *Runner) restoreBackup(t *config.TemplateConfig, runCtx *templateRunCtx) error {
+	path := config.StringVal(t.Destination)
+	if cr, ok := runCtx.renders[t]; ok {
+		if cr.destination != "" {
+			path = cr.destination
+		}
+		delete(r.renderedData, cr.templateID)
+	}
+
+	if err := copyFile(path+".bak", path); err != nil {
+		return errors.Wrapf(err, "failed restoring backup of %q", path)
+	}
+	r.logger.With("template", t.Display()).Printf("[INFO] restored %q from its backup", path)
+	return nil
+}
+
+// checksum returns the hex-encoded SHA256 checksum of the given contents.
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
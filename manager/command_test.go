@@ -0,0 +1,303 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul-template/config"
+	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/template"
+)
+
+func TestRunner_newCommandRender(t *testing.T) {
+	c := config.DefaultConfig()
+	c.Finalize()
+
+	r, err := NewRunner(c, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := template.NewTemplate(&template.NewTemplateInput{
+		Contents: `{{ file "/tmp/a" }}{{ file "/tmp/b" }}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := dep.NewFileQuery("/tmp/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := dep.NewFileQuery("/tmp/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := new(dep.Set)
+	used.Add(a)
+	used.Add(b)
+
+	r.brain.Remember(a, "a")
+	r.brain.Remember(b, "b")
+
+	// Everything changed before the first render.
+	cr := r.newCommandRender(tmpl, used)
+	if exp := []string{a.String(), b.String()}; !reflect.DeepEqual(exp, cr.changed) {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, cr.changed)
+	}
+	r.recordRender(cr, "/tmp/dest", nil, []byte("ab"))
+
+	if cr.previousChecksum != "" {
+		t.Errorf("expected %q to be empty", cr.previousChecksum)
+	}
+	if exp := checksum([]byte("ab")); cr.checksum != exp {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, cr.checksum)
+	}
+
+	// Only b changed since the last render.
+	r.brain.Remember(b, "c")
+	cr = r.newCommandRender(tmpl, used)
+	if exp := []string{b.String()}; !reflect.DeepEqual(exp, cr.changed) {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, cr.changed)
+	}
+	r.recordRender(cr, "/tmp/dest", []byte("ab"), []byte("ac"))
+
+	if exp := checksum([]byte("ab")); cr.previousChecksum != exp {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, cr.previousChecksum)
+	}
+
+	env, cleanup, err := cr.env()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path string
+	for _, e := range env {
+		if strings.HasPrefix(e, "CT_CHANGED_DEPS_FILE=") {
+			path = strings.TrimPrefix(e, "CT_CHANGED_DEPS_FILE=")
+		}
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file changedDepsFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{b.String()}; !reflect.DeepEqual(exp, file.Dependencies) {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, file.Dependencies)
+	}
+
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %q to be removed", path)
+	}
+}
+
+func TestRunner_redactRemoved(t *testing.T) {
+	c := config.DefaultConfig()
+	c.Finalize()
+
+	r, err := NewRunner(c, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := dep.NewVaultReadQuery("secret/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := dep.NewFileQuery("/tmp/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.brain.Remember(secret, &dep.Secret{Data: map[string]interface{}{"value": "s3cret"}})
+	r.brain.Remember(file, "a")
+
+	withSecret, err := template.NewTemplate(&template.NewTemplateInput{
+		Contents: `{{ with secret "secret/foo" }}{{ .Data.value }}{{ end }}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := new(dep.Set)
+	used.Add(secret)
+
+	cr := r.newCommandRender(withSecret, used)
+	if !r.redactRemoved(cr, "/tmp/dest") {
+		t.Error("expected removed lines to be redacted")
+	}
+	r.recordRender(cr, "/tmp/dest", nil, []byte("pass = s3cret\n"))
+
+	// The template no longer reads the secret, which is still in the file. Its
+	// contents changed, so it has a new ID.
+	withoutSecret, err := template.NewTemplate(&template.NewTemplateInput{
+		Contents: `{{ file "/tmp/a" }}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	used = new(dep.Set)
+	used.Add(file)
+
+	cr = r.newCommandRender(withoutSecret, used)
+	if r.redactRemoved(cr, "/tmp/other") {
+		t.Error("expected removed lines of another destination not to be redacted")
+	}
+	if !r.redactRemoved(cr, "/tmp/dest") {
+		t.Fatal("expected removed lines to be redacted")
+	}
+
+	diff, err := Diff(&DiffInput{
+		Path:          "/tmp/dest",
+		Existing:      []byte("pass = s3cret\n"),
+		Contents:      []byte("pass = a\n"),
+		Secrets:       r.secretsFor(used),
+		RedactRemoved: r.redactRemoved(cr, "/tmp/dest"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "--- /tmp/dest\n+++ /tmp/dest\n@@ -1 +1 @@\n-<redacted>\n+pass = a\n"; diff != exp {
+		t.Errorf("\nexp: %q\nact: %q", exp, diff)
+	}
+	r.recordRender(cr, "/tmp/dest", []byte("pass = s3cret\n"), []byte("pass = a\n"))
+
+	// Once the secret is gone from the file, removed lines are shown again.
+	cr = r.newCommandRender(withoutSecret, used)
+	if r.redactRemoved(cr, "/tmp/dest") {
+		t.Error("expected removed lines not to be redacted")
+	}
+}
+
+func TestValidateCommands(t *testing.T) {
+	cases := []struct {
+		name string
+		t    *config.TemplateConfig
+		err  bool
+	}{
+		{
+			"default",
+			&config.TemplateConfig{},
+			false,
+		},
+		{
+			"stop",
+			&config.TemplateConfig{OnFailure: config.String(config.OnFailureStop)},
+			false,
+		},
+		{
+			"restore_backup",
+			&config.TemplateConfig{
+				Backup:    config.Bool(true),
+				OnFailure: config.String(config.OnFailureRestoreBackup),
+			},
+			false,
+		},
+		{
+			"restore_backup_without_backup",
+			&config.TemplateConfig{OnFailure: config.String(config.OnFailureRestoreBackup)},
+			true,
+		},
+		{
+			"restore_backup_in_group",
+			&config.TemplateConfig{
+				Backup:        config.Bool(true),
+				OnFailure:     config.String(config.OnFailureRestoreBackup),
+				TemplateGroup: config.String("group"),
+			},
+			true,
+		},
+		{
+			"unknown",
+			&config.TemplateConfig{OnFailure: config.String("retry")},
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.t.Finalize()
+			err := validateCommands(&config.TemplateConfigs{tc.t})
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestValidateCommands_parallelConcurrency(t *testing.T) {
+	command := func(name, group, prefix string) *config.TemplateConfig {
+		c := &config.TemplateConfig{
+			Command:       config.String(name),
+			ParallelGroup: config.String(group),
+			Exec: &config.ExecConfig{
+				Concurrency: &config.ConcurrencyConfig{
+					Prefix: config.String(prefix),
+				},
+			},
+		}
+		c.Finalize()
+		return c
+	}
+
+	cases := []struct {
+		name      string
+		templates *config.TemplateConfigs
+		err       bool
+	}{
+		{
+			"different_prefixes",
+			&config.TemplateConfigs{command("a", "one", "foo"), command("b", "one", "bar")},
+			false,
+		},
+		{
+			"different_groups",
+			&config.TemplateConfigs{command("a", "one", "foo"), command("b", "two", "foo")},
+			false,
+		},
+		{
+			"not_parallel",
+			&config.TemplateConfigs{command("a", "", "foo"), command("b", "", "foo")},
+			false,
+		},
+		{
+			"same_prefix",
+			&config.TemplateConfigs{command("a", "one", "foo"), command("b", "one", "foo")},
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			err := validateCommands(tc.templates)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCommandBatches(t *testing.T) {
+	command := func(name, group string) *config.TemplateConfig {
+		return &config.TemplateConfig{
+			Command:       config.String(name),
+			ParallelGroup: config.String(group),
+		}
+	}
+	a, b, c := command("a", ""), command("b", "one"), command("c", "")
+	d, e, f := command("d", "two"), command("e", "one"), command("f", "two")
+
+	exp := [][]*config.TemplateConfig{{a}, {b, e}, {c}, {d, f}}
+	act := commandBatches([]*config.TemplateConfig{a, b, c, d, e, f})
+	if !reflect.DeepEqual(exp, act) {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, act)
+	}
+}
@@ -6,13 +6,14 @@ import (
 	"crypto/md5"
 	"encoding/gob"
 	"fmt"
-	"log"
 	"path"
 	"sync"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/consul-template/config"
 	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/logging"
 	"github.com/hashicorp/consul-template/template"
 	consulapi "github.com/hashicorp/consul/api"
 )
@@ -80,6 +81,9 @@ type DedupManager struct {
 	// updateCh is used to indicate an update watched data
 	updateCh chan struct{}
 
+	// logger is the logger of the de-duplication manager
+	logger *logging.Logger
+
 	// wg is used to wait for a clean shutdown
 	wg sync.WaitGroup
 
@@ -99,13 +103,14 @@ func NewDedupManager(config *config.DedupConfig, clients *dep.ClientSet, brain *
 		lastWrite: make(map[*template.Template][]byte),
 		updateCh:  make(chan struct{}, 1),
 		stopCh:    make(chan struct{}),
+		logger:    logging.New("dedup"),
 	}
 	return d, nil
 }
 
 // Start is used to start the de-duplication manager
 func (d *DedupManager) Start() error {
-	log.Printf("[INFO] (dedup) starting de-duplication manager")
+	d.logger.Printf("[INFO] starting de-duplication manager")
 
 	client := d.clients.Consul()
 	go d.createSession(client)
@@ -125,7 +130,7 @@ func (d *DedupManager) Stop() error {
 		return nil
 	}
 
-	log.Printf("[INFO] (dedup) stopping de-duplication manager")
+	d.logger.Printf("[INFO] stopping de-duplication manager")
 	d.stop = true
 	close(d.stopCh)
 	d.wg.Wait()
@@ -134,43 +139,20 @@ func (d *DedupManager) Stop() error {
 
 // createSession is used to create and maintain a session to Consul
 func (d *DedupManager) createSession(client *consulapi.Client) {
-START:
-	log.Printf("[INFO] (dedup) attempting to create session")
-	session := client.Session()
-	sessionCh := make(chan struct{})
 	ttl := fmt.Sprintf("%.6fs", float64(*d.config.TTL)/float64(time.Second))
 	se := &consulapi.SessionEntry{
 		Name:     "Consul-Template de-duplication",
 		Behavior: "delete",
 		TTL:      ttl,
 	}
-	id, _, err := session.Create(se, nil)
-	if err != nil {
-		log.Printf("[ERR] (dedup) failed to create session: %v", err)
-		goto WAIT
-	}
-	log.Printf("[INFO] (dedup) created session %s", id)
-
-	// Attempt to lock each template
-	for _, t := range d.templates {
-		d.wg.Add(1)
-		go d.attemptLock(client, id, sessionCh, t)
-	}
-
-	// Renew our session periodically
-	if err := session.RenewPeriodic("15s", id, nil, d.stopCh); err != nil {
-		log.Printf("[ERR] (dedup) failed to renew session: %v", err)
-	}
-	close(sessionCh)
-	d.wg.Wait()
-
-WAIT:
-	select {
-	case <-time.After(sessionCreateRetry):
-		goto START
-	case <-d.stopCh:
-		return
-	}
+	maintainSession(client, d.logger, se, d.stopCh, func(id string, sessionCh <-chan struct{}) {
+		// Attempt to lock each template
+		for _, t := range d.templates {
+			d.wg.Add(1)
+			go d.attemptLock(client, id, sessionCh, t)
+		}
+		d.wg.Wait()
+	})
 }
 
 // IsLeader checks if we are currently the leader instance
@@ -192,6 +174,8 @@ func (d *DedupManager) IsLeader(tmpl *template.Template) bool {
 
 // UpdateDeps is used to update the values of the dependencies for a template
 func (d *DedupManager) UpdateDeps(t *template.Template, deps []dep.Dependency) error {
+	logger := d.logger.With("template_id", t.ID())
+
 	// Calculate the path to write updates to
 	dataPath := path.Join(*d.config.Prefix, t.ID(), "data")
 
@@ -227,7 +211,7 @@ func (d *DedupManager) UpdateDeps(t *template.Template, deps []dep.Dependency) e
 	existing, ok := d.lastWrite[t]
 	d.lastWriteLock.RUnlock()
 	if ok && bytes.Equal(existing, hash[:]) {
-		log.Printf("[INFO] (dedup) de-duplicate data '%s' already current",
+		logger.Printf("[INFO] de-duplicate data '%s' already current",
 			dataPath)
 		return nil
 	}
@@ -242,7 +226,7 @@ func (d *DedupManager) UpdateDeps(t *template.Template, deps []dep.Dependency) e
 	if _, err := client.KV().Put(&kvPair, nil); err != nil {
 		return fmt.Errorf("failed to write '%s': %v", dataPath, err)
 	}
-	log.Printf("[INFO] (dedup) updated de-duplicate data '%s'", dataPath)
+	logger.Printf("[INFO] updated de-duplicate data '%s'", dataPath)
 	d.lastWriteLock.Lock()
 	d.lastWrite[t] = hash[:]
 	d.lastWriteLock.Unlock()
@@ -258,6 +242,7 @@ func (d *DedupManager) UpdateCh() <-chan struct{} {
 func (d *DedupManager) setLeader(tmpl *template.Template, lockCh <-chan struct{}) {
 	// Update the lock state
 	d.leaderLock.Lock()
+	_, wasLeader := d.leader[tmpl]
 	if lockCh != nil {
 		d.leader[tmpl] = lockCh
 	} else {
@@ -265,6 +250,16 @@ func (d *DedupManager) setLeader(tmpl *template.Template, lockCh <-chan struct{}
 	}
 	d.leaderLock.Unlock()
 
+	// Record leadership transitions
+	labels := []metrics.Label{{Name: "template", Value: tmpl.ID()}}
+	if lockCh != nil {
+		metrics.SetGaugeWithLabels([]string{"dedup", "leader"}, 1, labels)
+		metrics.IncrCounterWithLabels([]string{"dedup", "leader", "acquired"}, 1, labels)
+	} else if wasLeader {
+		metrics.SetGaugeWithLabels([]string{"dedup", "leader"}, 0, labels)
+		metrics.IncrCounterWithLabels([]string{"dedup", "leader", "lost"}, 1, labels)
+	}
+
 	// Clear the lastWrite hash if we've lost leadership
 	if lockCh == nil {
 		d.lastWriteLock.Lock()
@@ -280,7 +275,8 @@ func (d *DedupManager) setLeader(tmpl *template.Template, lockCh <-chan struct{}
 }
 
 func (d *DedupManager) watchTemplate(client *consulapi.Client, t *template.Template) {
-	log.Printf("[INFO] (dedup) starting watch for template hash %s", t.ID())
+	logger := d.logger.With("template_id", t.ID())
+	logger.Printf("[INFO] starting watch for template hash %s", t.ID())
 	path := path.Join(*d.config.Prefix, t.ID(), "data")
 
 	// Determine if stale queries are allowed
@@ -320,10 +316,10 @@ START:
 	}
 
 	// Block for updates on the data key
-	log.Printf("[INFO] (dedup) listing data for template hash %s", t.ID())
+	logger.Printf("[INFO] listing data for template hash %s", t.ID())
 	pair, meta, err := client.KV().Get(path, opts)
 	if err != nil {
-		log.Printf("[ERR] (dedup) failed to get '%s': %v", path, err)
+		logger.Printf("[ERR] failed to get '%s': %v", path, err)
 		select {
 		case <-time.After(listRetry):
 			goto START
@@ -343,7 +339,7 @@ START:
 	// If we've exceeded the maximum staleness, retry without stale
 	if allowStale && meta.LastContact > *d.config.MaxStale {
 		allowStale = false
-		log.Printf("[DEBUG] (dedup) %s stale data (last contact exceeded max_stale)", path)
+		logger.Printf("[DEBUG] %s stale data (last contact exceeded max_stale)", path)
 		goto START
 	}
 
@@ -353,12 +349,12 @@ START:
 	}
 
 	if meta.LastIndex == lastIndex {
-		log.Printf("[TRACE] (dedup) %s no new data (index was the same)", path)
+		logger.With("index", meta.LastIndex).Printf("[TRACE] %s no new data (index was the same)", path)
 		goto START
 	}
 
 	if meta.LastIndex < lastIndex {
-		log.Printf("[TRACE] (dedup) %s had a lower index, resetting", path)
+		logger.With("index", meta.LastIndex).Printf("[TRACE] %s had a lower index, resetting", path)
 		lastIndex = 0
 		goto START
 	}
@@ -369,7 +365,7 @@ START:
 		data = pair.Value
 	}
 	if bytes.Equal(lastData, data) {
-		log.Printf("[TRACE] (dedup) %s no new data (contents were the same)", path)
+		logger.Printf("[TRACE] %s no new data (contents were the same)", path)
 		goto START
 	}
 	lastData = data
@@ -405,11 +401,11 @@ func (d *DedupManager) parseData(path string, raw []byte) {
 	// Decode the data
 	var td templateData
 	if err := dec.Decode(&td); err != nil {
-		log.Printf("[ERR] (dedup) failed to decode '%s': %v",
+		d.logger.Printf("[ERR] failed to decode '%s': %v",
 			path, err)
 		return
 	}
-	log.Printf("[INFO] (dedup) loading %d dependencies from '%s'",
+	d.logger.Printf("[INFO] loading %d dependencies from '%s'",
 		len(td.Data), path)
 
 	// Update the data in the brain
@@ -424,10 +420,11 @@ func (d *DedupManager) parseData(path string, raw []byte) {
 	}
 }
 
-func (d *DedupManager) attemptLock(client *consulapi.Client, session string, sessionCh chan struct{}, t *template.Template) {
+func (d *DedupManager) attemptLock(client *consulapi.Client, session string, sessionCh <-chan struct{}, t *template.Template) {
 	defer d.wg.Done()
+	logger := d.logger.With("template_id", t.ID())
 	for {
-		log.Printf("[INFO] (dedup) attempting lock for template hash %s", t.ID())
+		logger.Printf("[INFO] attempting lock for template hash %s", t.ID())
 		basePath := path.Join(*d.config.Prefix, t.ID())
 		lopts := &consulapi.LockOptions{
 			Key:              path.Join(basePath, "lock"),
@@ -437,7 +434,7 @@ func (d *DedupManager) attemptLock(client *consulapi.Client, session string, ses
 		}
 		lock, err := client.LockOpts(lopts)
 		if err != nil {
-			log.Printf("[ERR] (dedup) failed to create lock '%s': %v",
+			logger.Printf("[ERR] failed to create lock '%s': %v",
 				lopts.Key, err)
 			return
 		}
@@ -445,11 +442,11 @@ func (d *DedupManager) attemptLock(client *consulapi.Client, session string, ses
 		var retryCh <-chan time.Time
 		leaderCh, err := lock.Lock(sessionCh)
 		if err != nil {
-			log.Printf("[ERR] (dedup) failed to acquire lock '%s': %v",
+			logger.Printf("[ERR] failed to acquire lock '%s': %v",
 				lopts.Key, err)
 			retryCh = time.After(lockRetry)
 		} else {
-			log.Printf("[INFO] (dedup) acquired lock '%s'", lopts.Key)
+			logger.Printf("[INFO] acquired lock '%s'", lopts.Key)
 			d.setLeader(t, leaderCh)
 		}
 
@@ -458,16 +455,16 @@ func (d *DedupManager) attemptLock(client *consulapi.Client, session string, ses
 			retryCh = nil
 			continue
 		case <-leaderCh:
-			log.Printf("[WARN] (dedup) lost lock ownership '%s'", lopts.Key)
+			logger.Printf("[WARN] lost lock ownership '%s'", lopts.Key)
 			d.setLeader(t, nil)
 			continue
 		case <-sessionCh:
-			log.Printf("[INFO] (dedup) releasing session '%s'", lopts.Key)
+			logger.Printf("[INFO] releasing session '%s'", lopts.Key)
 			d.setLeader(t, nil)
 			lock.Unlock()
 			return
 		case <-d.stopCh:
-			log.Printf("[INFO] (dedup) releasing lock '%s'", lopts.Key)
+			logger.Printf("[INFO] releasing lock '%s'", lopts.Key)
 			lock.Unlock()
 			return
 		}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/telemetry"
 	"github.com/hashicorp/consul-template/template"
 )
 
@@ -163,3 +164,36 @@ func TestDedup_FollowerUpdate(t *testing.T) {
 		t.Fatalf("bad: %v", data)
 	}
 }
+
+func TestDedup_setLeaderMetrics(t *testing.T) {
+	sink := telemetry.NewTestSink()
+
+	tmpl, err := template.NewTemplate(&template.NewTemplateInput{
+		Contents: `template-metrics`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dedup := testDedupManager(t, []*template.Template{tmpl})
+	dedup.setLeader(tmpl, make(chan struct{}))
+	dedup.setLeader(tmpl, nil)
+
+	gauges := sink.Metrics("dedup.leader")
+	if len(gauges) != 2 {
+		t.Fatalf("expected %d to be %d", len(gauges), 2)
+	}
+	if gauges[0].Value != 1 || gauges[1].Value != 0 {
+		t.Errorf("bad gauges %#v", gauges)
+	}
+	if gauges[0].Labels["template"] != tmpl.ID() {
+		t.Errorf("expected %q to be %q", gauges[0].Labels["template"], tmpl.ID())
+	}
+
+	if l := len(sink.Metrics("dedup.leader.acquired")); l != 1 {
+		t.Errorf("expected %d to be %d", l, 1)
+	}
+	if l := len(sink.Metrics("dedup.leader.lost")); l != 1 {
+		t.Errorf("expected %d to be %d", l, 1)
+	}
+}
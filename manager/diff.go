@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around each change.
+	diffContext = 3
+
+	// redacted replaces secret values in a diff.
+	redacted = "<redacted>"
+)
+
+// DiffInput is used as input to the diff function.
+type DiffInput struct {
+	// Path is the destination of the template, used in the diff header.
+	Path string
+
+	// Existing and Contents are the contents of the file on disk, which may be
+	// empty, and the new contents of the template.
+	Existing []byte
+	Contents []byte
+
+	// Secrets are values which must not appear in the diff, such as those read
+	// from Vault.
+	Secrets []string
+
+	// RedactRemoved redacts every removed line even if there are no secrets,
+	// for a file whose previous contents were rendered with secrets.
+	RedactRemoved bool
+}
+
+// Diff returns a unified diff between the existing and new contents of a file,
+// or an empty string if they are the same.
+//
+// Each secret is replaced wherever it appears in either version. Since the
+// previous values of the secrets are not known, and a removed line may hold one
+// in any form, every removed line is also redacted if there are any secrets, or
+// if RedactRemoved is set.
+func Diff(i *DiffInput) (string, error) {
+	secrets := make([]string, 0, len(i.Secrets))
+	for _, s := range i.Secrets {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+
+	// Replace the longest secrets first, in case one holds another.
+	sort.Slice(secrets, func(a, b int) bool {
+		return len(secrets[a]) > len(secrets[b])
+	})
+
+	redact := func(s string) string {
+		for _, secret := range secrets {
+			s = strings.Replace(s, secret, redacted, -1)
+		}
+		return s
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(redact(string(i.Existing))),
+		B:        splitLines(redact(string(i.Contents))),
+		FromFile: i.Path,
+		ToFile:   i.Path,
+		Context:  diffContext,
+	})
+	if err != nil || diff == "" || (len(secrets) == 0 && !i.RedactRemoved) {
+		return diff, err
+	}
+
+	// The first two lines are the header.
+	lines := strings.SplitAfter(diff, "\n")
+	for j := 2; j < len(lines); j++ {
+		if strings.HasPrefix(lines[j], "-") {
+			lines[j] = "-" + redacted + "\n"
+		}
+	}
+
+	return strings.Join(lines, ""), nil
+}
+
+// splitLines splits the contents of a file into lines, each ending with a
+// newline. Unlike difflib.SplitLines, no empty line is added after a trailing
+// newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// secretValues returns the values held by the data of a Vault dependency, which
+// must be redacted from diffs.
+func secretValues(data interface{}) []string {
+	s, ok := data.(*dep.Secret)
+	if !ok || s == nil {
+		return nil
+	}
+
+	result := stringValues(reflect.ValueOf(s.Data), nil)
+	if s.Auth != nil {
+		result = append(result, s.Auth.ClientToken)
+	}
+	if s.WrapInfo != nil {
+		result = append(result, s.WrapInfo.Token)
+	}
+
+	return result
+}
+
+// stringValues appends every non-empty string held by the given value, which
+// may be nested in maps, slices and interfaces.
+func stringValues(v reflect.Value, result []string) []string {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if !v.IsNil() {
+			result = stringValues(v.Elem(), result)
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			result = stringValues(v.MapIndex(k), result)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			result = stringValues(v.Index(i), result)
+		}
+	case reflect.String:
+		if s := v.String(); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package manager
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	dep "github.com/hashicorp/consul-template/dependency"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing string
+		contents string
+		secrets  []string
+		exp      string
+	}{
+		{
+			"same",
+			"a\nb\n",
+			"a\nb\n",
+			nil,
+			"",
+		},
+		{
+			"new_file",
+			"",
+			"a\n",
+			nil,
+			"--- /tmp/f\n+++ /tmp/f\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			"changed",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			nil,
+			"--- /tmp/f\n+++ /tmp/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"context",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			nil,
+			"--- /tmp/f\n+++ /tmp/f\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"redacts_secrets",
+			"user = bob\nport = 80\npass = old\n",
+			"user = alice\nport = 80\npass = s3cret\n",
+			[]string{"s3cret"},
+			"--- /tmp/f\n+++ /tmp/f\n@@ -1,3 +1,3 @@\n" +
+				"-<redacted>\n+user = alice\n port = 80\n-<redacted>\n+pass = <redacted>\n",
+		},
+		{
+			"redacts_removed_lines",
+			"a\npass = old\n",
+			"a\n",
+			[]string{"s3cret"},
+			"--- /tmp/f\n+++ /tmp/f\n@@ -1,2 +1 @@\n a\n-<redacted>\n",
+		},
+		{
+			"redacts_unchanged_secrets",
+			"pass = s3cret\nport = 80\n",
+			"pass = s3cret\nport = 81\n",
+			[]string{"s3cret"},
+			"--- /tmp/f\n+++ /tmp/f\n@@ -1,2 +1,2 @@\n pass = <redacted>\n-<redacted>\n+port = 81\n",
+		},
+		{
+			"longest_secret_first",
+			"",
+			"abcd\n",
+			[]string{"", "ab", "abcd"},
+			"--- /tmp/f\n+++ /tmp/f\n@@ -0,0 +1 @@\n+<redacted>\n",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act, err := Diff(&DiffInput{
+				Path:     "/tmp/f",
+				Existing: []byte(tc.existing),
+				Contents: []byte(tc.contents),
+				Secrets:  tc.secrets,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act != tc.exp {
+				t.Errorf("\nexp: %q\nact: %q", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestSecretValues(t *testing.T) {
+	cases := []struct {
+		name string
+		data interface{}
+		exp  []string
+	}{
+		{
+			"not_secret",
+			[]string{"a"},
+			nil,
+		},
+		{
+			"nil",
+			(*dep.Secret)(nil),
+			nil,
+		},
+		{
+			"secret",
+			&dep.Secret{
+				LeaseID: "lease",
+				Data: map[string]interface{}{
+					"empty":  "",
+					"number": 1,
+					"nested": map[string]interface{}{"list": []interface{}{"b", "c"}},
+					"value":  "a",
+				},
+				Auth:     &dep.SecretAuth{ClientToken: "token"},
+				WrapInfo: &dep.SecretWrapInfo{Token: "wrapped"},
+			},
+			[]string{"a", "b", "c", "token", "wrapped"},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act := secretValues(tc.data)
+			sort.Strings(act)
+			if !reflect.DeepEqual(act, tc.exp) {
+				t.Errorf("expected %v to be %v", act, tc.exp)
+			}
+		})
+	}
+}
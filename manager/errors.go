@@ -1,6 +1,10 @@
 package manager
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
 
 // ErrExitable is an interface that defines an integer ExitStatus() function.
 type ErrExitable interface {
@@ -29,3 +33,87 @@ func (e *ErrChildDied) Error() string {
 func (e *ErrChildDied) ExitStatus() int {
 	return e.code
 }
+
+var _ error = new(ErrCheckFailed)
+
+// ErrCheckFailed is the error returned when a rendered template fails its
+// check. The file on disk is left untouched.
+type ErrCheckFailed struct {
+	path string
+	err  error
+}
+
+// NewErrCheckFailed creates a new error for the given destination path and
+// the error returned by the check.
+func NewErrCheckFailed(path string, err error) *ErrCheckFailed {
+	return &ErrCheckFailed{path: path, err: err}
+}
+
+// Error implements the error interface.
+func (e *ErrCheckFailed) Error() string {
+	return fmt.Sprintf("check failed for %s: %s", e.path, e.err)
+}
+
+var _ error = new(ErrRenderTimeout)
+
+// ErrRenderTimeout is the error returned when templates have not rendered for
+// the first time before their render timeout. It reports the dependencies each
+// template is still waiting for, and encodes to JSON for machine consumers.
+type ErrRenderTimeout struct {
+	Templates []*RenderTimeoutReport `json:"templates"`
+}
+
+// RenderTimeoutReport describes a template which did not render before its
+// render timeout.
+type RenderTimeoutReport struct {
+	// ID is the unique ID of the template, and Source and Destinations are as
+	// reported by the status endpoint.
+	ID           string   `json:"id"`
+	Source       string   `json:"source"`
+	Destinations []string `json:"destinations"`
+
+	// Timeout is the render timeout of the template.
+	Timeout string `json:"timeout"`
+
+	// MissingDeps is the list of dependencies no data has been received for,
+	// and UnwatchedDeps is the list of dependencies the watcher has not started
+	// querying for.
+	MissingDeps   []string `json:"missing_deps"`
+	UnwatchedDeps []string `json:"unwatched_deps"`
+}
+
+// Error implements the error interface.
+func (e *ErrRenderTimeout) Error() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d template(s) did not render before the render timeout", len(e.Templates))
+	for _, t := range e.Templates {
+		fmt.Fprintf(&b, "\n\n  %s => %s (after %s)",
+			t.Source, strings.Join(t.Destinations, ", "), t.Timeout)
+		for _, d := range t.MissingDeps {
+			fmt.Fprintf(&b, "\n    missing: %s", d)
+		}
+		for _, d := range t.UnwatchedDeps {
+			fmt.Fprintf(&b, "\n    unwatched: %s", d)
+		}
+	}
+	return b.String()
+}
+
+var _ error = new(ErrReloadFailed)
+
+// ErrReloadFailed is the error returned when a configuration could not be
+// reloaded. The runner keeps running with its previous configuration.
+type ErrReloadFailed struct {
+	err error
+}
+
+// NewErrReloadFailed creates a new error for the error which prevented the
+// reload.
+func NewErrReloadFailed(err error) *ErrReloadFailed {
+	return &ErrReloadFailed{err: err}
+}
+
+// Error implements the error interface.
+func (e *ErrReloadFailed) Error() string {
+	return fmt.Sprintf("failed to reload configuration: %s", e.err)
+}
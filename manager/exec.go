@@ -0,0 +1,485 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/consul-template/child"
+	"github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/logging"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// execChild is a process supervised in exec mode. All fields other than child
+// are only used from the runner's goroutine.
+type execChild struct {
+	config *config.ExecConfig
+
+	// templates is the set of templates whose changes reload this process. It
+	// is nil if a change to any template reloads it.
+	templates map[*config.TemplateConfig]struct{}
+
+	// child is the running process, or nil if it has not been started yet. It
+	// is protected by the runner's child lock.
+	child *child.Child
+
+	// exitCh is the exit channel of the current process. Exits reported from
+	// any other channel are from a process which was replaced, and are ignored.
+	exitCh <-chan int
+
+	// started is when the process was last started, and restartAttempts is the
+	// number of consecutive times it has been restarted after exiting.
+	// restarting is true while a restart is waiting for its backoff.
+	started         time.Time
+	restartAttempts int
+	restarting      bool
+
+	// prober probes the liveness of the process. This may be nil.
+	prober *prober
+
+	// removed is true once the process is no longer supervised, after a reload
+	// changed its exec configuration.
+	removed bool
+}
+
+// childEventKind is the kind of a childEvent.
+type childEventKind int
+
+const (
+	childExited childEventKind = iota
+	childRestart
+	childProbeFailed
+)
+
+// childEvent is sent to the runner when something happens to a supervised
+// process.
+type childEvent struct {
+	kind  childEventKind
+	child *execChild
+
+	// exitCh and code are the channel the exit was reported on, and the exit
+	// code of the process.
+	exitCh <-chan int
+	code   int
+}
+
+// newExecChildren creates the processes to supervise in exec mode from the
+// given configuration. The default exec configuration comes first, if it has a
+// command, followed by each exec block in order.
+func newExecChildren(c *config.Config) ([]*execChild, error) {
+	var execs []*config.ExecConfig
+	if config.StringPresent(c.Exec.Command) {
+		execs = append(execs, c.Exec)
+	}
+	if c.Execs != nil {
+		for _, e := range *c.Execs {
+			if !config.StringPresent(e.Command) {
+				return nil, fmt.Errorf("exec %s: missing command", e.Display())
+			}
+			execs = append(execs, e)
+		}
+	}
+
+	destinations := make(map[string]*config.TemplateConfig)
+	if c.Templates != nil {
+		for _, t := range *c.Templates {
+			if dest := config.StringVal(t.Destination); dest != "" {
+				destinations[dest] = t
+			}
+		}
+	}
+
+	names := make(map[string]struct{})
+	result := make([]*execChild, 0, len(execs))
+	for _, e := range execs {
+		if name := config.StringVal(e.Name); name != "" {
+			if _, ok := names[name]; ok {
+				return nil, fmt.Errorf("exec %s: duplicate name", e.Display())
+			}
+			names[name] = struct{}{}
+		}
+
+		ec := &execChild{config: e}
+
+		if len(e.Templates) > 0 {
+			ec.templates = make(map[*config.TemplateConfig]struct{}, len(e.Templates))
+			for _, dest := range e.Templates {
+				t, ok := destinations[dest]
+				if !ok {
+					return nil, fmt.Errorf("exec %s: unknown template %q", e.Display(), dest)
+				}
+				ec.templates[t] = struct{}{}
+			}
+		}
+
+		if config.BoolVal(e.LivenessProbe.Enabled) {
+			p, err := newProber(e.LivenessProbe)
+			if err != nil {
+				return nil, fmt.Errorf("exec %s: %s", e.Display(), err)
+			}
+			p.logger = p.logger.With("exec", e.Display())
+			ec.prober = p
+		}
+
+		result = append(result, ec)
+	}
+
+	return result, nil
+}
+
+// childReload is how a process is reloaded after templates change. A restart
+// is the strongest action, and replaces any signals.
+type childReload struct {
+	restart bool
+	signals []os.Signal
+}
+
+// reloadFor returns how this process is reloaded after the given templates
+// rendered, combining what each template asks for. It returns nil if none of
+// them affect the process.
+func (c *execChild) reloadFor(rendered []*config.TemplateConfig) *childReload {
+	var result *childReload
+	for _, t := range rendered {
+		if c.templates != nil {
+			if _, ok := c.templates[t]; !ok {
+				continue
+			}
+		}
+		if !config.BoolVal(t.ReloadChild) {
+			continue
+		}
+
+		if result == nil {
+			result = &childReload{}
+		}
+		if config.BoolVal(t.RestartChild) {
+			result.restart = true
+			continue
+		}
+
+		s := config.SignalVal(t.ReloadChildSignal)
+		if s == nil {
+			s = config.SignalVal(c.config.ReloadSignal)
+		}
+
+		// Without a reload signal, the process is restarted.
+		if s == nil {
+			result.restart = true
+			continue
+		}
+
+		found := false
+		for _, existing := range result.signals {
+			if existing == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.signals = append(result.signals, s)
+		}
+	}
+	return result
+}
+
+// childLogger returns the logger for lines about the given process.
+func (r *Runner) childLogger(c *execChild) *logging.Logger {
+	return r.logger.With("exec", c.config.Display())
+}
+
+// startChildren spawns each supervised process which is not running yet, in
+// order.
+func (r *Runner) startChildren() error {
+	r.childLock.Lock()
+	defer r.childLock.Unlock()
+
+	for _, c := range r.children {
+		if c.child != nil {
+			continue
+		}
+
+		r.childLogger(c).Printf("[INFO] starting child process %s", c.config.Display())
+
+		env := c.config.Env.Copy()
+		env.Custom = append(r.childEnv(), env.Custom...)
+		ch, err := spawnChild(&spawnChildInput{
+			Stdin:        r.inStream,
+			Stdout:       r.outStream,
+			Stderr:       r.errStream,
+			Command:      config.StringVal(c.config.Command),
+			Env:          env.Env(),
+			ReloadSignal: config.SignalVal(c.config.ReloadSignal),
+			KillSignal:   config.SignalVal(c.config.KillSignal),
+			KillTimeout:  config.TimeDurationVal(c.config.KillTimeout),
+			Splay:        config.TimeDurationVal(c.config.Splay),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "exec %s", c.config.Display())
+		}
+		c.child = ch
+		c.started = time.Now()
+		r.watchChild(c)
+
+		if c.prober != nil {
+			c.prober.start(env.Env(), r.outStream, r.errStream)
+			go r.forwardProbe(c)
+		}
+	}
+
+	return nil
+}
+
+// watchChild watches the exit channel of the process, if it changed since it
+// was last watched. This must be called whenever the process may have been
+// replaced.
+func (r *Runner) watchChild(c *execChild) {
+	exitCh := c.child.ExitCh()
+	if exitCh == nil || exitCh == c.exitCh {
+		return
+	}
+	c.exitCh = exitCh
+
+	go func() {
+		select {
+		case code := <-exitCh:
+			r.sendChildEvent(&childEvent{
+				kind:   childExited,
+				child:  c,
+				exitCh: exitCh,
+				code:   code,
+			})
+		case <-r.DoneCh:
+		}
+	}()
+}
+
+// forwardProbe reports each failed liveness probe of the process to the
+// runner.
+func (r *Runner) forwardProbe(c *execChild) {
+	for {
+		select {
+		case <-c.prober.FailCh():
+			r.sendChildEvent(&childEvent{kind: childProbeFailed, child: c})
+		case <-c.prober.stopCh:
+			return
+		case <-r.DoneCh:
+			return
+		}
+	}
+}
+
+// sendChildEvent sends the event to the runner, unless it is stopped.
+func (r *Runner) sendChildEvent(e *childEvent) {
+	select {
+	case r.childEventCh <- e:
+	case <-r.DoneCh:
+	}
+}
+
+// handleChildEvent handles something which happened to a supervised process.
+// An ErrChildDied is returned if a critical process exited and will not be
+// restarted.
+func (r *Runner) handleChildEvent(e *childEvent) error {
+	c := e.child
+	if c.removed {
+		return nil
+	}
+
+	switch e.kind {
+	case childExited:
+		if e.exitCh != c.exitCh {
+			r.childLogger(c).Printf("[DEBUG] ignoring exit of replaced child process %s",
+				c.config.Display())
+			return nil
+		}
+		c.exitCh = nil
+
+		r.childLogger(c).Printf("[INFO] child process %s died", c.config.Display())
+		metrics.IncrCounterWithLabels([]string{"runner", "exec", "exit"}, 1, exitCodeLabels(e.code))
+
+		// A process which failed its liveness probe may exit while its restart
+		// waits for the backoff.
+		if c.restarting {
+			return nil
+		}
+		return r.childDied(c, e.code)
+
+	case childRestart:
+		c.restarting = false
+		if err := r.restartChild(c); err != nil {
+			r.childLogger(c).Printf("[ERR] failed to restart child process %s: %s",
+				c.config.Display(), err)
+			return r.childDied(c, child.ExitCodeError)
+		}
+
+	case childProbeFailed:
+		// A restart is already on its way.
+		if c.restarting {
+			return nil
+		}
+
+		r.childLogger(c).Printf("[WARN] child process %s failed liveness probe",
+			c.config.Display())
+		metrics.IncrCounter([]string{"runner", "exec", "probe_failed"}, 1)
+
+		// The process is treated as if it died with an error, so its restart
+		// policy decides if and when it is restarted. If it is not, the process
+		// is stopped, since it is no longer healthy.
+		err := r.childDied(c, child.ExitCodeError)
+		if !c.restarting {
+			r.stopChild(c)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// childDied decides if the process should be restarted after it exited with
+// the given code, according to its restart policy, and schedules the restart.
+// If it should not be restarted, an ErrChildDied is returned for a critical
+// process.
+func (r *Runner) childDied(c *execChild, code int) error {
+	restart := c.config.Restart
+
+	// A child which stayed up long enough is healthy, so its restarts begin
+	// the backoff again.
+	if max := config.TimeDurationVal(restart.MaxBackoff); max > 0 && time.Since(c.started) >= max {
+		c.restartAttempts = 0
+	}
+
+	ok, sleep := restart.RestartFunc()(code, c.restartAttempts)
+	if !ok {
+		if c.restartAttempts > 0 {
+			r.childLogger(c).Printf("[ERR] child process %s restarted %d times, giving up",
+				c.config.Display(), c.restartAttempts)
+		}
+		if config.BoolVal(c.config.Critical) {
+			return NewErrChildDied(code)
+		}
+		r.childLogger(c).Printf("[WARN] child process %s is not critical, continuing",
+			c.config.Display())
+		return nil
+	}
+
+	c.restartAttempts++
+	c.restarting = true
+	r.childLogger(c).Printf("[INFO] restarting child process %s in %s (attempt %d)",
+		c.config.Display(), sleep, c.restartAttempts)
+	time.AfterFunc(sleep, func() {
+		r.sendChildEvent(&childEvent{kind: childRestart, child: c})
+	})
+	return nil
+}
+
+// restartChild kills the process, if it is still running, and starts it again.
+func (r *Runner) restartChild(c *execChild) error {
+	r.childLock.Lock()
+	defer r.childLock.Unlock()
+
+	if err := c.child.Restart(); err != nil {
+		return err
+	}
+	c.started = time.Now()
+	r.watchChild(c)
+	metrics.IncrCounter([]string{"runner", "exec", "restart"}, 1)
+	return nil
+}
+
+// stopChild stops the process and its liveness probe. Its exit is not reported.
+func (r *Runner) stopChild(c *execChild) {
+	r.childLogger(c).Printf("[INFO] stopping child process %s", c.config.Display())
+	c.exitCh = nil
+	if c.prober != nil {
+		c.prober.stop()
+	}
+
+	r.childLock.RLock()
+	defer r.childLock.RUnlock()
+	c.child.Stop()
+}
+
+// reloadChildren reloads each supervised process which is affected by any of
+// the given templates, applying the strongest action they ask for.
+func (r *Runner) reloadChildren(rendered []*config.TemplateConfig) error {
+	r.childLock.RLock()
+	defer r.childLock.RUnlock()
+
+	var errs *multierror.Error
+	for _, c := range r.children {
+		if c.child == nil {
+			continue
+		}
+
+		reload := c.reloadFor(rendered)
+		if reload == nil {
+			continue
+		}
+
+		if reload.restart {
+			r.childLogger(c).Printf("[INFO] restarting child process %s", c.config.Display())
+			if err := c.child.Restart(); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		} else {
+			for _, s := range reload.signals {
+				r.childLogger(c).Printf("[INFO] sending %s to child process %s", s, c.config.Display())
+				if err := c.child.ReloadWith(s); err != nil {
+					errs = multierror.Append(errs, err)
+				}
+			}
+		}
+
+		// A restart replaces the process.
+		r.watchChild(c)
+	}
+	return errs.ErrorOrNil()
+}
+
+// waitChildren waits in once mode for the supervised processes to exit. An
+// ErrChildDied is returned as soon as a critical process exits.
+func (r *Runner) waitChildren() error {
+	running := len(r.children)
+	for running > 0 {
+		select {
+		case e := <-r.childEventCh:
+			c := e.child
+			if e.kind != childExited || e.exitCh != c.exitCh {
+				continue
+			}
+			c.exitCh = nil
+			running--
+
+			r.childLogger(c).Printf("[INFO] child process %s died", c.config.Display())
+			metrics.IncrCounterWithLabels([]string{"runner", "exec", "exit"}, 1, exitCodeLabels(e.code))
+			if config.BoolVal(c.config.Critical) {
+				return NewErrChildDied(e.code)
+			}
+		case <-r.DoneCh:
+			return nil
+		}
+	}
+	return nil
+}
+
+// stopChildren stops each supervised process, in the reverse of the order they
+// were started.
+func (r *Runner) stopChildren() {
+	r.childLock.RLock()
+	defer r.childLock.RUnlock()
+
+	for i := len(r.children) - 1; i >= 0; i-- {
+		c := r.children[i]
+		if c.prober != nil {
+			r.childLogger(c).Printf("[DEBUG] stopping liveness probe of %s", c.config.Display())
+			c.prober.stop()
+		}
+		if c.child != nil {
+			r.childLogger(c).Printf("[DEBUG] stopping child process %s", c.config.Display())
+			c.child.Stop()
+		}
+	}
+}
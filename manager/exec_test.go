@@ -0,0 +1,210 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestNewExecChildren(t *testing.T) {
+	cases := []struct {
+		name     string
+		c        *config.Config
+		children int
+		err      bool
+	}{
+		{
+			"none",
+			&config.Config{},
+			0,
+			false,
+		},
+		{
+			"exec",
+			&config.Config{
+				Exec: &config.ExecConfig{Command: config.String("a")},
+			},
+			1,
+			false,
+		},
+		{
+			"execs",
+			&config.Config{
+				Exec: &config.ExecConfig{Command: config.String("a")},
+				Execs: &config.ExecConfigs{
+					&config.ExecConfig{Command: config.String("b"), Name: config.String("b")},
+					&config.ExecConfig{Command: config.String("c"), Name: config.String("c")},
+				},
+			},
+			3,
+			false,
+		},
+		{
+			"missing_command",
+			&config.Config{
+				Execs: &config.ExecConfigs{
+					&config.ExecConfig{Name: config.String("a")},
+				},
+			},
+			0,
+			true,
+		},
+		{
+			"duplicate_name",
+			&config.Config{
+				Execs: &config.ExecConfigs{
+					&config.ExecConfig{Command: config.String("a"), Name: config.String("a")},
+					&config.ExecConfig{Command: config.String("b"), Name: config.String("a")},
+				},
+			},
+			0,
+			true,
+		},
+		{
+			"templates",
+			&config.Config{
+				Exec: &config.ExecConfig{
+					Command:   config.String("a"),
+					Templates: []string{"/tmp/a"},
+				},
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{Destination: config.String("/tmp/a")},
+				},
+			},
+			1,
+			false,
+		},
+		{
+			"unknown_template",
+			&config.Config{
+				Exec: &config.ExecConfig{
+					Command:   config.String("a"),
+					Templates: []string{"/tmp/b"},
+				},
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{Destination: config.String("/tmp/a")},
+				},
+			},
+			0,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.c.Finalize()
+			children, err := newExecChildren(tc.c)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+			if len(children) != tc.children {
+				t.Errorf("expected %d to be %d", len(children), tc.children)
+			}
+		})
+	}
+}
+
+func TestExecChild_reloadFor(t *testing.T) {
+	template := func(dest string, t *config.TemplateConfig) *config.TemplateConfig {
+		t.Destination = config.String(dest)
+		t.Finalize()
+		return t
+	}
+
+	a := template("/tmp/a", &config.TemplateConfig{})
+	b := template("/tmp/b", &config.TemplateConfig{})
+	none := template("/tmp/none", &config.TemplateConfig{
+		ReloadChild: config.Bool(false),
+	})
+	quit := template("/tmp/quit", &config.TemplateConfig{
+		ReloadChildSignal: config.Signal(syscall.SIGQUIT),
+	})
+	term := template("/tmp/term", &config.TemplateConfig{
+		ReloadChildSignal: config.Signal(syscall.SIGTERM),
+	})
+	restart := template("/tmp/restart", &config.TemplateConfig{
+		RestartChild: config.Bool(true),
+	})
+
+	cases := []struct {
+		name      string
+		signal    os.Signal
+		templates map[*config.TemplateConfig]struct{}
+		rendered  []*config.TemplateConfig
+		exp       *childReload
+	}{
+		{
+			"none_rendered",
+			syscall.SIGHUP,
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"all",
+			syscall.SIGHUP,
+			nil,
+			[]*config.TemplateConfig{b},
+			&childReload{signals: []os.Signal{syscall.SIGHUP}},
+		},
+		{
+			"no_reload_signal",
+			nil,
+			nil,
+			[]*config.TemplateConfig{a},
+			&childReload{restart: true},
+		},
+		{
+			"scoped",
+			syscall.SIGHUP,
+			map[*config.TemplateConfig]struct{}{a: {}},
+			[]*config.TemplateConfig{a},
+			&childReload{signals: []os.Signal{syscall.SIGHUP}},
+		},
+		{
+			"scoped_other",
+			syscall.SIGHUP,
+			map[*config.TemplateConfig]struct{}{a: {}},
+			[]*config.TemplateConfig{b},
+			nil,
+		},
+		{
+			"reload_child_false",
+			syscall.SIGHUP,
+			nil,
+			[]*config.TemplateConfig{none},
+			nil,
+		},
+		{
+			"signals",
+			syscall.SIGHUP,
+			nil,
+			[]*config.TemplateConfig{quit, a, term, quit},
+			&childReload{signals: []os.Signal{syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGTERM}},
+		},
+		{
+			"restart_strongest",
+			syscall.SIGHUP,
+			nil,
+			[]*config.TemplateConfig{quit, restart, a},
+			&childReload{restart: true, signals: []os.Signal{syscall.SIGQUIT, syscall.SIGHUP}},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			c := &execChild{
+				config:    &config.ExecConfig{ReloadSignal: config.Signal(tc.signal)},
+				templates: tc.templates,
+			}
+			act := c.reloadFor(tc.rendered)
+			if !reflect.DeepEqual(tc.exp, act) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.exp, act)
+			}
+		})
+	}
+}
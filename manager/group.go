@@ -0,0 +1,350 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/logging"
+	"github.com/pkg/errors"
+)
+
+// templateGroup is a set of templates which are published together. Each
+// member is rendered into a new generation directory, and the group's symlink
+// is swapped to point at it once every member has been written and checked.
+type templateGroup struct {
+	config  *config.TemplateGroupConfig
+	members []*config.TemplateConfig
+
+	// contents is the most recently rendered contents of each member.
+	contents map[*config.TemplateConfig][]byte
+
+	// secrets are the values to redact from the diff of each member, and
+	// redactRemoved is whether every removed line is redacted from it.
+	secrets       map[*config.TemplateConfig][]string
+	redactRemoved map[*config.TemplateConfig]bool
+
+	logger *logging.Logger
+}
+
+// publishGroupInput is used as input to publish a template group.
+type publishGroupInput struct {
+	Diff      bool
+	Dry       bool
+	DryStream io.Writer
+
+	// Check returns the check for the given member, which may be nil.
+	Check func(*config.TemplateConfig) func(string) error
+
+	// GroupCheck is called with the path to the new generation once every
+	// member has been written. It may be nil.
+	GroupCheck func(string) error
+}
+
+// newTemplateGroups creates the template groups in the given configuration,
+// and assigns each template which names a group to it.
+func newTemplateGroups(groups *config.TemplateGroupConfigs, templates *config.TemplateConfigs) ([]*templateGroup, error) {
+	var result []*templateGroup
+	byName := make(map[string]*templateGroup)
+
+	if groups != nil {
+		for _, c := range *groups {
+			name := config.StringVal(c.Name)
+			if name == "" {
+				return nil, fmt.Errorf("template group: missing name")
+			}
+			if _, ok := byName[name]; ok {
+				return nil, fmt.Errorf("template group %q: duplicate name", name)
+			}
+			if config.StringVal(c.Path) == "" {
+				return nil, fmt.Errorf("template group %q: missing path", name)
+			}
+
+			g := &templateGroup{
+				config:        c,
+				contents:      make(map[*config.TemplateConfig][]byte),
+				secrets:       make(map[*config.TemplateConfig][]string),
+				redactRemoved: make(map[*config.TemplateConfig]bool),
+				logger:        logging.New("runner").With("template_group", c.Display()),
+			}
+			byName[name] = g
+			result = append(result, g)
+		}
+	}
+
+	if templates != nil {
+		for _, t := range *templates {
+			name := config.StringVal(t.TemplateGroup)
+			if name == "" {
+				continue
+			}
+
+			g, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown template group %q", t.Display(), name)
+			}
+
+			dest := config.StringVal(t.Destination)
+			if dest == "" || filepath.IsAbs(dest) || strings.HasPrefix(filepath.Clean(dest), "..") {
+				return nil, fmt.Errorf("%s: destination must be a relative path "+
+					"inside template group %q", t.Display(), name)
+			}
+			g.members = append(g.members, t)
+		}
+	}
+
+	for _, g := range result {
+		if len(g.members) == 0 {
+			g.logger.Printf("[WARN] %s has no templates", g.config.Display())
+		}
+	}
+
+	return result, nil
+}
+
+// has returns true if the given template is a member of this group.
+func (g *templateGroup) has(c *config.TemplateConfig) bool {
+	for _, m := range g.members {
+		if m == c {
+			return true
+		}
+	}
+	return false
+}
+
+// destination returns the path of the given member in the published
+// generation.
+func (g *templateGroup) destination(m *config.TemplateConfig) string {
+	return filepath.Join(config.StringVal(g.config.Path), config.StringVal(m.Destination))
+}
+
+// contentsFor returns the contents of the member of this group which was
+// rendered from the template of the given event.
+func (g *templateGroup) contentsFor(e *RenderEvent) []byte {
+	for _, c := range e.TemplateConfigs {
+		if contents, ok := g.contents[c]; ok {
+			return contents
+		}
+	}
+	return nil
+}
+
+// publish writes the latest contents of every member into a new generation,
+// checks it, and points the group's symlink at it. Nothing is published until
+// every member has rendered at least once, or if the published generation
+// already has the same contents. If any check fails, the new generation is
+// removed and the published one is left untouched.
+func (g *templateGroup) publish(i *publishGroupInput) (*RenderResult, error) {
+	if len(g.members) == 0 || len(g.contents) < len(g.members) {
+		return &RenderResult{}, nil
+	}
+
+	path := config.StringVal(g.config.Path)
+
+	if stat, err := os.Lstat(path); err == nil && stat.Mode()&os.ModeSymlink == 0 {
+		return nil, fmt.Errorf("%s exists and is not a symlink", path)
+	}
+
+	if !g.changed(path) {
+		return &RenderResult{WouldRender: true}, nil
+	}
+
+	// The diffs are taken against the published generation, so they must be
+	// built before the symlink is swapped.
+	var diffs []string
+	if i.Diff {
+		for _, m := range g.members {
+			diff, err := g.diff(path, m)
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+
+	if i.Dry {
+		for n, m := range g.members {
+			if i.Diff {
+				fmt.Fprint(i.DryStream, diffs[n])
+				continue
+			}
+			dest := filepath.Join(path, config.StringVal(m.Destination))
+			fmt.Fprintf(i.DryStream, "> %s\n%s", dest, g.contents[m])
+		}
+		return &RenderResult{DidRender: true, WouldRender: true}, nil
+	}
+
+	dir, err := g.writeGeneration(path, i)
+	if err != nil {
+		return nil, err
+	}
+
+	// Swap the symlink by renaming a new one over it, which is atomic. The
+	// target is relative, so the generations can be moved together.
+	link := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	os.Remove(link)
+	if err := os.Symlink(filepath.Base(dir), link); err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.Wrap(err, "failed creating symlink")
+	}
+	if err := os.Rename(link, path); err != nil {
+		os.Remove(link)
+		os.RemoveAll(dir)
+		return nil, errors.Wrap(err, "failed swapping symlink")
+	}
+
+	g.prune(path)
+
+	for n, m := range g.members {
+		if i.Diff && diffs[n] != "" {
+			dest := filepath.Join(path, config.StringVal(m.Destination))
+			g.logger.Printf("[INFO] diff for %s:\n%s", dest, diffs[n])
+		}
+	}
+
+	return &RenderResult{DidRender: true, WouldRender: true}, nil
+}
+
+// changed returns true if the contents of any member differ from the file in
+// the published generation.
+func (g *templateGroup) changed(path string) bool {
+	for _, m := range g.members {
+		existing, err := ioutil.ReadFile(filepath.Join(path, config.StringVal(m.Destination)))
+		if err != nil || !bytes.Equal(existing, g.contents[m]) {
+			return true
+		}
+	}
+	return false
+}
+
+// diff returns the diff between the given member in the published generation
+// and its latest contents.
+func (g *templateGroup) diff(path string, m *config.TemplateConfig) (string, error) {
+	dest := filepath.Join(path, config.StringVal(m.Destination))
+	existing, err := ioutil.ReadFile(dest)
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrap(err, "failed reading file")
+	}
+
+	diff, err := Diff(&DiffInput{
+		Path:          dest,
+		Existing:      existing,
+		Contents:      g.contents[m],
+		Secrets:       g.secrets[m],
+		RedactRemoved: g.redactRemoved[m],
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed diffing "+m.Display())
+	}
+	return diff, nil
+}
+
+// writeGeneration writes every member into a new generation directory and runs
+// the checks, returning the path to the directory. The directory is removed if
+// any write or check fails.
+func (g *templateGroup) writeGeneration(path string, i *publishGroupInput) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	gens, err := generations(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed listing generations")
+	}
+	next := 1
+	if len(gens) > 0 {
+		next = gens[len(gens)-1] + 1
+	}
+
+	dir := fmt.Sprintf("%s.%d", path, next)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return "", err
+	}
+
+	for _, m := range g.members {
+		var check func(string) error
+		if i.Check != nil {
+			check = i.Check(m)
+		}
+
+		owner := &Ownership{
+			User:  config.StringVal(m.User),
+			Group: config.StringVal(m.Group),
+		}
+
+		dest := filepath.Join(dir, config.StringVal(m.Destination))
+		if err := AtomicWrite(dest, g.contents[m], config.FileModeVal(m.Perms), false, owner, check); err != nil {
+			os.RemoveAll(dir)
+			return "", errors.Wrap(err, "error rendering "+m.Display())
+		}
+	}
+
+	if i.GroupCheck != nil {
+		if err := i.GroupCheck(dir); err != nil {
+			os.RemoveAll(dir)
+			return "", NewErrCheckFailed(path, err)
+		}
+	}
+
+	return dir, nil
+}
+
+// prune removes all but the newest generations, as configured by Keep. The
+// published generation is never removed.
+func (g *templateGroup) prune(path string) {
+	keep := config.IntVal(g.config.Keep)
+	if keep < 1 {
+		keep = 1
+	}
+
+	gens, err := generations(path)
+	if err != nil {
+		g.logger.Printf("[WARN] failed listing generations of %s: %s", path, err)
+		return
+	}
+
+	current, _ := os.Readlink(path)
+	for len(gens) > keep {
+		dir := fmt.Sprintf("%s.%d", path, gens[0])
+		gens = gens[1:]
+
+		if filepath.Base(dir) == current {
+			continue
+		}
+		g.logger.Printf("[DEBUG] removing old generation %s", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			g.logger.Printf("[WARN] failed removing old generation %s: %s", dir, err)
+		}
+	}
+}
+
+// generations returns the numbers of the existing generation directories of
+// the given symlink path, in ascending order.
+func generations(path string) ([]int, error) {
+	entries, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(path) + "."
+	var result []int
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(e.Name(), prefix))
+		if err != nil || n < 1 {
+			continue
+		}
+		result = append(result, n)
+	}
+	sort.Ints(result)
+	return result, nil
+}
@@ -0,0 +1,408 @@
+package manager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestNewTemplateGroups(t *testing.T) {
+	cases := []struct {
+		name      string
+		groups    *config.TemplateGroupConfigs
+		templates *config.TemplateConfigs
+		members   []int
+		err       bool
+	}{
+		{
+			"empty",
+			nil,
+			nil,
+			nil,
+			false,
+		},
+		{
+			"members",
+			&config.TemplateGroupConfigs{
+				&config.TemplateGroupConfig{
+					Name: config.String("a"),
+					Path: config.String("/etc/a"),
+				},
+			},
+			&config.TemplateConfigs{
+				&config.TemplateConfig{
+					Destination:   config.String("one.conf"),
+					TemplateGroup: config.String("a"),
+				},
+				&config.TemplateConfig{
+					Destination: config.String("/etc/other.conf"),
+				},
+				&config.TemplateConfig{
+					Destination:   config.String("sub/two.conf"),
+					TemplateGroup: config.String("a"),
+				},
+			},
+			[]int{2},
+			false,
+		},
+		{
+			"missing_name",
+			&config.TemplateGroupConfigs{
+				&config.TemplateGroupConfig{
+					Path: config.String("/etc/a"),
+				},
+			},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"missing_path",
+			&config.TemplateGroupConfigs{
+				&config.TemplateGroupConfig{
+					Name: config.String("a"),
+				},
+			},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"duplicate_name",
+			&config.TemplateGroupConfigs{
+				&config.TemplateGroupConfig{
+					Name: config.String("a"),
+					Path: config.String("/etc/a"),
+				},
+				&config.TemplateGroupConfig{
+					Name: config.String("a"),
+					Path: config.String("/etc/b"),
+				},
+			},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"unknown_group",
+			nil,
+			&config.TemplateConfigs{
+				&config.TemplateConfig{
+					Destination:   config.String("one.conf"),
+					TemplateGroup: config.String("a"),
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			"absolute_destination",
+			&config.TemplateGroupConfigs{
+				&config.TemplateGroupConfig{
+					Name: config.String("a"),
+					Path: config.String("/etc/a"),
+				},
+			},
+			&config.TemplateConfigs{
+				&config.TemplateConfig{
+					Destination:   config.String("/etc/one.conf"),
+					TemplateGroup: config.String("a"),
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			"escaping_destination",
+			&config.TemplateGroupConfigs{
+				&config.TemplateGroupConfig{
+					Name: config.String("a"),
+					Path: config.String("/etc/a"),
+				},
+			},
+			&config.TemplateConfigs{
+				&config.TemplateConfig{
+					Destination:   config.String("../one.conf"),
+					TemplateGroup: config.String("a"),
+				},
+			},
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			groups, err := newTemplateGroups(tc.groups, tc.templates)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+
+			var members []int
+			for _, g := range groups {
+				members = append(members, len(g.members))
+			}
+			if !reflect.DeepEqual(members, tc.members) {
+				t.Errorf("expected %v to be %v", members, tc.members)
+			}
+		})
+	}
+}
+
+// testTemplateGroup creates a template group with the given member
+// destinations, which publishes to a symlink in a temporary directory.
+func testTemplateGroup(t *testing.T, keep int, dests ...string) (*templateGroup, string) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &config.TemplateGroupConfig{
+		Keep: config.Int(keep),
+		Name: config.String("test"),
+		Path: config.String(filepath.Join(dir, "conf")),
+	}
+	g.Finalize()
+
+	templates := make(config.TemplateConfigs, 0, len(dests))
+	for _, dest := range dests {
+		c := &config.TemplateConfig{
+			Destination:   config.String(dest),
+			TemplateGroup: config.String("test"),
+		}
+		c.Finalize()
+		templates = append(templates, c)
+	}
+
+	groups, err := newTemplateGroups(&config.TemplateGroupConfigs{g}, &templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return groups[0], dir
+}
+
+// stageGroup sets the contents of each member of the group, in order.
+func stageGroup(g *templateGroup, contents ...string) {
+	for i, m := range g.members {
+		g.contents[m] = []byte(contents[i])
+	}
+}
+
+func TestTemplateGroup_publish(t *testing.T) {
+	t.Run("waits_for_members", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf", "b.conf")
+		defer os.RemoveAll(dir)
+
+		g.contents[g.members[0]] = []byte("a")
+
+		result, err := g.publish(&publishGroupInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.WouldRender || result.DidRender {
+			t.Errorf("expected not to render")
+		}
+		if _, err := os.Lstat(filepath.Join(dir, "conf")); !os.IsNotExist(err) {
+			t.Errorf("expected symlink not to exist")
+		}
+	})
+
+	t.Run("publishes", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf", "sub/b.conf")
+		defer os.RemoveAll(dir)
+
+		stageGroup(g, "a1", "b1")
+		result, err := g.publish(&publishGroupInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.DidRender {
+			t.Errorf("expected to render")
+		}
+
+		link := filepath.Join(dir, "conf")
+		target, err := os.Readlink(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if target != "conf.1" {
+			t.Errorf("expected %q to be %q", target, "conf.1")
+		}
+
+		for path, exp := range map[string]string{"a.conf": "a1", "sub/b.conf": "b1"} {
+			act, err := ioutil.ReadFile(filepath.Join(link, path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(act) != exp {
+				t.Errorf("expected %q to be %q", act, exp)
+			}
+		}
+
+		// Publishing the same contents again does nothing.
+		result, err = g.publish(&publishGroupInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.WouldRender || result.DidRender {
+			t.Errorf("expected to be up to date")
+		}
+		if target, _ := os.Readlink(link); target != "conf.1" {
+			t.Errorf("expected %q to be %q", target, "conf.1")
+		}
+	})
+
+	t.Run("keeps_generations", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 2, "a.conf")
+		defer os.RemoveAll(dir)
+
+		for i := 1; i <= 4; i++ {
+			stageGroup(g, fmt.Sprintf("a%d", i))
+			if _, err := g.publish(&publishGroupInput{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		gens, err := generations(filepath.Join(dir, "conf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := []int{3, 4}; !reflect.DeepEqual(gens, exp) {
+			t.Errorf("expected %v to be %v", gens, exp)
+		}
+
+		act, err := ioutil.ReadFile(filepath.Join(dir, "conf.3", "a.conf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(act) != "a3" {
+			t.Errorf("expected %q to be %q", act, "a3")
+		}
+	})
+
+	t.Run("check_fails", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf", "b.conf")
+		defer os.RemoveAll(dir)
+
+		stageGroup(g, "a1", "b1")
+		if _, err := g.publish(&publishGroupInput{}); err != nil {
+			t.Fatal(err)
+		}
+
+		var checked string
+		stageGroup(g, "a2", "b2")
+		_, err := g.publish(&publishGroupInput{
+			GroupCheck: func(path string) error {
+				checked = path
+				return errors.New("bad")
+			},
+		})
+		if _, ok := err.(*ErrCheckFailed); !ok {
+			t.Fatalf("expected %#v to be an ErrCheckFailed", err)
+		}
+		if exp := filepath.Join(dir, "conf.2"); checked != exp {
+			t.Errorf("expected %q to be %q", checked, exp)
+		}
+
+		if target, _ := os.Readlink(filepath.Join(dir, "conf")); target != "conf.1" {
+			t.Errorf("expected %q to be %q", target, "conf.1")
+		}
+		if _, err := os.Stat(checked); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", checked)
+		}
+	})
+
+	t.Run("member_check_fails", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf", "b.conf")
+		defer os.RemoveAll(dir)
+
+		stageGroup(g, "a1", "b1")
+		_, err := g.publish(&publishGroupInput{
+			Check: func(c *config.TemplateConfig) func(string) error {
+				if c != g.members[1] {
+					return nil
+				}
+				return func(string) error { return errors.New("bad") }
+			},
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if _, err := os.Lstat(filepath.Join(dir, "conf")); !os.IsNotExist(err) {
+			t.Errorf("expected symlink not to exist")
+		}
+		if gens, _ := generations(filepath.Join(dir, "conf")); len(gens) != 0 {
+			t.Errorf("expected no generations, got %v", gens)
+		}
+	})
+
+	t.Run("not_symlink", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf")
+		defer os.RemoveAll(dir)
+
+		if err := os.Mkdir(filepath.Join(dir, "conf"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		stageGroup(g, "a1")
+		if _, err := g.publish(&publishGroupInput{}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("dry", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf")
+		defer os.RemoveAll(dir)
+
+		var out bytes.Buffer
+		stageGroup(g, "a1")
+		result, err := g.publish(&publishGroupInput{Dry: true, DryStream: &out})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.DidRender {
+			t.Errorf("expected to render")
+		}
+
+		exp := fmt.Sprintf("> %s\na1", filepath.Join(dir, "conf", "a.conf"))
+		if out.String() != exp {
+			t.Errorf("expected %q to be %q", out.String(), exp)
+		}
+		if _, err := os.Lstat(filepath.Join(dir, "conf")); !os.IsNotExist(err) {
+			t.Errorf("expected symlink not to exist")
+		}
+	})
+
+	t.Run("dry_diff", func(t *testing.T) {
+		g, dir := testTemplateGroup(t, 3, "a.conf")
+		defer os.RemoveAll(dir)
+
+		stageGroup(g, "a1\n")
+		if _, err := g.publish(&publishGroupInput{}); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		stageGroup(g, "a2\n")
+		if _, err := g.publish(&publishGroupInput{Diff: true, Dry: true, DryStream: &out}); err != nil {
+			t.Fatal(err)
+		}
+
+		dest := filepath.Join(dir, "conf", "a.conf")
+		exp := fmt.Sprintf("--- %s\n+++ %s\n@@ -1 +1 @@\n-a1\n+a2\n", dest, dest)
+		if out.String() != exp {
+			t.Errorf("expected %q to be %q", out.String(), exp)
+		}
+		if target, _ := os.Readlink(filepath.Join(dir, "conf")); target != "conf.1" {
+			t.Errorf("expected %q to be %q", target, "conf.1")
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/systemd"
+)
+
+// notifyRendered tells the service manager how far the templates are from
+// rendering, and that the runner is ready once every template has rendered for
+// the first time.
+func (r *Runner) notifyRendered() {
+	if r.notifier == nil {
+		return
+	}
+
+	rendered := r.allTemplatesRendered()
+	states := []string{systemd.Status(r.renderStatus(rendered))}
+	if rendered && !r.notifiedReady {
+		states = append(states, systemd.Ready)
+	}
+
+	if err := r.notifier.Notify(states...); err != nil {
+		r.logger.Printf("[WARN] failed to notify service manager: %s", err)
+		return
+	}
+	if rendered {
+		r.notifiedReady = true
+	}
+}
+
+// renderStatus describes how many templates have rendered, or how many
+// dependencies they are missing.
+func (r *Runner) renderStatus(rendered bool) string {
+	if rendered {
+		return fmt.Sprintf("Rendered %d templates, watching %d dependencies",
+			len(r.templates), r.watcher.Size())
+	}
+
+	r.renderEventsLock.RLock()
+	defer r.renderEventsLock.RUnlock()
+
+	waiting := 0
+	missing := new(dep.Set)
+	for _, tmpl := range r.templates {
+		event, ok := r.renderEvents[tmpl.ID()]
+		if ok && (event.DidRender || event.WouldRender) {
+			continue
+		}
+		waiting++
+
+		if ok && event.MissingDeps != nil {
+			for _, d := range event.MissingDeps.List() {
+				missing.Add(d)
+			}
+		}
+	}
+	return fmt.Sprintf("Waiting for %d of %d templates, missing %d dependencies",
+		waiting, len(r.templates), missing.Len())
+}
+
+// watchdog notifies the service manager's watchdog at the given interval until
+// the runner stops.
+func (r *Runner) watchdog(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.notifyWatchdog()
+		case <-r.DoneCh:
+			return
+		}
+	}
+}
+
+// notifyWatchdog tells the service manager the runner is still healthy, unless
+// a watched dependency is failing, in which case the watchdog is left to fire
+// if it keeps failing.
+func (r *Runner) notifyWatchdog() {
+	for _, v := range r.watcher.Status() {
+		if v.Retries > 0 {
+			r.logger.Printf("[WARN] skipping watchdog notification (%s is failing)",
+				v.Dependency)
+			return
+		}
+	}
+
+	if err := r.notifier.Notify(systemd.Watchdog); err != nil {
+		r.logger.Printf("[WARN] failed to notify service manager: %s", err)
+	}
+}
@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestRunner_notify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.SkipNow()
+	}
+
+	cases := []struct {
+		name     string
+		contents string
+		exp      string
+	}{
+		{
+			"rendered",
+			`test`,
+			"STATUS=Rendered 1 templates, watching 0 dependencies\nREADY=1",
+		},
+		{
+			"missing",
+			`{{ key "notify-foo" }}`,
+			"STATUS=Waiting for 1 of 1 templates, missing 1 dependencies",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			socket := filepath.Join(dir, "notify.sock")
+			conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			os.Setenv("NOTIFY_SOCKET", socket)
+			defer os.Unsetenv("NOTIFY_SOCKET")
+
+			c := config.DefaultConfig().Merge(&config.Config{
+				Consul: &config.ConsulConfig{
+					Address: config.String("127.0.0.1:1"),
+				},
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Contents:    config.String(tc.contents),
+						Destination: config.String(filepath.Join(dir, "out")),
+					},
+				},
+			})
+			c.Finalize()
+
+			r, err := NewRunner(c, false, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			go r.Start()
+			defer r.Stop()
+
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			buf := make([]byte, 1024)
+			n, err := conn.Read(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := string(buf[:n]); act != tc.exp {
+				t.Errorf("\nexp: %q\nact: %q", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestRunner_notifyWatchdog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.SkipNow()
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	os.Setenv("NOTIFY_SOCKET", socket)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+	os.Setenv("WATCHDOG_USEC", "200000")
+	defer os.Unsetenv("WATCHDOG_USEC")
+
+	// The command of the first render takes longer than the watchdog interval.
+	c := config.DefaultConfig().Merge(&config.Config{
+		Templates: &config.TemplateConfigs{
+			&config.TemplateConfig{
+				Contents:    config.String("test"),
+				Command:     config.String("sleep 2"),
+				Destination: config.String(filepath.Join(dir, "out")),
+			},
+		},
+	})
+	c.Finalize()
+
+	r, err := NewRunner(c, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go r.Start()
+	defer r.Stop()
+
+	conn.SetReadDeadline(time.Now().Add(1 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := "WATCHDOG=1", string(buf[:n]); act != exp {
+		t.Errorf("\nexp: %q\nact: %q", exp, act)
+	}
+}
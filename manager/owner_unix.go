@@ -0,0 +1,17 @@
+// +build !windows
+
+package manager
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the user and group IDs of the owner of the given file.
+func fileOwner(info os.FileInfo) (int, int) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1
+	}
+	return int(stat.Uid), int(stat.Gid)
+}
@@ -0,0 +1,163 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/logging"
+	"github.com/pkg/errors"
+)
+
+// prober periodically probes the liveness of the child process in exec mode,
+// and reports on its fail channel once too many consecutive probes have failed.
+type prober struct {
+	config *config.ProbeConfig
+
+	// env, stdout and stderr are given to a command probe.
+	env            []string
+	stdout, stderr io.Writer
+
+	failCh   chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	logger *logging.Logger
+}
+
+// newProber creates a new prober from the given configuration, which must name
+// exactly one kind of probe.
+func newProber(c *config.ProbeConfig) (*prober, error) {
+	kinds := 0
+	for _, s := range []*string{c.Command, c.HTTP, c.TCP} {
+		if config.StringPresent(s) {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return nil, fmt.Errorf("exec liveness_probe: exactly one of command, " +
+			"http or tcp is required")
+	}
+
+	return &prober{
+		config: c,
+		failCh: make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+		logger: logging.New("runner"),
+	}, nil
+}
+
+// FailCh returns the channel which is triggered when the child process fails
+// its liveness probe.
+func (p *prober) FailCh() <-chan struct{} {
+	return p.failCh
+}
+
+// start begins probing in the background. The environment and streams are
+// given to a command probe.
+func (p *prober) start(env []string, stdout, stderr io.Writer) {
+	p.env, p.stdout, p.stderr = env, stdout, stderr
+	go p.run()
+}
+
+// stop stops probing. It is safe to call more than once.
+func (p *prober) stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+}
+
+func (p *prober) run() {
+	threshold := config.IntVal(p.config.FailureThreshold)
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	interval := config.TimeDurationVal(p.config.Interval)
+	if interval <= 0 {
+		interval = config.DefaultProbeInterval
+	}
+
+	// Wait for the initial delay again after each failure is reported, since
+	// the child process is restarted.
+	delay := time.After(config.TimeDurationVal(p.config.InitialDelay))
+	for {
+		select {
+		case <-delay:
+		case <-p.stopCh:
+			return
+		}
+
+		failures := 0
+		ticker := time.NewTicker(interval)
+		for failures < threshold {
+			select {
+			case <-ticker.C:
+			case <-p.stopCh:
+				ticker.Stop()
+				return
+			}
+
+			if err := p.probe(); err != nil {
+				failures++
+				p.logger.Printf("[WARN] liveness probe failed (%d/%d): %s",
+					failures, threshold, err)
+				continue
+			}
+			failures = 0
+		}
+		ticker.Stop()
+
+		select {
+		case p.failCh <- struct{}{}:
+		default:
+		}
+		delay = time.After(config.TimeDurationVal(p.config.InitialDelay))
+	}
+}
+
+// probe runs a single probe, returning an error if it fails.
+func (p *prober) probe() error {
+	// A timeout is required, since without one a command would be supervised
+	// instead of waited for.
+	timeout := config.TimeDurationVal(p.config.Timeout)
+	if timeout <= 0 {
+		timeout = config.DefaultProbeTimeout
+	}
+
+	switch {
+	case config.StringPresent(p.config.HTTP):
+		client := &http.Client{Timeout: timeout}
+		resp, err := client.Get(config.StringVal(p.config.HTTP))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("unexpected response status %q", resp.Status)
+		}
+	case config.StringPresent(p.config.TCP):
+		conn, err := net.DialTimeout("tcp", config.StringVal(p.config.TCP), timeout)
+		if err != nil {
+			return err
+		}
+		conn.Close()
+	default:
+		command := config.StringVal(p.config.Command)
+		if _, err := spawnChild(&spawnChildInput{
+			Stdout:  p.stdout,
+			Stderr:  p.stderr,
+			Command: command,
+			Env:     p.env,
+			Timeout: timeout,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to execute probe command %q", command)
+		}
+	}
+
+	return nil
+}
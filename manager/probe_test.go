@@ -0,0 +1,154 @@
+package manager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestNewProber(t *testing.T) {
+	cases := []struct {
+		name string
+		c    *config.ProbeConfig
+		err  bool
+	}{
+		{
+			"none",
+			&config.ProbeConfig{},
+			true,
+		},
+		{
+			"command",
+			&config.ProbeConfig{Command: config.String("true")},
+			false,
+		},
+		{
+			"http",
+			&config.ProbeConfig{HTTP: config.String("http://127.0.0.1")},
+			false,
+		},
+		{
+			"tcp",
+			&config.ProbeConfig{TCP: config.String("127.0.0.1:80")},
+			false,
+		},
+		{
+			"many",
+			&config.ProbeConfig{
+				Command: config.String("true"),
+				TCP:     config.String("127.0.0.1:80"),
+			},
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.c.Finalize()
+			_, err := newProber(tc.c)
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestProber_probe(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	// A listener which is closed straight away gives an address which refuses
+	// connections.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := l.Addr().String()
+	l.Close()
+
+	cases := []struct {
+		name string
+		c    *config.ProbeConfig
+		err  bool
+	}{
+		{
+			"http",
+			&config.ProbeConfig{HTTP: config.String(ok.URL)},
+			false,
+		},
+		{
+			"http_status",
+			&config.ProbeConfig{HTTP: config.String(failing.URL)},
+			true,
+		},
+		{
+			"tcp",
+			&config.ProbeConfig{TCP: config.String(ok.Listener.Addr().String())},
+			false,
+		},
+		{
+			"tcp_refused",
+			&config.ProbeConfig{TCP: config.String(closed)},
+			true,
+		},
+		{
+			"command",
+			&config.ProbeConfig{Command: config.String("true")},
+			false,
+		},
+		{
+			"command_fails",
+			&config.ProbeConfig{Command: config.String("false")},
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tc.c.Finalize()
+			p, err := newProber(tc.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.stdout, p.stderr = ioutil.Discard, ioutil.Discard
+
+			err = p.probe()
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestProber_run(t *testing.T) {
+	c := &config.ProbeConfig{
+		Command:          config.String("false"),
+		FailureThreshold: config.Int(2),
+		Interval:         config.TimeDuration(10 * time.Millisecond),
+	}
+	c.Finalize()
+
+	p, err := newProber(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.start(nil, ioutil.Discard, ioutil.Discard)
+	defer p.stop()
+
+	select {
+	case <-p.FailCh():
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected probe to fail")
+	}
+}
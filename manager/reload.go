@@ -0,0 +1,240 @@
+package manager
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/template"
+	"github.com/pkg/errors"
+)
+
+// ErrRestartRequired is returned by Reload if the new configuration cannot be
+// applied to the running runner, which must be replaced by a new one.
+var ErrRestartRequired = errors.New("runner: configuration change requires a restart")
+
+// ErrRunnerStopped is returned by Reload if the runner stopped before it could
+// apply the new configuration.
+var ErrRunnerStopped = errors.New("runner: stopped before the configuration was reloaded")
+
+// reloadRequest asks the runner to apply a new configuration, and receives the
+// result of the reload.
+type reloadRequest struct {
+	config *config.Config
+	errCh  chan error
+}
+
+// Reload applies the given configuration to the runner while it runs. The data,
+// views and Vault leases of each dependency which is still used are kept, only
+// the templates which changed are rendered, and supervised processes are only
+// restarted if their exec configuration changed.
+//
+// If the configuration changes how the runner connects to Consul or Vault, or
+// its de-duplication options, ErrRestartRequired is returned and nothing is
+// changed. A runner which de-duplicates restarts its de-duplication manager with
+// the new templates, so the lock of each template is acquired again. If the configuration is invalid, or a template cannot be parsed, an
+// ErrReloadFailed is returned and the runner keeps its configuration. If the
+// runner is stopped while the reload waits for the current run to finish,
+// ErrRunnerStopped is returned. Any other error is the error which stopped the
+// runner.
+//
+// Reload blocks until the runner is between runs, which may take as long as its
+// slowest template command, so callers which must stay responsive should call
+// it from their own goroutine.
+func (r *Runner) Reload(c *config.Config) error {
+	req := &reloadRequest{
+		config: c,
+		errCh:  make(chan error, 1),
+	}
+
+	select {
+	case r.reloadCh <- req:
+		return <-req.errCh
+	case err := <-r.ErrCh:
+		return err
+	case <-r.DoneCh:
+		return ErrRunnerStopped
+	}
+}
+
+// reload applies the given configuration from the runner's goroutine. The
+// next run renders the new templates, and stops watching the dependencies
+// which are no longer used.
+func (r *Runner) reload(c *config.Config) error {
+	c = config.DefaultConfig().Merge(c)
+	c.Finalize()
+
+	if block := restartRequiredBy(r.config, c); block != "" {
+		r.logger.Printf("[INFO] %s changed, restart required to reload", block)
+		return ErrRestartRequired
+	}
+
+	// Build everything from the new configuration before changing the runner,
+	// so an invalid configuration leaves it as it was.
+	templates, ctemplatesMap, err := newTemplates(c.Templates)
+	if err != nil {
+		return NewErrReloadFailed(err)
+	}
+	for _, tmpl := range templates {
+		if err := tmpl.Validate(); err != nil {
+			return NewErrReloadFailed(errors.Wrap(err, tmpl.Source()))
+		}
+	}
+	groups, err := newTemplateGroups(c.TemplateGroups, c.Templates)
+	if err != nil {
+		return NewErrReloadFailed(err)
+	}
+	if err := validateCommands(c.Templates); err != nil {
+		return NewErrReloadFailed(err)
+	}
+	children, err := newExecChildren(c)
+	if err != nil {
+		return NewErrReloadFailed(err)
+	}
+
+	ids := make(map[string]struct{}, len(templates))
+	for _, tmpl := range templates {
+		ids[tmpl.ID()] = struct{}{}
+	}
+
+	// Forget the templates which were removed. Templates which are unchanged
+	// keep their state, so they are not rendered again unless their data
+	// changed.
+	r.renderEventsLock.Lock()
+	for id := range r.renderEvents {
+		if _, ok := ids[id]; !ok {
+			delete(r.renderEvents, id)
+		}
+	}
+	r.templates = templates
+	r.ctemplatesMap = ctemplatesMap
+	r.renderEventsLock.Unlock()
+
+	for id := range r.renderedData {
+		if _, ok := ids[id]; !ok {
+			delete(r.renderedData, id)
+		}
+	}
+	for id := range r.quiescenceMap {
+		if _, ok := ids[id]; !ok {
+			delete(r.quiescenceMap, id)
+		}
+	}
+
+	r.groups = groups
+	r.replaceChildren(children)
+
+	if err := r.replaceDedup(c.Dedup, templates); err != nil {
+		return err
+	}
+
+	if r.semaphores == nil {
+		for _, t := range *c.Templates {
+			if config.BoolVal(t.Exec.Concurrency.Enabled) {
+				r.semaphores = newSemaphoreManager(r.clients)
+				break
+			}
+		}
+	}
+
+	r.config = c
+	r.logger.Printf("[INFO] reloaded configuration (%d templates, %d child processes)",
+		len(r.templates), len(r.children))
+	return nil
+}
+
+// restartRequiredBy returns the name of the first configuration block which
+// changed between the previous and next configuration and cannot be reloaded,
+// or an empty string if the next configuration can be reloaded.
+func restartRequiredBy(prev, next *config.Config) string {
+	switch {
+	case !reflect.DeepEqual(prev.Consul, next.Consul):
+		return "consul"
+	case !reflect.DeepEqual(prev.Vault, next.Vault):
+		return "vault"
+	case !reflect.DeepEqual(prev.MaxStale, next.MaxStale):
+		return "max_stale"
+	case !reflect.DeepEqual(prev.Dedup, next.Dedup):
+		return "deduplicate"
+	case !reflect.DeepEqual(prev.PidFile, next.PidFile):
+		return "pid_file"
+	case !reflect.DeepEqual(prev.Status, next.Status):
+		return "status"
+	}
+	return ""
+}
+
+// replaceDedup replaces the de-duplication manager, if there is one, by a new
+// one for the given templates, since a manager holds the locks of the templates
+// it started with. It is not replaced once the runner is stopped.
+func (r *Runner) replaceDedup(c *config.DedupConfig, templates []*template.Template) error {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+
+	if r.dedup == nil || r.stopped {
+		return nil
+	}
+
+	r.stopDedup()
+	dedup, err := NewDedupManager(c, r.clients, r.brain, templates)
+	if err != nil {
+		return err
+	}
+	if err := dedup.Start(); err != nil {
+		return err
+	}
+
+	// The status server reads the manager from its own goroutine.
+	r.renderEventsLock.Lock()
+	r.dedup = dedup
+	r.renderEventsLock.Unlock()
+	return nil
+}
+
+// replaceChildren supervises the given processes instead of the current ones.
+// A running process whose exec configuration is unchanged is kept, and any
+// other process is stopped. New processes are started once every template has
+// rendered.
+func (r *Runner) replaceChildren(children []*execChild) {
+	r.childLock.Lock()
+	defer r.childLock.Unlock()
+
+	kept := make(map[*execChild]struct{}, len(r.children))
+	for i, c := range children {
+		for _, existing := range r.children {
+			if _, ok := kept[existing]; ok {
+				continue
+			}
+			if !reflect.DeepEqual(existing.config, c.config) {
+				continue
+			}
+
+			// The templates which reload the process are from the new
+			// configuration.
+			existing.config = c.config
+			existing.templates = c.templates
+			kept[existing] = struct{}{}
+			children[i] = existing
+			break
+		}
+	}
+
+	for i := len(r.children) - 1; i >= 0; i-- {
+		c := r.children[i]
+		if _, ok := kept[c]; ok {
+			continue
+		}
+
+		r.logger.Printf("[INFO] stopping child process %s (exec configuration changed)",
+			c.config.Display())
+		c.removed = true
+		c.exitCh = nil
+		if c.prober != nil {
+			c.prober.stop()
+		}
+		if c.child != nil {
+			c.child.Stop()
+		}
+	}
+
+	r.children = children
+}
@@ -0,0 +1,465 @@
+package manager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/logging"
+)
+
+func TestRestartRequiredBy(t *testing.T) {
+	cases := []struct {
+		name string
+		c    *config.Config
+		exp  string
+	}{
+		{
+			"unchanged",
+			&config.Config{},
+			"",
+		},
+		{
+			"templates",
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{Contents: config.String("foo")},
+				},
+			},
+			"",
+		},
+		{
+			"exec",
+			&config.Config{
+				Exec: &config.ExecConfig{Command: config.String("foo")},
+			},
+			"",
+		},
+		{
+			"consul",
+			&config.Config{
+				Consul: &config.ConsulConfig{Address: config.String("127.0.0.1:8501")},
+			},
+			"consul",
+		},
+		{
+			"vault",
+			&config.Config{
+				Vault: &config.VaultConfig{Address: config.String("https://vault.service.consul")},
+			},
+			"vault",
+		},
+		{
+			"dedup",
+			&config.Config{
+				Dedup: &config.DedupConfig{Enabled: config.Bool(true)},
+			},
+			"deduplicate",
+		},
+		{
+			"pid_file",
+			&config.Config{
+				PidFile: config.String("/var/run/consul-template.pid"),
+			},
+			"pid_file",
+		},
+	}
+
+	prev := config.DefaultConfig()
+	prev.Finalize()
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			next := config.DefaultConfig().Merge(tc.c)
+			next.Finalize()
+
+			if act := restartRequiredBy(prev, next); act != tc.exp {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestRunner_reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	foo := &config.TemplateConfig{
+		Contents:    config.String(`{{ key "reload-foo" }}`),
+		Destination: config.String(filepath.Join(dir, "foo")),
+	}
+	bar := &config.TemplateConfig{
+		Contents:    config.String(`bar`),
+		Destination: config.String(filepath.Join(dir, "bar")),
+	}
+	newConfig := func(templates ...*config.TemplateConfig) *config.Config {
+		ctemplates := make(config.TemplateConfigs, 0, len(templates))
+		for _, t := range templates {
+			ctemplates = append(ctemplates, t.Copy())
+		}
+		return &config.Config{
+			Consul: &config.ConsulConfig{
+				Address: config.String("127.0.0.1:1"),
+			},
+			Templates: &ctemplates,
+		}
+	}
+
+	r, err := NewRunner(newConfig(foo), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// Render foo with data from its dependency.
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	fooID := r.templates[0].ID()
+	d := r.renderEvents[fooID].MissingDeps.List()[0]
+	r.Receive(d, "foo")
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !r.renderEvents[fooID].DidRender {
+		t.Fatal("expected foo to render")
+	}
+
+	t.Run("add_template", func(t *testing.T) {
+		if err := r.reload(newConfig(foo, bar)); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !r.watcher.Watching(d) {
+			t.Errorf("expected %s to be watched", d)
+		}
+		if _, ok := r.brain.Recall(d); !ok {
+			t.Errorf("expected data of %s to be kept", d)
+		}
+		if e := r.renderEvents[fooID]; e.DidRender || !e.WouldRender {
+			t.Errorf("expected foo not to render again")
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := "bar"; string(b) != exp {
+			t.Errorf("\nexp: %#v\nact: %#v", exp, string(b))
+		}
+	})
+
+	t.Run("remove_template", func(t *testing.T) {
+		if err := r.reload(newConfig(bar)); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		if r.watcher.Watching(d) {
+			t.Errorf("expected %s not to be watched", d)
+		}
+		if _, ok := r.brain.Recall(d); ok {
+			t.Errorf("expected data of %s to be forgotten", d)
+		}
+		if _, ok := r.renderEvents[fooID]; ok {
+			t.Errorf("expected render event of foo to be forgotten")
+		}
+	})
+
+	t.Run("restart_required", func(t *testing.T) {
+		c := newConfig(bar)
+		c.Consul.Address = config.String("127.0.0.1:2")
+		if err := r.reload(c); err != ErrRestartRequired {
+			t.Fatalf("\nexp: %#v\nact: %#v", ErrRestartRequired, err)
+		}
+		if exp, act := "127.0.0.1:1", config.StringVal(r.config.Consul.Address); act != exp {
+			t.Errorf("\nexp: %#v\nact: %#v", exp, act)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := newConfig(bar)
+		c.Execs = &config.ExecConfigs{&config.ExecConfig{}}
+		if _, ok := r.reload(c).(*ErrReloadFailed); !ok {
+			t.Fatal("expected reload to fail")
+		}
+		if l := len(r.templates); l != 1 {
+			t.Errorf("\nexp: %#v\nact: %#v", 1, l)
+		}
+	})
+
+	t.Run("parse_error", func(t *testing.T) {
+		broken := bar.Copy()
+		broken.Contents = config.String(`{{ key "reload-foo" `)
+		if _, ok := r.reload(newConfig(broken)).(*ErrReloadFailed); !ok {
+			t.Fatal("expected reload to fail")
+		}
+		if exp, act := "bar", r.templates[0].Contents(); act != exp {
+			t.Errorf("\nexp: %#v\nact: %#v", exp, act)
+		}
+	})
+}
+
+func TestRunner_Reload(t *testing.T) {
+	out, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+
+	newConfig := func(contents string) *config.Config {
+		return &config.Config{
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{
+					Contents:    config.String(contents),
+					Destination: config.String(out.Name()),
+				},
+			},
+		}
+	}
+
+	r, err := NewRunner(newConfig("foo"), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go r.Start()
+	defer r.Stop()
+
+	for _, contents := range []string{"foo", "bar"} {
+		if contents != "foo" {
+			if err := r.Reload(newConfig(contents)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		select {
+		case err := <-r.ErrCh:
+			t.Fatal(err)
+		case <-r.renderedCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout")
+		}
+
+		b, err := ioutil.ReadFile(out.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != contents {
+			t.Errorf("\nexp: %#v\nact: %#v", contents, string(b))
+		}
+	}
+}
+
+func TestRunner_Reload_stopped(t *testing.T) {
+	c := &config.Config{
+		Templates: &config.TemplateConfigs{
+			&config.TemplateConfig{Contents: config.String("foo")},
+		},
+	}
+	r, err := NewRunner(c, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Stop()
+
+	if err := r.Reload(c); err != ErrRunnerStopped {
+		t.Fatalf("\nexp: %#v\nact: %#v", ErrRunnerStopped, err)
+	}
+}
+
+func TestRunner_reload_status(t *testing.T) {
+	r, err := NewRunner(&config.Config{
+		Templates: &config.TemplateConfigs{
+			&config.TemplateConfig{Contents: config.String("foo")},
+		},
+	}, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// The status is read from other goroutines while a reload replaces the
+	// templates, which the race detector checks.
+	stopCh, doneCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			r.Status()
+			r.TemplateConfigMapping()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		c := &config.Config{
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{Contents: config.String(fmt.Sprintf("foo%d", i))},
+			},
+		}
+		if err := r.reload(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stopCh)
+	<-doneCh
+
+	if l := len(r.Status().Templates); l != 1 {
+		t.Errorf("\nexp: %#v\nact: %#v", 1, l)
+	}
+}
+
+func TestRunner_reload_dedup(t *testing.T) {
+	newConfig := func(contents string) *config.Config {
+		return &config.Config{
+			Consul: &config.ConsulConfig{
+				Address: config.String("127.0.0.1:1"),
+			},
+			Dedup: &config.DedupConfig{Enabled: config.Bool(true)},
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{Contents: config.String(contents)},
+			},
+		}
+	}
+
+	r, err := NewRunner(newConfig("foo"), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	// The de-duplication manager is replaced by one for the new templates.
+	prev := r.dedup
+	if err := r.reload(newConfig("bar")); err != nil {
+		t.Fatal(err)
+	}
+	if r.dedup == prev {
+		t.Fatal("expected de-duplication manager to be replaced")
+	}
+	if l := len(r.dedup.templates); l != 1 || r.dedup.templates[0] != r.templates[0] {
+		t.Errorf("\nexp: %#v\nact: %#v", r.templates, r.dedup.templates)
+	}
+	if _, ok := r.Status().Dedup.Leader[r.templates[0].ID()]; !ok {
+		t.Errorf("expected status of template %q", r.templates[0].ID())
+	}
+
+	// Changing the options of de-duplication requires a restart.
+	c := newConfig("bar")
+	c.Dedup.Prefix = config.String("other/")
+	if err := r.reload(c); err != ErrRestartRequired {
+		t.Fatalf("\nexp: %#v\nact: %#v", ErrRestartRequired, err)
+	}
+}
+
+func TestRunner_Reload_renderTimeout(t *testing.T) {
+	newConfig := func(timeout time.Duration, contents ...string) *config.Config {
+		templates := make(config.TemplateConfigs, 0, len(contents))
+		for _, c := range contents {
+			templates = append(templates, &config.TemplateConfig{
+				Contents: config.String(c),
+			})
+		}
+		return &config.Config{
+			Consul: &config.ConsulConfig{
+				Address: config.String("127.0.0.1:1"),
+			},
+			RenderTimeout: config.TimeDuration(timeout),
+			Templates:     &templates,
+		}
+	}
+
+	t.Run("added_timeout", func(t *testing.T) {
+		r, err := NewRunner(newConfig(0, `{{ key "reload-foo" }}`), true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go r.Start()
+		defer r.Stop()
+
+		if err := r.Reload(newConfig(100*time.Millisecond, `{{ key "reload-foo" }}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-r.ErrCh:
+			if _, ok := err.(*ErrRenderTimeout); !ok {
+				t.Fatal(err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+
+	t.Run("added_template", func(t *testing.T) {
+		r, err := NewRunner(newConfig(200*time.Millisecond, "foo"), true, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go r.Start()
+		defer r.Stop()
+
+		// The template added after the original timeout has passed is given the
+		// whole timeout from the reload.
+		time.Sleep(300 * time.Millisecond)
+		c := newConfig(200*time.Millisecond, "foo", `{{ key "reload-foo" }}`)
+		if err := r.Reload(c); err != nil {
+			t.Fatal(err)
+		}
+		reloaded := time.Now()
+
+		select {
+		case err := <-r.ErrCh:
+			if _, ok := err.(*ErrRenderTimeout); !ok {
+				t.Fatal(err)
+			}
+			if d := time.Since(reloaded); d < 150*time.Millisecond {
+				t.Errorf("expected the timeout to be counted from the reload, after %s", d)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+}
+
+func TestRunner_replaceChildren(t *testing.T) {
+	a := &execChild{config: &config.ExecConfig{Command: config.String("a")}}
+	b := &execChild{config: &config.ExecConfig{Command: config.String("b")}}
+	r := &Runner{
+		children: []*execChild{a, b},
+		logger:   logging.New("runner"),
+	}
+
+	r.replaceChildren([]*execChild{
+		&execChild{config: &config.ExecConfig{Command: config.String("a")}},
+		&execChild{config: &config.ExecConfig{Command: config.String("c")}},
+	})
+
+	if r.children[0] != a {
+		t.Errorf("expected child a to be kept")
+	}
+	if a.removed {
+		t.Errorf("expected child a not to be removed")
+	}
+	if r.children[1] == b || !b.removed {
+		t.Errorf("expected child b to be removed")
+	}
+	if cmd := config.StringVal(r.children[1].config.Command); cmd != "c" {
+		t.Errorf("\nexp: %#v\nact: %#v", "c", cmd)
+	}
+}
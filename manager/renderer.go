@@ -6,19 +6,43 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
 
+	"github.com/hashicorp/consul-template/logging"
 	"github.com/pkg/errors"
 )
 
 // RenderInput is used as input to the render function.
 type RenderInput struct {
-	Backup    bool
-	Contents  []byte
-	Dry       bool
-	DryStream io.Writer
-	Path      string
-	Perms     os.FileMode
+	Backup            bool
+	Check             func(path string) error
+	Contents          []byte
+	Diff              bool
+	Dry               bool
+	DryStream         io.Writer
+	Group             string
+	Logger            *logging.Logger
+	Path              string
+	Perms             os.FileMode
+	PreserveOwnership bool
+	RedactRemoved     bool
+	Secrets           []string
+	User              string
+}
+
+// Ownership is the owner to give a file written by AtomicWrite.
+type Ownership struct {
+	// User and Group are the name or numeric ID of the user and group which
+	// should own the file. An empty value leaves it unchanged.
+	User  string
+	Group string
+
+	// Preserve keeps the owner and group of the existing file, unless User or
+	// Group is given. This only applies when running as root, since no other
+	// user can give a file away.
+	Preserve bool
 }
 
 // RenderResult is returned and stored. It contains the status of the render
@@ -37,6 +61,10 @@ type RenderResult struct {
 	// Contents are the actual contents of the resulting template from the render
 	// operation.
 	Contents []byte
+
+	// Existing are the contents of the destination before the render, or nil if
+	// it did not exist.
+	Existing []byte
 }
 
 // Render atomically renders a file contents to disk, returning a result of
@@ -55,18 +83,50 @@ func Render(i *RenderInput) (*RenderResult, error) {
 		}, nil
 	}
 
+	var diff string
+	if i.Diff {
+		diff, err = Diff(&DiffInput{
+			Path:          i.Path,
+			Existing:      existing,
+			Contents:      i.Contents,
+			Secrets:       i.Secrets,
+			RedactRemoved: i.RedactRemoved,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed diffing file")
+		}
+	}
+
+	logger := i.Logger
+	if logger == nil {
+		logger = logging.New("runner")
+	}
+
 	if i.Dry {
-		fmt.Fprintf(i.DryStream, "> %s\n%s", i.Path, i.Contents)
+		if i.Diff {
+			fmt.Fprint(i.DryStream, diff)
+		} else {
+			fmt.Fprintf(i.DryStream, "> %s\n%s", i.Path, i.Contents)
+		}
 	} else {
-		if err := AtomicWrite(i.Path, i.Contents, i.Perms, i.Backup); err != nil {
+		owner := &Ownership{
+			User:     i.User,
+			Group:    i.Group,
+			Preserve: i.PreserveOwnership,
+		}
+		if err := AtomicWrite(i.Path, i.Contents, i.Perms, i.Backup, owner, i.Check); err != nil {
 			return nil, errors.Wrap(err, "failed writing file")
 		}
+		if i.Diff {
+			logger.Printf("[INFO] diff for %s:\n%s", i.Path, diff)
+		}
 	}
 
 	return &RenderResult{
 		DidRender:   true,
 		WouldRender: true,
 		Contents:    i.Contents,
+		Existing:    existing,
 	}, nil
 }
 
@@ -83,9 +143,18 @@ func Render(i *RenderInput) (*RenderResult, error) {
 // permissions 0644. To use a different permission, create the destination file
 // first or use `chmod` in a Command.
 //
+// If owner is given, the TempFile is given that owner before it is renamed, and
+// so is the backup. The extended attributes of an existing destination, such as
+// its SELinux label, are copied onto the TempFile, since a rename would
+// otherwise replace them with those inherited from the parent directory.
+//
+// If check is given, it is called with the path to the TempFile just before it
+// is renamed. If the check returns an error, the destination and its backup are
+// left untouched and an ErrCheckFailed is returned.
+//
 // If no errors occur, the Tempfile is "renamed" (moved) to the destination
 // path.
-func AtomicWrite(path string, contents []byte, perms os.FileMode, backup bool) error {
+func AtomicWrite(path string, contents []byte, perms os.FileMode, backup bool, owner *Ownership, check func(string) error) error {
 	if path == "" {
 		return fmt.Errorf("missing destination")
 	}
@@ -115,18 +184,49 @@ func AtomicWrite(path string, contents []byte, perms os.FileMode, backup bool) e
 		return err
 	}
 
+	existing, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		existing = nil
+	}
+
+	uid, gid, err := owner.ids(existing)
+	if err != nil {
+		return err
+	}
+
+	// Change the owner before the permissions, since changing the owner may
+	// clear the setuid and setgid bits.
+	if err := chown(f.Name(), uid, gid); err != nil {
+		return err
+	}
+
 	if err := os.Chmod(f.Name(), perms); err != nil {
 		return err
 	}
 
+	if existing != nil {
+		copyXattrs(path, f.Name())
+	}
+
+	if check != nil {
+		if err := check(f.Name()); err != nil {
+			return NewErrCheckFailed(path, err)
+		}
+	}
+
 	// If we got this far, it means we are about to save the file. Copy the
 	// current contents of the file onto disk (if it exists) so we have a backup.
-	if backup {
-		if _, err := os.Stat(path); !os.IsNotExist(err) {
-			if err := copyFile(path, path+".bak"); err != nil {
-				return err
-			}
+	if backup && existing != nil {
+		if err := copyFile(path, path+".bak"); err != nil {
+			return err
+		}
+		if err := chown(path+".bak", uid, gid); err != nil {
+			return err
 		}
+		copyXattrs(path, path+".bak")
 	}
 
 	if err := os.Rename(f.Name(), path); err != nil {
@@ -160,3 +260,70 @@ func copyFile(src, dst string) error {
 	}
 	return d.Close()
 }
+
+// ids returns the user and group IDs to give a file which replaces the given
+// existing file, which may be nil. An ID of -1 means it should not be changed.
+func (o *Ownership) ids(existing os.FileInfo) (int, int, error) {
+	uid, gid := -1, -1
+	if o == nil {
+		return uid, gid, nil
+	}
+
+	if o.Preserve && existing != nil && os.Geteuid() == 0 {
+		uid, gid = fileOwner(existing)
+	}
+
+	if o.User != "" {
+		id, err := lookupUser(o.User)
+		if err != nil {
+			return 0, 0, err
+		}
+		uid = id
+	}
+
+	if o.Group != "" {
+		id, err := lookupGroup(o.Group)
+		if err != nil {
+			return 0, 0, err
+		}
+		gid = id
+	}
+
+	return uid, gid, nil
+}
+
+// lookupUser returns the ID of the given user name or numeric ID.
+func lookupUser(s string) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		return id, nil
+	}
+
+	u, err := user.Lookup(s)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed looking up user")
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// lookupGroup returns the ID of the given group name or numeric ID.
+func lookupGroup(s string) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		return id, nil
+	}
+
+	g, err := user.LookupGroup(s)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed looking up group")
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// chown changes the owner of the file at path, unless both IDs are -1.
+func chown(path string, uid, gid int) error {
+	if uid == -1 && gid == -1 {
+		return nil
+	}
+
+	logging.New("runner").With("path", path).Printf("[TRACE] changing owner of %s to %d:%d", path, uid, gid)
+	return os.Chown(path, uid, gid)
+}
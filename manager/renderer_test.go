@@ -2,6 +2,7 @@ package manager
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -25,7 +26,7 @@ func TestAtomicWrite(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if err := AtomicWrite(outFile.Name(), nil, 0644, false); err != nil {
+		if err := AtomicWrite(outFile.Name(), nil, 0644, false, nil, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -46,7 +47,7 @@ func TestAtomicWrite(t *testing.T) {
 		}
 		os.Chmod(outFile.Name(), 0644)
 
-		if err := AtomicWrite(outFile.Name(), nil, 0644, false); err != nil {
+		if err := AtomicWrite(outFile.Name(), nil, 0644, false, nil, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -70,7 +71,7 @@ func TestAtomicWrite(t *testing.T) {
 
 		// Try AtomicWrite to a file that doesn't exist yet
 		file := filepath.Join(outDir, "nope")
-		if err := AtomicWrite(file, nil, 0644, false); err != nil {
+		if err := AtomicWrite(file, nil, 0644, false, nil, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -96,7 +97,7 @@ func TestAtomicWrite(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if err := AtomicWrite(outFile.Name(), []byte("after"), 0644, true); err != nil {
+		if err := AtomicWrite(outFile.Name(), []byte("after"), 0644, true, nil, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -131,7 +132,7 @@ func TestAtomicWrite(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		if err := AtomicWrite(outFile.Name(), nil, 0644, true); err != nil {
+		if err := AtomicWrite(outFile.Name(), nil, 0644, true, nil, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -144,4 +145,216 @@ func TestAtomicWrite(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("check_fails", func(t *testing.T) {
+		outDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outDir)
+		outFile, err := ioutil.TempFile(outDir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := outFile.Write([]byte("before")); err != nil {
+			t.Fatal(err)
+		}
+
+		var checked []byte
+		check := func(path string) error {
+			checked, _ = ioutil.ReadFile(path)
+			return errors.New("bad")
+		}
+
+		err = AtomicWrite(outFile.Name(), []byte("after"), 0644, true, nil, check)
+		if _, ok := err.(*ErrCheckFailed); !ok {
+			t.Fatalf("expected %#v to be an ErrCheckFailed", err)
+		}
+
+		if !bytes.Equal(checked, []byte("after")) {
+			t.Errorf("expected %q to be %q", checked, []byte("after"))
+		}
+
+		f, err := ioutil.ReadFile(outFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(f, []byte("before")) {
+			t.Errorf("expected %q to be %q", f, []byte("before"))
+		}
+
+		// The backup is only taken when the file is replaced.
+		if _, err := os.Stat(outFile.Name() + ".bak"); !os.IsNotExist(err) {
+			t.Errorf("expected no backup")
+		}
+
+		// The temporary file is removed.
+		files, err := ioutil.ReadDir(outDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Errorf("expected %d to be %d", len(files), 1)
+		}
+	})
+
+	t.Run("preserves_ownership", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("must be root to change ownership")
+		}
+
+		outDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outDir)
+		outFile, err := ioutil.TempFile(outDir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chown(outFile.Name(), 1234, 5678); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := AtomicWrite(outFile.Name(), nil, 0644, true, &Ownership{Preserve: true}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, path := range []string{outFile.Name(), outFile.Name() + ".bak"} {
+			stat, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uid, gid := fileOwner(stat); uid != 1234 || gid != 5678 {
+				t.Errorf("expected %d:%d to be %d:%d", uid, gid, 1234, 5678)
+			}
+		}
+	})
+
+	t.Run("ownership", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("must be root to change ownership")
+		}
+
+		outDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outDir)
+		outFile, err := ioutil.TempFile(outDir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chown(outFile.Name(), 1234, 5678); err != nil {
+			t.Fatal(err)
+		}
+
+		owner := &Ownership{User: "4321", Group: "8765", Preserve: true}
+		if err := AtomicWrite(outFile.Name(), nil, 0644, true, owner, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, path := range []string{outFile.Name(), outFile.Name() + ".bak"} {
+			stat, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uid, gid := fileOwner(stat); uid != 4321 || gid != 8765 {
+				t.Errorf("expected %d:%d to be %d:%d", uid, gid, 4321, 8765)
+			}
+		}
+	})
+
+	t.Run("ownership_not_preserved", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("must be root to change ownership")
+		}
+
+		outDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outDir)
+		outFile, err := ioutil.TempFile(outDir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chown(outFile.Name(), 1234, 5678); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := AtomicWrite(outFile.Name(), nil, 0644, false, &Ownership{}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		stat, err := os.Stat(outFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uid, _ := fileOwner(stat); uid != 0 {
+			t.Errorf("expected %d to be %d", uid, 0)
+		}
+	})
+
+	t.Run("unknown_user", func(t *testing.T) {
+		outDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outDir)
+
+		file := filepath.Join(outDir, "nope")
+		owner := &Ownership{User: "not-a-real-user"}
+		if err := AtomicWrite(file, nil, 0644, false, owner, nil); err == nil {
+			t.Fatal("expected error")
+		}
+
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to exist", file)
+		}
+	})
+}
+
+func TestRender(t *testing.T) {
+	t.Run("dry_diff", func(t *testing.T) {
+		outDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outDir)
+
+		file := filepath.Join(outDir, "out")
+		if err := ioutil.WriteFile(file, []byte("a\nb\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		result, err := Render(&RenderInput{
+			Contents:  []byte("a\nsecret\n"),
+			Diff:      true,
+			Dry:       true,
+			DryStream: &out,
+			Path:      file,
+			Secrets:   []string{"secret"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.DidRender {
+			t.Errorf("expected to render")
+		}
+
+		exp := "--- " + file + "\n+++ " + file + "\n@@ -1,2 +1,2 @@\n a\n-<redacted>\n+<redacted>\n"
+		if out.String() != exp {
+			t.Errorf("\nexp: %q\nact: %q", exp, out.String())
+		}
+
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(contents) != "a\nb\n" {
+			t.Errorf("expected %q to be unchanged", contents)
+		}
+	})
 }
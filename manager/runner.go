@@ -4,15 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"sync"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/consul-template/child"
 	"github.com/hashicorp/consul-template/config"
 	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/logging"
+	"github.com/hashicorp/consul-template/systemd"
 	"github.com/hashicorp/consul-template/template"
 	"github.com/hashicorp/consul-template/watch"
 	"github.com/hashicorp/go-multierror"
@@ -56,10 +59,25 @@ type Runner struct {
 	// templates is the list of calculated templates.
 	templates []*template.Template
 
+	// groups is the list of template groups, in the order they were
+	// configured.
+	groups []*templateGroup
+
+	// renderedData is a mapping of a template ID to the data of each dependency
+	// it used when it last rendered.
+	renderedData map[string]map[string]interface{}
+
+	// renderedVault is whether the last render to each destination used Vault.
+	// It is keyed by destination rather than template ID, since a template whose
+	// contents change gets a new ID but replaces the same file.
+	renderedVault map[string]bool
+
 	// renderEvents is a mapping of a template ID to the render event.
 	renderEvents map[string]*RenderEvent
 
-	// renderEventLock protects access into the renderEvents map
+	// renderEventLock protects access into the renderEvents map, and to
+	// templates, ctemplatesMap and dedup, which are replaced by a reload, from
+	// other goroutines than the runner's.
 	renderEventsLock sync.RWMutex
 
 	// renderedCh is used to signal that a template has been rendered
@@ -77,18 +95,22 @@ type Runner struct {
 	// dependenciesLock is a lock around touching the dependencies map.
 	dependenciesLock sync.Mutex
 
+	// clients is the set of Consul and Vault clients the watcher and other
+	// components of this runner use.
+	clients *dep.ClientSet
+
 	// watcher is the watcher this runner is using.
 	watcher *watch.Watcher
 
 	// brain is the internal storage database of returned dependency data.
 	brain *template.Brain
 
-	// child is the child process under management. This may be nil if not running
-	// in exec mode.
-	child *child.Child
-
-	// childLock is the internal lock around the child process.
-	childLock sync.RWMutex
+	// children are the processes under supervision in exec mode, in the order
+	// they are started. childLock is the internal lock around them, and
+	// childEventCh is where they report what happens to them.
+	children     []*execChild
+	childLock    sync.RWMutex
+	childEventCh chan *childEvent
 
 	// quiescenceMap is the map of templates to their quiescence timers.
 	// quiescenceCh is the channel where templates report returns from quiescence
@@ -96,9 +118,19 @@ type Runner struct {
 	quiescenceMap map[string]*quiescence
 	quiescenceCh  chan *template.Template
 
+	// reloadCh is where Reload asks the runner to apply a new configuration.
+	reloadCh chan *reloadRequest
+
 	// dedup is the deduplication manager if enabled
 	dedup *DedupManager
 
+	// semaphores limits how many instances run a template command at the same
+	// time, if any template asks for it.
+	semaphores *semaphoreManager
+
+	// status is the HTTP status listener if enabled
+	status *statusServer
+
 	// Env represents a custom set of environment variables to populate the
 	// template and command runtime with. These environment variables will be
 	// available in both the command's environment as well as the template's
@@ -110,6 +142,14 @@ type Runner struct {
 
 	// stopped is a boolean of whether the runner is stopped
 	stopped bool
+
+	// logger is the logger of the runner
+	logger *logging.Logger
+
+	// notifier tells the service manager when the runner is ready, if it was
+	// started by one, and notifiedReady is whether it has been told.
+	notifier      *systemd.Notifier
+	notifiedReady bool
 }
 
 // RenderEvent captures the time and events that occurred for a template
@@ -161,18 +201,28 @@ type RenderEvent struct {
 
 	// LastDidRender marks the last time the template was written to disk.
 	LastDidRender time.Time
+
+	// CheckErr is the error from the check of the rendered contents, if the
+	// check failed. The file on disk is left untouched when a check fails.
+	CheckErr error
+
+	// LastCheckFailed marks the last time the check of the rendered contents
+	// failed.
+	LastCheckFailed time.Time
 }
 
 // NewRunner accepts a slice of TemplateConfigs and returns a pointer to the new
 // Runner and any error that occurred during creation.
 func NewRunner(config *config.Config, dry, once bool) (*Runner, error) {
-	log.Printf("[INFO] (runner) creating new runner (dry: %v, once: %v)", dry, once)
-
 	runner := &Runner{
 		config: config,
 		dry:    dry,
 		once:   once,
+		logger: logging.New("runner"),
+
+		notifier: systemd.NewNotifier(),
 	}
+	runner.logger.Printf("[INFO] creating new runner (dry: %v, once: %v)", dry, once)
 
 	if err := runner.init(); err != nil {
 		return nil, err
@@ -185,7 +235,7 @@ func NewRunner(config *config.Config, dry, once bool) (*Runner, error) {
 // this function to push an item onto the runner's error channel and the halt
 // execution. This function is blocking and should be called as a goroutine.
 func (r *Runner) Start() {
-	log.Printf("[INFO] (runner) starting")
+	r.logger.Printf("[INFO] starting")
 
 	// Create the pid before doing anything.
 	if err := r.storePid(); err != nil {
@@ -193,6 +243,11 @@ func (r *Runner) Start() {
 		return
 	}
 
+	// Start the status listener
+	if r.status != nil {
+		r.status.start()
+	}
+
 	// Start the de-duplication manager
 	var dedupCh <-chan struct{}
 	if r.dedup != nil {
@@ -203,17 +258,27 @@ func (r *Runner) Start() {
 		dedupCh = r.dedup.UpdateCh()
 	}
 
-	// Setup the child process exit channel
-	var childExitCh <-chan int
+	// Ping the service manager's watchdog at half of its interval. This runs
+	// on its own, since a run may take longer than the interval while its
+	// commands run or wait for a semaphore.
+	if interval := r.notifier.WatchdogInterval(); interval > 0 {
+		go r.watchdog(interval / 2)
+	}
 
 	// Fire an initial run to parse all the templates and setup the first-pass
 	// dependencies. This also forces any templates that have no dependencies to
 	// be rendered immediately (since they are already renderable).
-	log.Printf("[DEBUG] (runner) running initial templates")
+	r.logger.Printf("[DEBUG] running initial templates")
+	renderStart := time.Now()
 	if err := r.Run(); err != nil {
 		r.ErrCh <- err
 		return
 	}
+	r.notifyRendered()
+
+	// Report the templates which have not rendered once their render timeout
+	// passes.
+	renderTimeoutCh := r.nextRenderTimeout(renderStart)
 
 	for {
 		// Enable quiescence for all templates if we have specified wait
@@ -226,7 +291,7 @@ func (r *Runner) Start() {
 
 			for _, c := range r.templateConfigsFor(t) {
 				if *c.Wait.Enabled {
-					log.Printf("[DEBUG] (runner) enabling template-specific quiescence for %q", t.ID())
+					r.logger.Printf("[DEBUG] enabling template-specific quiescence for %q", t.ID())
 					r.quiescenceMap[t.ID()] = newQuiescence(
 						r.quiescenceCh, *c.Wait.Min, *c.Wait.Max, t)
 					continue NEXT_Q
@@ -234,7 +299,7 @@ func (r *Runner) Start() {
 			}
 
 			if *r.config.Wait.Enabled {
-				log.Printf("[DEBUG] (runner) enabling global quiescence for %q", t.ID())
+				r.logger.Printf("[DEBUG] enabling global quiescence for %q", t.ID())
 				r.quiescenceMap[t.ID()] = newQuiescence(
 					r.quiescenceCh, *r.config.Wait.Min, *r.config.Wait.Max, t)
 				continue NEXT_Q
@@ -243,75 +308,34 @@ func (r *Runner) Start() {
 
 		// Warn the user if they are watching too many dependencies.
 		if r.watcher.Size() > saneViewLimit {
-			log.Printf("[WARN] (runner) watching %d dependencies - watching this "+
+			r.logger.Printf("[WARN] watching %d dependencies - watching this "+
 				"many dependencies could DDoS your consul cluster", r.watcher.Size())
 		} else {
-			log.Printf("[DEBUG] (runner) watching %d dependencies", r.watcher.Size())
+			r.logger.Printf("[DEBUG] watching %d dependencies", r.watcher.Size())
 		}
 
 		if r.allTemplatesRendered() {
-			log.Printf("[DEBUG] (runner) all templates rendered")
-
-			// If an exec command was given and a command is not currently running,
-			// spawn the child process for supervision.
-			if config.StringPresent(r.config.Exec.Command) {
-				// Lock the child because we are about to check if it exists.
-				r.childLock.Lock()
-
-				log.Printf("[TRACE] (runner) acquired child lock for command, spawning")
-
-				if r.child == nil {
-					env := r.config.Exec.Env.Copy()
-					env.Custom = append(r.childEnv(), env.Custom...)
-					child, err := spawnChild(&spawnChildInput{
-						Stdin:        r.inStream,
-						Stdout:       r.outStream,
-						Stderr:       r.errStream,
-						Command:      config.StringVal(r.config.Exec.Command),
-						Env:          env.Env(),
-						ReloadSignal: config.SignalVal(r.config.Exec.ReloadSignal),
-						KillSignal:   config.SignalVal(r.config.Exec.KillSignal),
-						KillTimeout:  config.TimeDurationVal(r.config.Exec.KillTimeout),
-						Splay:        config.TimeDurationVal(r.config.Exec.Splay),
-					})
-					if err != nil {
-						r.ErrCh <- err
-						r.childLock.Unlock()
-						return
-					}
-					r.child = child
-				}
+			r.logger.Printf("[DEBUG] all templates rendered")
 
-				// Unlock the child, we are done now.
-				r.childLock.Unlock()
-
-				// It's possible that we didn't start a process, in which case no
-				// channel is returned. If we did get a new exitCh, that means a child
-				// was spawned, so we need to watch a new exitCh. It is also possible
-				// that during a run, the child process was restarted, which means a
-				// new exit channel should be used.
-				nexitCh := r.child.ExitCh()
-				if nexitCh != nil {
-					childExitCh = nexitCh
-				}
+			// Spawn any child processes which are not running yet for supervision.
+			if err := r.startChildren(); err != nil {
+				r.ErrCh <- err
+				return
 			}
 
 			// If we are running in once mode and all our templates are rendered,
 			// then we should exit here.
 			if r.once {
-				log.Printf("[INFO] (runner) once mode and all templates rendered")
+				r.logger.Printf("[INFO] once mode and all templates rendered")
 
-				if r.child != nil {
+				if len(r.children) > 0 {
 					r.stopDedup()
 					r.stopWatcher()
 
-					log.Printf("[INFO] (runner) waiting for child process to exit")
-					select {
-					case c := <-childExitCh:
-						log.Printf("[INFO] (runner) child process died")
-						r.ErrCh <- NewErrChildDied(c)
+					r.logger.Printf("[INFO] waiting for child processes to exit")
+					if err := r.waitChildren(); err != nil {
+						r.ErrCh <- err
 						return
-					case <-r.DoneCh:
 					}
 				}
 
@@ -348,28 +372,55 @@ func (r *Runner) Start() {
 			// We may get triggered by the de-duplication manager for either a change
 			// in leadership (acquired or lost lock), or an update of data for a template
 			// that we are watching.
-			log.Printf("[INFO] (runner) watcher triggered by de-duplication manager")
+			r.logger.Printf("[INFO] watcher triggered by de-duplication manager")
 			break OUTER
 
 		case err := <-r.watcher.ErrCh():
 			// Push the error back up the stack
-			log.Printf("[ERR] (runner) watcher reported error: %s", err)
+			r.logger.Printf("[ERR] watcher reported error: %s", err)
 			r.ErrCh <- err
 			return
 
 		case tmpl := <-r.quiescenceCh:
 			// Remove the quiescence for this template from the map. This will force
 			// the upcoming Run call to actually evaluate and render the template.
-			log.Printf("[DEBUG] (runner) received template %q from quiescence", tmpl.ID())
+			r.logger.With("template_id", tmpl.ID()).Printf("[DEBUG] received template %q from quiescence", tmpl.ID())
 			delete(r.quiescenceMap, tmpl.ID())
 
-		case c := <-childExitCh:
-			log.Printf("[INFO] (runner) child process died")
-			r.ErrCh <- NewErrChildDied(c)
-			return
+		case e := <-r.childEventCh:
+			if err := r.handleChildEvent(e); err != nil {
+				r.ErrCh <- err
+				return
+			}
+			continue
+
+		case <-renderTimeoutCh:
+			if err := r.checkRenderTimeouts(renderStart); err != nil {
+				r.ErrCh <- err
+				return
+			}
+			renderTimeoutCh = r.nextRenderTimeout(renderStart)
+			continue
+
+		case req := <-r.reloadCh:
+			err := r.reload(req.config)
+			req.errCh <- err
+			if err != nil {
+				continue
+			}
+
+			// The render timeouts of the new configuration are counted from the
+			// reload, for the templates which have not rendered yet.
+			renderStart = time.Now()
+			renderTimeoutCh = r.nextRenderTimeout(renderStart)
+
+			// The de-duplication manager is replaced by the reload.
+			if r.dedup != nil {
+				dedupCh = r.dedup.UpdateCh()
+			}
 
 		case <-r.DoneCh:
-			log.Printf("[INFO] (runner) received finish")
+			r.logger.Printf("[INFO] received finish")
 			return
 		}
 
@@ -379,6 +430,7 @@ func (r *Runner) Start() {
 			r.ErrCh <- err
 			return
 		}
+		r.notifyRendered()
 	}
 }
 
@@ -391,14 +443,16 @@ func (r *Runner) Stop() {
 		return
 	}
 
-	log.Printf("[INFO] (runner) stopping")
+	r.logger.Printf("[INFO] stopping")
 	r.stopDedup()
 	r.stopWatcher()
-	r.stopChild()
+	r.stopChildren()
+	r.stopSemaphores()
+	r.stopStatus()
 
 	if err := r.deletePid(); err != nil {
-		log.Printf("[WARN] (runner) could not remove pid at %q: %s",
-			r.config.PidFile, err)
+		r.logger.Printf("[WARN] could not remove pid at %q: %s",
+			config.StringVal(r.config.PidFile), err)
 	}
 
 	r.stopped = true
@@ -419,39 +473,45 @@ func (r *Runner) RenderEventCh() <-chan struct{} {
 }
 
 // RenderEvents returns the render events for each template was rendered. The
-// map is keyed by template ID.
+// map is keyed by template ID. Each event is a copy, since the runner updates
+// its events while template groups are published.
 func (r *Runner) RenderEvents() map[string]*RenderEvent {
 	r.renderEventsLock.RLock()
 	defer r.renderEventsLock.RUnlock()
 
 	times := make(map[string]*RenderEvent, len(r.renderEvents))
 	for k, v := range r.renderEvents {
-		times[k] = v
+		event := *v
+		times[k] = &event
 	}
 	return times
 }
 
 func (r *Runner) stopDedup() {
 	if r.dedup != nil {
-		log.Printf("[DEBUG] (runner) stopping de-duplication manager")
+		r.logger.Printf("[DEBUG] stopping de-duplication manager")
 		r.dedup.Stop()
 	}
 }
 
 func (r *Runner) stopWatcher() {
 	if r.watcher != nil {
-		log.Printf("[DEBUG] (runner) stopping watcher")
+		r.logger.Printf("[DEBUG] stopping watcher")
 		r.watcher.Stop()
 	}
 }
 
-func (r *Runner) stopChild() {
-	r.childLock.RLock()
-	defer r.childLock.RUnlock()
+func (r *Runner) stopSemaphores() {
+	if r.semaphores != nil {
+		r.logger.Printf("[DEBUG] stopping command semaphores")
+		r.semaphores.stop()
+	}
+}
 
-	if r.child != nil {
-		log.Printf("[DEBUG] (runner) stopping child process")
-		r.child.Stop()
+func (r *Runner) stopStatus() {
+	if r.status != nil {
+		r.logger.Printf("[DEBUG] stopping status listener")
+		r.status.stop()
 	}
 }
 
@@ -477,20 +537,27 @@ func (r *Runner) Receive(d dep.Dependency, data interface{}) {
 	//
 	// and by "little" bug, I mean really big bug.
 	if _, ok := r.dependencies[d.String()]; ok {
-		log.Printf("[DEBUG] (runner) receiving dependency %s", d)
+		r.logger.Printf("[DEBUG] receiving dependency %s", d)
 		r.brain.Remember(d, data)
 	}
 }
 
-// Signal sends a signal to the child process, if it exists. Any errors that
+// Signal sends a signal to each child process which exists. Any errors that
 // occur are returned.
 func (r *Runner) Signal(s os.Signal) error {
 	r.childLock.RLock()
 	defer r.childLock.RUnlock()
-	if r.child == nil {
-		return nil
+
+	var errs *multierror.Error
+	for _, c := range r.children {
+		if c.child == nil {
+			continue
+		}
+		if err := c.child.Signal(s); err != nil {
+			errs = multierror.Append(errs, err)
+		}
 	}
-	return r.child.Signal(s)
+	return errs.ErrorOrNil()
 }
 
 // Run iterates over each template in this Runner and conditionally executes
@@ -501,20 +568,19 @@ func (r *Runner) Signal(s os.Signal) error {
 // Please note that all templates are rendered **and then** any commands are
 // executed.
 func (r *Runner) Run() error {
-	log.Printf("[INFO] (runner) initiating run")
+	r.logger.Printf("[INFO] initiating run")
 
 	var newRenderEvent, wouldRenderAny, renderedAny bool
 	runCtx := &templateRunCtx{
 		depsMap: make(map[string]dep.Dependency),
+		groups:  make(map[*templateGroup][]*RenderEvent),
+		renders: make(map[*config.TemplateConfig]*commandRender),
 	}
 
 	for _, tmpl := range r.templates {
 		event, err := r.runTemplate(tmpl, runCtx)
-		if err != nil {
-			return err
-		}
 
-		// If there was a render event store it
+		// If there was a render event store it.
 		if event != nil {
 			r.renderEventsLock.Lock()
 			r.renderEvents[tmpl.ID()] = event
@@ -533,6 +599,32 @@ func (r *Runner) Run() error {
 				renderedAny = true
 			}
 		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Publish each template group with a member which ran. This happens after
+	// every template has run, so that the group is published once with all of
+	// its changes.
+	for _, g := range r.groups {
+		events, ok := runCtx.groups[g]
+		if !ok {
+			continue
+		}
+
+		result, err := r.runGroup(g, events, runCtx)
+		if err != nil {
+			return err
+		}
+
+		if result.WouldRender {
+			wouldRenderAny = true
+		}
+		if result.DidRender {
+			renderedAny = true
+		}
 	}
 
 	// Check if we need to deliver any rendered signals
@@ -555,39 +647,24 @@ func (r *Runner) Run() error {
 	// Perform the diff and update the known dependencies.
 	r.diffAndUpdateDeps(runCtx.depsMap)
 
-	// Execute each command in sequence, collecting any errors that occur - this
-	// ensures all commands execute at least once.
-	var errs []error
-	for _, t := range runCtx.commands {
-		command := config.StringVal(t.Exec.Command)
-		log.Printf("[INFO] (runner) executing command %q from %s", command, t.Display())
-		env := t.Exec.Env.Copy()
-		env.Custom = append(r.childEnv(), env.Custom...)
-		if _, err := spawnChild(&spawnChildInput{
-			Stdin:        r.inStream,
-			Stdout:       r.outStream,
-			Stderr:       r.errStream,
-			Command:      command,
-			Env:          env.Env(),
-			Timeout:      config.TimeDurationVal(t.Exec.Timeout),
-			ReloadSignal: config.SignalVal(t.Exec.ReloadSignal),
-			KillSignal:   config.SignalVal(t.Exec.KillSignal),
-			KillTimeout:  config.TimeDurationVal(t.Exec.KillTimeout),
-			Splay:        config.TimeDurationVal(t.Exec.Splay),
-		}); err != nil {
-			s := fmt.Sprintf("failed to execute command %q from %s", command, t.Display())
-			errs = append(errs, errors.Wrap(err, s))
-		}
-	}
+	// Execute each command in order, collecting any errors that occur - this
+	// ensures all commands execute at least once, unless a failed command asks
+	// to stop.
+	errs := r.runCommands(runCtx)
 
-	// If we got this far and have a child process, we need to send the reload
-	// signal to the child process.
-	if renderedAny && r.child != nil {
-		r.childLock.RLock()
-		if err := r.child.Reload(); err != nil {
+	// If we got this far and have child processes, we need to send the reload
+	// signal to each one which reads a rendered template.
+	if renderedAny {
+		if err := r.reloadChildren(runCtx.rendered); err != nil {
 			errs = append(errs, err)
 		}
-		r.childLock.RUnlock()
+	}
+
+	// A failed check is only logged and reported in the render event, since the
+	// next change may fix the contents. In once mode there is no next change, so
+	// the failure is returned.
+	if r.once {
+		errs = append(errs, runCtx.checkErrs...)
 	}
 
 	// If any errors were returned, convert them to an ErrorList for human
@@ -604,6 +681,10 @@ func (r *Runner) Run() error {
 }
 
 type templateRunCtx struct {
+	// checkErrs is the errors of the rendered contents which failed their
+	// check, and were not written.
+	checkErrs []error
+
 	// commands is the set of commands that will be executed after all templates
 	// have run. When adding to the commands, care should be taken not to
 	// duplicate any existing command from a previous template.
@@ -611,6 +692,17 @@ type templateRunCtx struct {
 
 	// depsMap is the set of dependencies shared across all templates.
 	depsMap map[string]dep.Dependency
+
+	// groups is the render events of the templates in each template group
+	// which ran, and so may need to be published.
+	groups map[*templateGroup][]*RenderEvent
+
+	// rendered is the templates which were rendered, used to decide which
+	// child processes to reload.
+	rendered []*config.TemplateConfig
+
+	// renders describes the render of each template which ran to its command.
+	renders map[*config.TemplateConfig]*commandRender
 }
 
 // runTemplate is used to run a particular template. It takes as input the
@@ -619,7 +711,8 @@ type templateRunCtx struct {
 // error that occured. The render event is nil in the case that the template has
 // been already rendered and is a once template or if there is an error.
 func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*RenderEvent, error) {
-	log.Printf("[DEBUG] (runner) checking template %s", tmpl.ID())
+	logger := r.logger.With("template_id", tmpl.ID())
+	logger.Printf("[DEBUG] checking template %s", tmpl.ID())
 
 	// Grab the last event
 	r.renderEventsLock.RLock()
@@ -635,6 +728,7 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 	if lastEvent != nil {
 		event.LastWouldRender = lastEvent.LastWouldRender
 		event.LastDidRender = lastEvent.LastDidRender
+		event.LastCheckFailed = lastEvent.LastCheckFailed
 	}
 
 	// Check if we are currently the leader instance
@@ -651,7 +745,7 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 		event, ok := r.renderEvents[tmpl.ID()]
 		r.renderEventsLock.RUnlock()
 		if ok && (event.WouldRender || event.DidRender) {
-			log.Printf("[DEBUG] (runner) once mode and already rendered")
+			logger.Printf("[DEBUG] once mode and already rendered")
 			return nil, nil
 		}
 	}
@@ -659,10 +753,12 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 	// Attempt to render the template, returning any missing dependencies and
 	// the rendered contents. If there are any missing dependencies, the
 	// contents cannot be rendered or trusted!
+	executeStart := time.Now()
 	result, err := tmpl.Execute(&template.ExecuteInput{
 		Brain: r.brain,
 		Env:   r.childEnv(),
 	})
+	metrics.MeasureSince([]string{"runner", "template", "execute"}, executeStart)
 	if err != nil {
 		return nil, errors.Wrap(err, tmpl.Source())
 	}
@@ -701,7 +797,7 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 	// If there are unwatched dependencies, start the watcher and exit since we
 	// won't have data.
 	if l := unwatched.Len(); l > 0 {
-		log.Printf("[DEBUG] (runner) was not watching %d dependencies", l)
+		logger.Printf("[DEBUG] was not watching %d dependencies", l)
 		for _, d := range unwatched.List() {
 			// If we are deduplicating, we must still handle non-sharable
 			// dependencies, since those will be ignored.
@@ -715,14 +811,14 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 	// If the template is missing data for some dependencies then we are not
 	// ready to render and need to move on to the next one.
 	if l := missing.Len(); l > 0 {
-		log.Printf("[DEBUG] (runner) missing data for %d dependencies", l)
+		logger.Printf("[DEBUG] missing data for %d dependencies", l)
 		return event, nil
 	}
 
 	// Trigger an update of the de-duplicaiton manager
 	if r.dedup != nil && isLeader {
 		if err := r.dedup.UpdateDeps(tmpl, used.List()); err != nil {
-			log.Printf("[ERR] (runner) failed to update dependency data for de-duplication: %v", err)
+			logger.Printf("[ERR] failed to update dependency data for de-duplication: %v", err)
 		}
 	}
 
@@ -733,23 +829,69 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 		return event, nil
 	}
 
+	// Values read from Vault must not appear in diffs.
+	var secrets []string
+	if config.BoolVal(r.config.Diff) {
+		secrets = r.secretsFor(used)
+	}
+
+	// Find what changed before any destination renders, since each one shares
+	// the data of the template.
+	render := r.newCommandRender(tmpl, used)
+
 	// For each template configuration that is tied to this template, attempt to
 	// render it to disk and accumulate commands for later use.
 	for _, templateConfig := range r.templateConfigsFor(tmpl) {
-		log.Printf("[DEBUG] (runner) rendering %s", templateConfig.Display())
+		cr := *render
+		runCtx.renders[templateConfig] = &cr
+
+		// Members of a template group are published with the rest of the group
+		// once every template has run.
+		if g := r.groupFor(templateConfig); g != nil {
+			logger.Printf("[DEBUG] staging %s for %s", templateConfig.Display(), g.config.Display())
+			g.contents[templateConfig] = result.Output
+			g.secrets[templateConfig] = secrets
+			g.redactRemoved[templateConfig] = r.redactRemoved(&cr, g.destination(templateConfig))
+			runCtx.groups[g] = append(runCtx.groups[g], event)
+			continue
+		}
+
+		logger.Printf("[DEBUG] rendering %s", templateConfig.Display())
 
 		// Render the template, taking dry mode into account
+		renderStart := time.Now()
 		result, err := Render(&RenderInput{
-			Backup:    config.BoolVal(templateConfig.Backup),
-			Contents:  result.Output,
-			Dry:       r.dry,
-			DryStream: r.outStream,
-			Path:      config.StringVal(templateConfig.Destination),
-			Perms:     config.FileModeVal(templateConfig.Perms),
+			Backup:            config.BoolVal(templateConfig.Backup),
+			Check:             r.checkFunc(templateConfig.Check, templateConfig.Exec.Env),
+			Contents:          result.Output,
+			Diff:              config.BoolVal(r.config.Diff),
+			Dry:               r.dry,
+			DryStream:         r.outStream,
+			Group:             config.StringVal(templateConfig.Group),
+			Logger:            logger,
+			Path:              config.StringVal(templateConfig.Destination),
+			Perms:             config.FileModeVal(templateConfig.Perms),
+			PreserveOwnership: config.BoolVal(templateConfig.PreserveOwnership),
+			RedactRemoved:     r.redactRemoved(&cr, config.StringVal(templateConfig.Destination)),
+			Secrets:           secrets,
+			User:              config.StringVal(templateConfig.User),
 		})
 		if err != nil {
+			// A failed check only skips this destination and its command, so the
+			// other templates are still rendered and their commands still run.
+			if _, ok := errors.Cause(err).(*ErrCheckFailed); ok {
+				logger.Printf("[ERR] rendered %s failed check, not replacing file: %s",
+					templateConfig.Display(), err)
+				metrics.IncrCounter([]string{"runner", "template", "check_failed"}, 1)
+				event.CheckErr = err
+				event.LastCheckFailed = time.Now().UTC()
+				runCtx.checkErrs = append(runCtx.checkErrs,
+					errors.Wrap(err, "error rendering "+templateConfig.Display()))
+				continue
+			}
 			return nil, errors.Wrap(err, "error rendering "+templateConfig.Display())
 		}
+		metrics.MeasureSince([]string{"runner", "template", "render"}, renderStart)
 
 		renderTime := time.Now().UTC()
 
@@ -760,6 +902,7 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 		// disk though.
 		if result.WouldRender {
 			// This event would have rendered
+			metrics.IncrCounter([]string{"runner", "template", "would_render"}, 1)
 			event.WouldRender = true
 			event.LastWouldRender = renderTime
 		}
@@ -767,11 +910,15 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 		// If we _actually_ rendered the template to disk, we want to run the
 		// appropriate commands.
 		if result.DidRender {
-			log.Printf("[INFO] (runner) rendered %s", templateConfig.Display())
+			logger.Printf("[INFO] rendered %s", templateConfig.Display())
 
 			// This event did render
+			metrics.IncrCounter([]string{"runner", "template", "rendered"}, 1)
 			event.DidRender = true
 			event.LastDidRender = renderTime
+			runCtx.rendered = append(runCtx.rendered, templateConfig)
+			r.recordRender(&cr, config.StringVal(templateConfig.Destination),
+				result.Existing, result.Contents)
 
 			// Update the contents
 			event.Contents = result.Contents
@@ -787,17 +934,7 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 				// definitions. If we inserted commands into a map, we would lose that
 				// relative ordering and people would be unhappy.
 				// if config.StringPresent(ctemplate.Command)
-				if c := config.StringVal(templateConfig.Exec.Command); c != "" {
-					existing := findCommand(templateConfig, runCtx.commands)
-					if existing != nil {
-						log.Printf("[DEBUG] (runner) skipping command %q from %s (already appended from %s)",
-							c, templateConfig.Display(), existing.Display())
-					} else {
-						log.Printf("[DEBUG] (runner) appending command %q from %s",
-							c, templateConfig.Display())
-						runCtx.commands = append(runCtx.commands, templateConfig)
-					}
-				}
+				runCtx.appendCommand(logger, templateConfig)
 			}
 		}
 	}
@@ -805,6 +942,151 @@ func (r *Runner) runTemplate(tmpl *template.Template, runCtx *templateRunCtx) (*
 	return event, nil
 }
 
+// appendCommand appends the command of the given template to the commands to
+// run, unless it has no command or the same command was already appended.
+func (runCtx *templateRunCtx) appendCommand(logger *logging.Logger, templateConfig *config.TemplateConfig) {
+	c := config.StringVal(templateConfig.Exec.Command)
+	if c == "" {
+		return
+	}
+
+	existing := findCommand(templateConfig, runCtx.commands)
+	if existing != nil {
+		logger.Printf("[DEBUG] skipping command %q from %s (already appended from %s)",
+			c, templateConfig.Display(), existing.Display())
+	} else {
+		logger.Printf("[DEBUG] appending command %q from %s",
+			c, templateConfig.Display())
+		runCtx.commands = append(runCtx.commands, templateConfig)
+	}
+}
+
+// runGroup publishes the given template group, and updates the render events
+// of its members which ran. The commands of the members are appended once if
+// the group was published.
+func (r *Runner) runGroup(g *templateGroup, events []*RenderEvent, runCtx *templateRunCtx) (*RenderResult, error) {
+	r.logger.Printf("[DEBUG] publishing %s", g.config.Display())
+
+	// The published generation is replaced, so read what it holds first.
+	existing := make(map[*config.TemplateConfig][]byte, len(g.members))
+	for _, m := range g.members {
+		if b, err := ioutil.ReadFile(g.destination(m)); err == nil {
+			existing[m] = b
+		}
+	}
+
+	publishStart := time.Now()
+	result, err := g.publish(&publishGroupInput{
+		Diff:      config.BoolVal(r.config.Diff),
+		Dry:       r.dry,
+		DryStream: r.outStream,
+		Check: func(c *config.TemplateConfig) func(string) error {
+			return r.checkFunc(c.Check, c.Exec.Env)
+		},
+		GroupCheck: r.checkFunc(g.config.Check, config.DefaultEnvConfig()),
+	})
+
+	now := time.Now().UTC()
+
+	r.renderEventsLock.Lock()
+	defer r.renderEventsLock.Unlock()
+
+	if err != nil {
+		if _, ok := errors.Cause(err).(*ErrCheckFailed); ok {
+			r.logger.Printf("[ERR] %s failed check, not publishing: %s", g.config.Display(), err)
+			metrics.IncrCounter([]string{"runner", "template_group", "check_failed"}, 1)
+			for _, e := range events {
+				e.CheckErr = err
+				e.LastCheckFailed = now
+			}
+			runCtx.checkErrs = append(runCtx.checkErrs,
+				errors.Wrap(err, "error publishing "+g.config.Display()))
+			return &RenderResult{}, nil
+		}
+		return nil, errors.Wrap(err, "error publishing "+g.config.Display())
+	}
+
+	for _, e := range events {
+		if result.WouldRender {
+			e.WouldRender = true
+			e.LastWouldRender = now
+		}
+		if result.DidRender {
+			e.DidRender = true
+			e.LastDidRender = now
+			e.Contents = g.contentsFor(e)
+		}
+	}
+
+	if result.DidRender {
+		r.logger.Printf("[INFO] published %s", g.config.Display())
+		metrics.MeasureSince([]string{"runner", "template_group", "publish"}, publishStart)
+		metrics.IncrCounter([]string{"runner", "template_group", "published"}, 1)
+		runCtx.rendered = append(runCtx.rendered, g.members...)
+		for _, m := range g.members {
+			if cr, ok := runCtx.renders[m]; ok {
+				r.recordRender(cr, g.destination(m), existing[m], g.contents[m])
+			}
+		}
+
+		if !r.dry {
+			for _, m := range g.members {
+				runCtx.appendCommand(g.logger, m)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// secretsFor returns the values of the given dependencies which come from
+// Vault, to be redacted from diffs.
+func (r *Runner) secretsFor(deps *dep.Set) []string {
+	var result []string
+	for _, d := range deps.List() {
+		if d.Type() != dep.TypeVault {
+			continue
+		}
+		if data, ok := r.brain.Recall(d); ok {
+			result = append(result, secretValues(data)...)
+		}
+	}
+	return result
+}
+
+// groupFor returns the template group the given template belongs to, or nil if
+// it does not belong to a group.
+func (r *Runner) groupFor(templateConfig *config.TemplateConfig) *templateGroup {
+	for _, g := range r.groups {
+		if g.has(templateConfig) {
+			return g
+		}
+	}
+	return nil
+}
+
+// checkFunc returns the function which runs the given check against rendered
+// contents before they replace the file on disk, or nil if the check is not
+// enabled. The check command runs in the given environment.
+func (r *Runner) checkFunc(c *config.CheckConfig, env *config.EnvConfig) func(string) error {
+	if c == nil || !config.BoolVal(c.Enabled) {
+		return nil
+	}
+
+	return func(path string) error {
+		e := env.Copy()
+		e.Custom = append(r.childEnv(), e.Custom...)
+		return Check(&CheckInput{
+			Command: config.StringVal(c.Command),
+			Format:  config.StringVal(c.Format),
+			Timeout: config.TimeDurationVal(c.Timeout),
+			Env:     e.Env(),
+			Stdout:  r.outStream,
+			Stderr:  r.errStream,
+		}, path)
+	}
+}
+
 // init() creates the Runner's underlying data structures and returns an error
 // if any problems occur.
 func (r *Runner) init() error {
@@ -817,7 +1099,7 @@ func (r *Runner) init() error {
 	if err != nil {
 		return err
 	}
-	log.Printf("[DEBUG] (runner) final config: %s", result)
+	r.logger.Printf("[DEBUG] final config: %s", result)
 
 	// Create the clientset
 	clients, err := newClientSet(r.config)
@@ -832,47 +1114,39 @@ func (r *Runner) init() error {
 	}
 	r.watcher = watcher
 
-	numTemplates := len(*r.config.Templates)
-	templates := make([]*template.Template, 0, numTemplates)
-	ctemplatesMap := make(map[string]config.TemplateConfigs)
-
-	// Iterate over each TemplateConfig, creating a new Template resource for each
-	// entry. Templates are parsed and saved, and a map of templates to their
-	// config templates is kept so templates can lookup their commands and output
-	// destinations.
-	for _, ctmpl := range *r.config.Templates {
-		tmpl, err := template.NewTemplate(&template.NewTemplateInput{
-			Source:        config.StringVal(ctmpl.Source),
-			Contents:      config.StringVal(ctmpl.Contents),
-			ErrMissingKey: config.BoolVal(ctmpl.ErrMissingKey),
-			LeftDelim:     config.StringVal(ctmpl.LeftDelim),
-			RightDelim:    config.StringVal(ctmpl.RightDelim),
-		})
-		if err != nil {
-			return err
-		}
-
-		if _, ok := ctemplatesMap[tmpl.ID()]; !ok {
-			templates = append(templates, tmpl)
-		}
+	r.clients = clients
 
-		if _, ok := ctemplatesMap[tmpl.ID()]; !ok {
-			ctemplatesMap[tmpl.ID()] = make([]*config.TemplateConfig, 0, 1)
-		}
-		ctemplatesMap[tmpl.ID()] = append(ctemplatesMap[tmpl.ID()], ctmpl)
+	templates, ctemplatesMap, err := newTemplates(r.config.Templates)
+	if err != nil {
+		return err
 	}
-
-	// Convert the map of templates (which was only used to ensure uniqueness)
-	// back into an array of templates.
+	numTemplates := len(templates)
 	r.templates = templates
 
 	r.renderEvents = make(map[string]*RenderEvent, numTemplates)
+	r.renderedData = make(map[string]map[string]interface{}, numTemplates)
+	r.renderedVault = make(map[string]bool, numTemplates)
 	r.dependencies = make(map[string]dep.Dependency)
 
 	r.renderedCh = make(chan struct{}, 1)
 	r.renderEventCh = make(chan struct{}, 1)
 
 	r.ctemplatesMap = ctemplatesMap
+
+	r.groups, err = newTemplateGroups(r.config.TemplateGroups, r.config.Templates)
+	if err != nil {
+		return err
+	}
+
+	if err := validateCommands(r.config.Templates); err != nil {
+		return err
+	}
+
+	r.children, err = newExecChildren(r.config)
+	if err != nil {
+		return err
+	}
+	r.childEventCh = make(chan *childEvent)
 	r.inStream = os.Stdin
 	r.outStream = os.Stdout
 	r.errStream = os.Stderr
@@ -884,9 +1158,11 @@ func (r *Runner) init() error {
 	r.quiescenceMap = make(map[string]*quiescence)
 	r.quiescenceCh = make(chan *template.Template)
 
+	r.reloadCh = make(chan *reloadRequest)
+
 	if *r.config.Dedup.Enabled {
 		if r.once {
-			log.Printf("[INFO] (runner) disabling de-duplication in once mode")
+			r.logger.Printf("[INFO] disabling de-duplication in once mode")
 		} else {
 			r.dedup, err = NewDedupManager(r.config.Dedup, clients, r.brain, r.templates)
 			if err != nil {
@@ -895,9 +1171,53 @@ func (r *Runner) init() error {
 		}
 	}
 
+	for _, t := range *r.config.Templates {
+		if config.BoolVal(t.Exec.Concurrency.Enabled) {
+			r.semaphores = newSemaphoreManager(clients)
+			break
+		}
+	}
+
+	if *r.config.Status.Enabled {
+		r.status, err = newStatusServer(r, *r.config.Status.Address)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// newTemplates creates a Template for each TemplateConfig, in order. Template
+// configurations with the same template share a single Template, and the
+// returned map has the configurations of each template ID so templates can
+// lookup their commands and output destinations.
+func newTemplates(ctemplates *config.TemplateConfigs) ([]*template.Template, map[string]config.TemplateConfigs, error) {
+	templates := make([]*template.Template, 0, len(*ctemplates))
+	ctemplatesMap := make(map[string]config.TemplateConfigs)
+
+	for _, ctmpl := range *ctemplates {
+		tmpl, err := template.NewTemplate(&template.NewTemplateInput{
+			Source:        config.StringVal(ctmpl.Source),
+			Contents:      config.StringVal(ctmpl.Contents),
+			ErrMissingKey: config.BoolVal(ctmpl.ErrMissingKey),
+			LeftDelim:     config.StringVal(ctmpl.LeftDelim),
+			RightDelim:    config.StringVal(ctmpl.RightDelim),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, ok := ctemplatesMap[tmpl.ID()]; !ok {
+			templates = append(templates, tmpl)
+			ctemplatesMap[tmpl.ID()] = make([]*config.TemplateConfig, 0, 1)
+		}
+		ctemplatesMap[tmpl.ID()] = append(ctemplatesMap[tmpl.ID()], ctmpl)
+	}
+
+	return templates, ctemplatesMap, nil
+}
+
 // diffAndUpdateDeps iterates through the current map of dependencies on this
 // runner and stops the watcher for any deps that are no longer required.
 //
@@ -908,15 +1228,15 @@ func (r *Runner) diffAndUpdateDeps(depsMap map[string]dep.Dependency) {
 	defer r.dependenciesLock.Unlock()
 
 	// Diff and up the list of dependencies, stopping any unneeded watchers.
-	log.Printf("[DEBUG] (runner) diffing and updating dependencies")
+	r.logger.Printf("[DEBUG] diffing and updating dependencies")
 
 	for key, d := range r.dependencies {
 		if _, ok := depsMap[key]; !ok {
-			log.Printf("[DEBUG] (runner) %s is no longer needed", d)
+			r.logger.Printf("[DEBUG] %s is no longer needed", d)
 			r.watcher.Remove(d)
 			r.brain.Forget(d)
 		} else {
-			log.Printf("[DEBUG] (runner) %s is still needed", d)
+			r.logger.Printf("[DEBUG] %s is still needed", d)
 		}
 	}
 
@@ -931,9 +1251,10 @@ func (r *Runner) templateConfigsFor(tmpl *template.Template) []*config.TemplateC
 // TemplateConfigMapping returns a mapping between the template ID and the set
 // of TemplateConfig represented by the template ID
 func (r *Runner) TemplateConfigMapping() map[string][]config.TemplateConfig {
-	m := make(map[string][]config.TemplateConfig, len(r.ctemplatesMap))
+	_, ctemplatesMap := r.templatesSnapshot()
+	m := make(map[string][]config.TemplateConfig, len(ctemplatesMap))
 
-	for id, set := range r.ctemplatesMap {
+	for id, set := range ctemplatesMap {
 		ctmpls := make([]config.TemplateConfig, len(set))
 		m[id] = ctmpls
 		for i, ctmpl := range set {
@@ -944,6 +1265,14 @@ func (r *Runner) TemplateConfigMapping() map[string][]config.TemplateConfig {
 	return m
 }
 
+// templatesSnapshot returns the templates and the mapping of template IDs to
+// their configurations, for use outside of the runner's goroutine.
+func (r *Runner) templatesSnapshot() ([]*template.Template, map[string]config.TemplateConfigs) {
+	r.renderEventsLock.RLock()
+	defer r.renderEventsLock.RUnlock()
+	return r.templates, r.ctemplatesMap
+}
+
 // allTemplatesRendered returns true if all the templates in this Runner have
 // been rendered at least one time.
 func (r *Runner) allTemplatesRendered() bool {
@@ -1029,7 +1358,7 @@ func (r *Runner) storePid() error {
 		return nil
 	}
 
-	log.Printf("[INFO] creating pid file at %q", path)
+	r.logger.Printf("[INFO] creating pid file at %q", path)
 
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
 	if err != nil {
@@ -1052,7 +1381,7 @@ func (r *Runner) deletePid() error {
 		return nil
 	}
 
-	log.Printf("[DEBUG] removing pid file at %q", path)
+	r.logger.Printf("[DEBUG] removing pid file at %q", path)
 
 	stat, err := os.Stat(path)
 	if err != nil {
@@ -1079,6 +1408,11 @@ func (r *Runner) SetErrStream(err io.Writer) {
 	r.errStream = err
 }
 
+// exitCodeLabels returns the labels for a metric about a process exit.
+func exitCodeLabels(code int) []metrics.Label {
+	return []metrics.Label{{Name: "exit_code", Value: strconv.Itoa(code)}}
+}
+
 // spawnChildInput is used as input to spawn a child process.
 type spawnChildInput struct {
 	Stdin        io.Reader
@@ -1121,7 +1455,25 @@ func spawnChild(i *spawnChildInput) (*child.Child, error) {
 		return nil, errors.Wrap(err, "error creating child")
 	}
 
-	if err := child.Start(); err != nil {
+	start := time.Now()
+	err = child.Start()
+
+	// With a timeout, Start waits for the command to exit, so the duration and
+	// exit code are known here. Without one, the child is supervised in exec
+	// mode and its exit is recorded by the runner. A command that failed to
+	// start or did not exit within the timeout has no exit code.
+	if i.Timeout != 0 {
+		metrics.MeasureSince([]string{"runner", "command", "duration"}, start)
+		if code, exited := child.LastExitCode(); exited {
+			metrics.IncrCounterWithLabels([]string{"runner", "command", "exit"}, 1, exitCodeLabels(code))
+		} else {
+			metrics.IncrCounter([]string{"runner", "command", "error"}, 1)
+		}
+	} else if err == nil {
+		metrics.IncrCounter([]string{"runner", "exec", "spawn"}, 1)
+	}
+
+	if err != nil {
 		return nil, errors.Wrap(err, "child")
 	}
 	return child, nil
@@ -1223,6 +1575,11 @@ func newClientSet(c *config.Config) (*dep.ClientSet, error) {
 		Address:                      config.StringVal(c.Vault.Address),
 		Token:                        config.StringVal(c.Vault.Token),
 		UnwrapToken:                  config.BoolVal(c.Vault.UnwrapToken),
+		AuthEnabled:                  config.BoolVal(c.Vault.Auth.Enabled),
+		AuthMethod:                   config.StringVal(c.Vault.Auth.Method),
+		AuthMountPath:                config.StringVal(c.Vault.Auth.MountPath),
+		AuthRoleIDFile:               config.StringVal(c.Vault.Auth.RoleIDFile),
+		AuthSecretIDFile:             config.StringVal(c.Vault.Auth.SecretIDFile),
 		SSLEnabled:                   config.BoolVal(c.Vault.SSL.Enabled),
 		SSLVerify:                    config.BoolVal(c.Vault.SSL.Verify),
 		SSLCert:                      config.StringVal(c.Vault.SSL.Cert),
@@ -1246,20 +1603,28 @@ func newClientSet(c *config.Config) (*dep.ClientSet, error) {
 
 // newWatcher creates a new watcher.
 func newWatcher(c *config.Config, clients *dep.ClientSet, once bool) (*watch.Watcher, error) {
-	log.Printf("[INFO] (runner) creating watcher")
+	logging.New("runner").Printf("[INFO] creating watcher")
+
+	// When logging in with an auth method, the token to renew is the one the
+	// client set obtained, not the one from the configuration.
+	vaultToken := config.StringVal(c.Vault.Token)
+	if config.BoolVal(c.Vault.Auth.Enabled) {
+		vaultToken = clients.Vault().Token()
+	}
 
 	w, err := watch.NewWatcher(&watch.NewWatcherInput{
 		Clients:         clients,
 		MaxStale:        config.TimeDurationVal(c.MaxStale),
 		Once:            once,
-		RenewVault:      config.StringPresent(c.Vault.Token) && config.BoolVal(c.Vault.RenewToken),
+		RenewVault:      vaultToken != "" && config.BoolVal(c.Vault.RenewToken),
+		VaultAuth:       config.BoolVal(c.Vault.Auth.Enabled),
 		RetryFuncConsul: watch.RetryFunc(c.Consul.Retry.RetryFunc()),
 		// TODO: Add a sane default retry - right now this only affects "local"
 		// dependencies like reading a file from disk.
 		RetryFuncDefault: nil,
 		RetryFuncVault:   watch.RetryFunc(c.Vault.Retry.RetryFunc()),
 		VaultGrace:       config.TimeDurationVal(c.Vault.Grace),
-		VaultToken:       config.StringVal(c.Vault.Token),
+		VaultToken:       vaultToken,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "runner")
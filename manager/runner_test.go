@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/consul-template/config"
 	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/telemetry"
 	"github.com/hashicorp/consul-template/template"
 )
 
@@ -437,6 +439,433 @@ func TestRunner_Run(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"env_render",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				os.Remove("/tmp/ct-env_render")
+
+				// The command is parsed with the environment of this process, so
+				// the script expands the variable instead.
+				script := []byte(`env; cat "$CT_CHANGED_DEPS_FILE"`)
+				if err := ioutil.WriteFile("/tmp/ct-env_render.sh", script, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Contents:    config.String("hello"),
+						Command:     config.String("sh /tmp/ct-env_render.sh"),
+						Destination: config.String("/tmp/ct-env_render"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				for _, exp := range []string{
+					"CT_TEMPLATE_ID=",
+					"CT_DESTINATION=/tmp/ct-env_render\n",
+					"CT_PREVIOUS_CHECKSUM=\n",
+					"CT_CHECKSUM=" + checksum([]byte("hello")) + "\n",
+					`{"dependencies":[]}`,
+				} {
+					if !strings.Contains(out, exp) {
+						t.Errorf("\nexp: %#v\nact: %#v", exp, out)
+					}
+				}
+				os.Remove("/tmp/ct-env_render")
+				os.Remove("/tmp/ct-env_render.sh")
+			},
+			false,
+		},
+		{
+			"env_render_no_timeout",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				os.Remove("/tmp/ct-env_render_no_timeout.path")
+
+				script := []byte(`echo "$CT_CHANGED_DEPS_FILE" > /tmp/ct-env_render_no_timeout.path`)
+				if err := ioutil.WriteFile("/tmp/ct-env_render_no_timeout.sh", script, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Contents:       config.String("hello"),
+						Command:        config.String("sh /tmp/ct-env_render_no_timeout.sh"),
+						CommandTimeout: config.TimeDuration(0),
+						Destination:    config.String("/tmp/ct-env_render_no_timeout"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				defer os.Remove("/tmp/ct-env_render_no_timeout")
+				defer os.Remove("/tmp/ct-env_render_no_timeout.path")
+				defer os.Remove("/tmp/ct-env_render_no_timeout.sh")
+
+				// The command is not waited for, so the file describing the changed
+				// dependencies is removed once it exits.
+				var path string
+				for start := time.Now(); time.Since(start) < 2*time.Second; {
+					b, _ := ioutil.ReadFile("/tmp/ct-env_render_no_timeout.path")
+					if path = strings.TrimSpace(string(b)); path != "" {
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				if path == "" {
+					t.Fatal("command did not run")
+				}
+				for start := time.Now(); time.Since(start) < 2*time.Second; {
+					if _, err := os.Stat(path); os.IsNotExist(err) {
+						return
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				t.Errorf("expected %q to be removed", path)
+			},
+			false,
+		},
+		{
+			"command_retry",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				os.Remove("/tmp/ct-command_retry")
+
+				// The command fails the first time it runs.
+				script := []byte("echo attempt\n" +
+					"test -e /tmp/ct-command_retry && exit 0\n" +
+					"touch /tmp/ct-command_retry\n" +
+					"exit 1\n")
+				if err := ioutil.WriteFile("/tmp/ct-command_retry.sh", script, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Contents:    config.String("hello"),
+						Command:     config.String("sh /tmp/ct-command_retry.sh"),
+						Destination: config.String("/tmp/ct-command_retry.out"),
+						Retry: &config.RetryConfig{
+							Attempts: config.Int(2),
+							Backoff:  config.TimeDuration(time.Millisecond),
+						},
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				exp := "attempt\nattempt\n"
+				if out != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, out)
+				}
+				os.Remove("/tmp/ct-command_retry")
+				os.Remove("/tmp/ct-command_retry.out")
+				os.Remove("/tmp/ct-command_retry.sh")
+			},
+			false,
+		},
+		{
+			"on_failure_stop",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Contents:    config.String("a"),
+						Command:     config.String("false"),
+						Destination: config.String("/tmp/ct-on_failure_stop_a"),
+						OnFailure:   config.String(config.OnFailureStop),
+					},
+					&config.TemplateConfig{
+						Contents:    config.String("b"),
+						Command:     config.String("echo 123"),
+						Destination: config.String("/tmp/ct-on_failure_stop_b"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				os.Remove("/tmp/ct-on_failure_stop_a")
+				os.Remove("/tmp/ct-on_failure_stop_b")
+				if out != "" {
+					t.Errorf("expected the second command to be skipped, got %q", out)
+				}
+			},
+			true,
+		},
+		{
+			"on_failure_restore_backup",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				os.Remove("/tmp/ct-on_failure_restore_backup.bak")
+				if err := ioutil.WriteFile("/tmp/ct-on_failure_restore_backup", []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Backup:      config.Bool(true),
+						Contents:    config.String("new"),
+						Command:     config.String("false"),
+						Destination: config.String("/tmp/ct-on_failure_restore_backup"),
+						OnFailure:   config.String(config.OnFailureRestoreBackup),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				defer os.Remove("/tmp/ct-on_failure_restore_backup")
+				defer os.Remove("/tmp/ct-on_failure_restore_backup.bak")
+
+				contents, err := ioutil.ReadFile("/tmp/ct-on_failure_restore_backup")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exp := "old"; string(contents) != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, string(contents))
+				}
+			},
+			true,
+		},
+		{
+			"parallel_group",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				os.Remove("/tmp/ct-parallel_group")
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					// The first command only finishes once the last runs, which
+					// is in the same parallel group.
+					&config.TemplateConfig{
+						Contents:    config.String("a"),
+						Destination: config.String("/tmp/ct-parallel_group_a"),
+						Exec: &config.ExecConfig{
+							Command: config.String("sh -c 'while [ ! -e /tmp/ct-parallel_group ]; do sleep 0.01; done'"),
+							Timeout: config.TimeDuration(5 * time.Second),
+						},
+						ParallelGroup: config.String("group"),
+					},
+					&config.TemplateConfig{
+						Contents:    config.String("b"),
+						Command:     config.String("sh -c 'test -e /tmp/ct-parallel_group && echo 123'"),
+						Destination: config.String("/tmp/ct-parallel_group_b"),
+					},
+					&config.TemplateConfig{
+						Contents:      config.String("c"),
+						Command:       config.String("touch /tmp/ct-parallel_group"),
+						Destination:   config.String("/tmp/ct-parallel_group_c"),
+						ParallelGroup: config.String("group"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				// The command between them runs after the group.
+				exp := "123\n"
+				if out != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, out)
+				}
+				for _, path := range []string{"", "_a", "_b", "_c"} {
+					os.Remove("/tmp/ct-parallel_group" + path)
+				}
+			},
+			false,
+		},
+		{
+			"template_group",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Contents:      config.String("a"),
+						Command:       config.String("echo 123"),
+						Destination:   config.String("a.conf"),
+						TemplateGroup: config.String("group"),
+					},
+					&config.TemplateConfig{
+						Contents:      config.String("b"),
+						Command:       config.String("echo 123"),
+						Destination:   config.String("b.conf"),
+						TemplateGroup: config.String("group"),
+					},
+				},
+				TemplateGroups: &config.TemplateGroupConfigs{
+					&config.TemplateGroupConfig{
+						Name: config.String("group"),
+						Path: config.String("/tmp/ct-template_group/conf"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				defer os.RemoveAll("/tmp/ct-template_group")
+
+				// The command runs once for the group.
+				exp := "123\n"
+				if out != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, out)
+				}
+
+				for name, exp := range map[string]string{"a.conf": "a", "b.conf": "b"} {
+					contents, err := ioutil.ReadFile("/tmp/ct-template_group/conf/" + name)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(contents) != exp {
+						t.Errorf("\nexp: %#v\nact: %#v", exp, string(contents))
+					}
+				}
+
+				for _, e := range r.RenderEvents() {
+					if !e.DidRender {
+						t.Errorf("expected to render")
+					}
+				}
+			},
+			false,
+		},
+		{
+			"check_passes",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Check: &config.CheckConfig{
+							Command: config.String("grep -q hello {{.TempPath}}"),
+						},
+						Contents:    config.String("hello"),
+						Command:     config.String("echo 123"),
+						Destination: config.String("/tmp/ct-check_passes"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				defer os.Remove("/tmp/ct-check_passes")
+
+				exp := "123\n"
+				if out != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, out)
+				}
+
+				contents, err := ioutil.ReadFile("/tmp/ct-check_passes")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(contents) != "hello" {
+					t.Errorf("\nexp: %#v\nact: %#v", "hello", string(contents))
+				}
+			},
+			false,
+		},
+		{
+			"check_fails",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				if err := ioutil.WriteFile("/tmp/ct-check_fails", []byte(`{"a":1}`), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Check: &config.CheckConfig{
+							Format: config.String("json"),
+						},
+						Contents:    config.String(`{"a":`),
+						Command:     config.String("echo 123"),
+						Destination: config.String("/tmp/ct-check_fails"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				defer os.Remove("/tmp/ct-check_fails")
+
+				// The command must not run, and the file must be left untouched.
+				if out != "" {
+					t.Errorf("\nexp: %#v\nact: %#v", "", out)
+				}
+
+				contents, err := ioutil.ReadFile("/tmp/ct-check_fails")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exp := `{"a":1}`; string(contents) != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, string(contents))
+				}
+
+				for _, e := range r.RenderEvents() {
+					if e.CheckErr == nil {
+						t.Errorf("expected check error")
+					}
+					if e.LastCheckFailed.IsZero() {
+						t.Errorf("expected last check failed to be set")
+					}
+					if e.DidRender {
+						t.Errorf("expected not to render")
+					}
+				}
+			},
+			true,
+		},
+		{
+			"check_fails_other_templates_run",
+			func(t *testing.T, r *Runner) {
+				r.dry = false
+				r.once = false
+			},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Check: &config.CheckConfig{
+							Format: config.String("json"),
+						},
+						Contents:    config.String(`{"a":`),
+						Command:     config.String("echo 123"),
+						Destination: config.String("/tmp/ct-check_fails_other_a"),
+					},
+					&config.TemplateConfig{
+						Contents:    config.String("b"),
+						Command:     config.String("echo 456"),
+						Destination: config.String("/tmp/ct-check_fails_other_b"),
+					},
+				},
+			},
+			func(t *testing.T, r *Runner, out string) {
+				defer os.Remove("/tmp/ct-check_fails_other_a")
+				defer os.Remove("/tmp/ct-check_fails_other_b")
+
+				// Only the command of the template which passed runs.
+				exp := "456\n"
+				if out != exp {
+					t.Errorf("\nexp: %#v\nact: %#v", exp, out)
+				}
+
+				if _, err := os.Stat("/tmp/ct-check_fails_other_a"); !os.IsNotExist(err) {
+					t.Errorf("expected the file which failed its check not to be written")
+				}
+				contents, err := ioutil.ReadFile("/tmp/ct-check_fails_other_b")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(contents) != "b" {
+					t.Errorf("\nexp: %#v\nact: %#v", "b", string(contents))
+				}
+
+				events := r.RenderEvents()
+				if e := events[r.templates[0].ID()]; e.CheckErr == nil || e.DidRender {
+					t.Errorf("expected the first template to fail its check")
+				}
+				if e := events[r.templates[1].ID()]; e.CheckErr != nil || !e.DidRender {
+					t.Errorf("expected the second template to render")
+				}
+			},
+			false,
+		},
 	}
 
 	for i, tc := range cases {
@@ -617,6 +1046,52 @@ func TestRunner_Start(t *testing.T) {
 		}
 	})
 
+	t.Run("render_timeout", func(t *testing.T) {
+		t.Parallel()
+
+		// Nothing listens on the Consul address, so the key never has data.
+		c := config.DefaultConfig().Merge(&config.Config{
+			Consul: &config.ConsulConfig{
+				Address: config.String("127.0.0.1:1"),
+			},
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{
+					Contents:      config.String(`{{ key "render-timeout-foo" }}`),
+					Destination:   config.String("/tmp/ct-render_timeout"),
+					RenderTimeout: config.TimeDuration(100 * time.Millisecond),
+				},
+			},
+		})
+		c.Finalize()
+
+		r, err := NewRunner(c, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go r.Start()
+		defer r.Stop()
+
+		select {
+		case err := <-r.ErrCh:
+			typed, ok := err.(*ErrRenderTimeout)
+			if !ok {
+				t.Fatal(err)
+			}
+			if len(typed.Templates) != 1 {
+				t.Fatalf("expected 1 template, got %d", len(typed.Templates))
+			}
+			exp := []string{"kv.block(render-timeout-foo)"}
+			if act := typed.Templates[0].MissingDeps; !reflect.DeepEqual(exp, act) {
+				t.Errorf("\nexp: %#v\nact: %#v", exp, act)
+			}
+		case <-r.renderedCh:
+			t.Fatal("expected template not to render")
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+
 	t.Run("multipass", func(t *testing.T) {
 		t.Parallel()
 
@@ -710,7 +1185,7 @@ func TestRunner_Start(t *testing.T) {
 				time.Sleep(100 * time.Millisecond)
 
 				r.childLock.RLock()
-				if r.child != nil {
+				if r.children[0].child != nil {
 					found = true
 				}
 				r.childLock.RUnlock()
@@ -763,6 +1238,255 @@ func TestRunner_Start(t *testing.T) {
 		}
 	})
 
+	t.Run("exec_restart", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(out.Name())
+
+		runs, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(runs.Name())
+
+		c := config.DefaultConfig().Merge(&config.Config{
+			Exec: &config.ExecConfig{
+				Command: config.String(fmt.Sprintf(`sh -c "echo run >> %s; exit 3"`, runs.Name())),
+				Restart: &config.RestartConfig{
+					Backoff:     config.TimeDuration(10 * time.Millisecond),
+					MaxAttempts: config.Int(2),
+					Policy:      config.String(config.RestartPolicyOnFailure),
+				},
+			},
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{
+					Contents:    config.String(`test`),
+					Destination: config.String(out.Name()),
+				},
+			},
+		})
+		c.Finalize()
+
+		r, err := NewRunner(c, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go r.Start()
+		defer r.Stop()
+
+		select {
+		case err := <-r.ErrCh:
+			died, ok := err.(*ErrChildDied)
+			if !ok {
+				t.Fatal(err)
+			}
+			if died.ExitStatus() != 3 {
+				t.Errorf("expected %d to be %d", died.ExitStatus(), 3)
+			}
+
+			b, err := ioutil.ReadFile(runs.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(string(b), "run"); n != 3 {
+				t.Errorf("expected %d runs to be %d", n, 3)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+
+	t.Run("exec_liveness_probe", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(out.Name())
+
+		c := config.DefaultConfig().Merge(&config.Config{
+			Exec: &config.ExecConfig{
+				Command:     config.String(`sleep 30`),
+				KillTimeout: config.TimeDuration(100 * time.Millisecond),
+				LivenessProbe: &config.ProbeConfig{
+					Command:          config.String(`false`),
+					FailureThreshold: config.Int(1),
+					Interval:         config.TimeDuration(50 * time.Millisecond),
+				},
+				Restart: &config.RestartConfig{
+					Backoff: config.TimeDuration(10 * time.Millisecond),
+					Policy:  config.String(config.RestartPolicyAlways),
+				},
+			},
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{
+					Contents:    config.String(`test`),
+					Destination: config.String(out.Name()),
+				},
+			},
+		})
+		c.Finalize()
+
+		r, err := NewRunner(c, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go r.Start()
+		defer r.Stop()
+
+		pid := func() int {
+			r.childLock.RLock()
+			defer r.childLock.RUnlock()
+			if r.children[0].child == nil {
+				return 0
+			}
+			return r.children[0].child.Pid()
+		}
+
+		var first int
+		for i := 0; i < 40; i++ {
+			select {
+			case err := <-r.ErrCh:
+				t.Fatal(err)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			p := pid()
+			if first == 0 {
+				first = p
+			} else if p != 0 && p != first {
+				return
+			}
+		}
+		t.Fatal("expected child process to be restarted")
+	})
+
+	t.Run("exec_liveness_probe_max_attempts", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(out.Name())
+
+		runs, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(runs.Name())
+
+		c := config.DefaultConfig().Merge(&config.Config{
+			Exec: &config.ExecConfig{
+				Command:     config.String(fmt.Sprintf(`sh -c "echo run >> %s; exec sleep 30"`, runs.Name())),
+				KillTimeout: config.TimeDuration(100 * time.Millisecond),
+				LivenessProbe: &config.ProbeConfig{
+					Command:          config.String(`false`),
+					FailureThreshold: config.Int(1),
+					Interval:         config.TimeDuration(100 * time.Millisecond),
+				},
+				Restart: &config.RestartConfig{
+					Backoff:     config.TimeDuration(10 * time.Millisecond),
+					MaxAttempts: config.Int(2),
+					Policy:      config.String(config.RestartPolicyAlways),
+				},
+			},
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{
+					Contents:    config.String(`test`),
+					Destination: config.String(out.Name()),
+				},
+			},
+		})
+		c.Finalize()
+
+		r, err := NewRunner(c, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go r.Start()
+		defer r.Stop()
+
+		select {
+		case err := <-r.ErrCh:
+			if _, ok := err.(*ErrChildDied); !ok {
+				t.Fatal(err)
+			}
+
+			b, err := ioutil.ReadFile(runs.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(string(b), "run"); n != 3 {
+				t.Errorf("expected %d runs to be %d", n, 3)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+
+	t.Run("exec_multiple", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(out.Name())
+
+		c := config.DefaultConfig().Merge(&config.Config{
+			Execs: &config.ExecConfigs{
+				&config.ExecConfig{
+					Command:  config.String(`sh -c "exit 2"`),
+					Critical: config.Bool(false),
+					Name:     config.String("worker"),
+				},
+				&config.ExecConfig{
+					Command: config.String(`sh -c "sleep 0.2; exit 4"`),
+					Name:    config.String("server"),
+				},
+			},
+			Templates: &config.TemplateConfigs{
+				&config.TemplateConfig{
+					Contents:    config.String(`test`),
+					Destination: config.String(out.Name()),
+				},
+			},
+		})
+		c.Finalize()
+
+		r, err := NewRunner(c, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go r.Start()
+		defer r.Stop()
+
+		// The worker is not critical, so only the exit of the server stops the
+		// runner.
+		select {
+		case err := <-r.ErrCh:
+			died, ok := err.(*ErrChildDied)
+			if !ok {
+				t.Fatal(err)
+			}
+			if died.ExitStatus() != 4 {
+				t.Errorf("expected %d to be %d", died.ExitStatus(), 4)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+
 	t.Run("render_in_memory", func(t *testing.T) {
 		t.Parallel()
 
@@ -821,6 +1545,67 @@ func TestRunner_Start(t *testing.T) {
 	})
 }
 
+func TestRunner_RenderEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := config.DefaultConfig().Merge(&config.Config{
+		Templates: &config.TemplateConfigs{
+			&config.TemplateConfig{
+				Contents:      config.String("a"),
+				Destination:   config.String("a.conf"),
+				TemplateGroup: config.String("group"),
+			},
+		},
+		TemplateGroups: &config.TemplateGroupConfigs{
+			&config.TemplateGroupConfig{
+				Name: config.String("group"),
+				Path: config.String(dir + "/conf"),
+			},
+		},
+	})
+	c.Finalize()
+
+	r, err := NewRunner(c, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The events are read from other goroutines while the group is published,
+	// which updates them, and the race detector checks this.
+	stopCh, doneCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+			for _, e := range r.RenderEvents() {
+				_ = e.DidRender || e.WouldRender
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stopCh)
+	<-doneCh
+
+	for _, e := range r.RenderEvents() {
+		if !e.WouldRender {
+			t.Errorf("expected to render")
+		}
+	}
+}
+
 func TestRunner_quiescence(t *testing.T) {
 	t.Parallel()
 
@@ -911,3 +1696,29 @@ func TestRunner_quiescence(t *testing.T) {
 		}
 	})
 }
+
+func TestSpawnChild_metrics(t *testing.T) {
+	sink := telemetry.NewTestSink()
+
+	_, err := spawnChild(&spawnChildInput{
+		Stdout:  ioutil.Discard,
+		Stderr:  ioutil.Discard,
+		Command: "sh -c 'exit 3'",
+		Timeout: 5 * time.Second,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if l := len(sink.Metrics("runner.command.duration")); l != 1 {
+		t.Errorf("expected %d to be %d", l, 1)
+	}
+
+	exits := sink.Metrics("runner.command.exit")
+	if len(exits) != 1 {
+		t.Fatalf("expected %d to be %d", len(exits), 1)
+	}
+	if exits[0].Labels["exit_code"] != "3" {
+		t.Errorf("expected %q to be %q", exits[0].Labels["exit_code"], "3")
+	}
+}
@@ -0,0 +1,146 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/consul-template/config"
+	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/logging"
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	// semaphoreSessionTTL is the TTL of the session which holds the slots in
+	// command semaphores.
+	semaphoreSessionTTL = "15s"
+)
+
+// semaphoreManager takes slots in the Consul semaphores which limit how many
+// instances of Consul Template across the cluster run a template command at
+// the same time. Every slot is held by a single session, which is created when
+// the first slot is needed.
+type semaphoreManager struct {
+	clients *dep.ClientSet
+
+	// session is the ID of the current session, or empty if there is none.
+	// readyCh is closed once there is a session.
+	lock    sync.Mutex
+	started bool
+	session string
+	readyCh chan struct{}
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	logger *logging.Logger
+}
+
+// newSemaphoreManager creates a new semaphore manager which uses the Consul
+// client in the given set.
+func newSemaphoreManager(clients *dep.ClientSet) *semaphoreManager {
+	return &semaphoreManager{
+		clients: clients,
+		readyCh: make(chan struct{}),
+		stopCh:  make(chan struct{}),
+		logger:  logging.New("semaphore"),
+	}
+}
+
+// acquire waits for a slot in the semaphore in the given configuration, and
+// returns a function which releases it. It returns an error if stopCh is
+// closed before a slot is free.
+func (s *semaphoreManager) acquire(c *config.ConcurrencyConfig, stopCh <-chan struct{}) (func(), error) {
+	prefix := config.StringVal(c.Prefix)
+
+	id, err := s.sessionID(stopCh)
+	if err != nil {
+		return nil, err
+	}
+
+	sem, err := s.clients.Consul().SemaphoreOpts(&consulapi.SemaphoreOptions{
+		Prefix:           prefix,
+		Limit:            config.IntVal(c.Limit),
+		Session:          id,
+		MonitorRetries:   3,
+		MonitorRetryTime: 3 * time.Second,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create semaphore %q", prefix)
+	}
+
+	logger := s.logger.With("prefix", prefix)
+	logger.Printf("[INFO] waiting for a slot in %q", prefix)
+	start := time.Now()
+	lockCh, err := sem.Acquire(stopCh)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to acquire semaphore %q", prefix)
+	}
+	if lockCh == nil {
+		return nil, fmt.Errorf("stopped waiting for semaphore %q", prefix)
+	}
+	logger.Printf("[INFO] acquired a slot in %q", prefix)
+	metrics.MeasureSince([]string{"runner", "command", "semaphore_wait"}, start)
+
+	return func() {
+		if err := sem.Release(); err != nil {
+			logger.Printf("[WARN] failed to release slot in %q: %s", prefix, err)
+			return
+		}
+		logger.Printf("[DEBUG] released slot in %q", prefix)
+	}, nil
+}
+
+// sessionID returns the ID of the current session, creating one if this is the
+// first time it is needed, and waiting for it to be created.
+func (s *semaphoreManager) sessionID(stopCh <-chan struct{}) (string, error) {
+	for {
+		s.lock.Lock()
+		if !s.started {
+			s.started = true
+			se := &consulapi.SessionEntry{
+				Name:     "Consul-Template command concurrency",
+				Behavior: "delete",
+				TTL:      semaphoreSessionTTL,
+			}
+			go maintainSession(s.clients.Consul(), s.logger, se, s.stopCh, s.held)
+		}
+		id, readyCh := s.session, s.readyCh
+		s.lock.Unlock()
+
+		if id != "" {
+			return id, nil
+		}
+
+		select {
+		case <-readyCh:
+		case <-stopCh:
+			return "", errors.New("stopped waiting for semaphore session")
+		}
+	}
+}
+
+// held records the session while it is held.
+func (s *semaphoreManager) held(id string, sessionCh <-chan struct{}) {
+	s.lock.Lock()
+	s.session = id
+	close(s.readyCh)
+	s.lock.Unlock()
+
+	<-sessionCh
+
+	s.lock.Lock()
+	s.session = ""
+	s.readyCh = make(chan struct{})
+	s.lock.Unlock()
+}
+
+// stop destroys the session, which releases any slots it holds.
+func (s *semaphoreManager) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
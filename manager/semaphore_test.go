@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestSemaphoreManager_acquire(t *testing.T) {
+	t.Parallel()
+
+	c := &config.ConcurrencyConfig{
+		Limit:  config.Int(1),
+		Prefix: config.String("consul-template/test/semaphore"),
+	}
+	c.Finalize()
+
+	// Each manager has its own session, like separate instances of Consul
+	// Template.
+	a := newSemaphoreManager(testClients)
+	defer a.stop()
+	b := newSemaphoreManager(testClients)
+	defer b.stop()
+
+	release, err := a.acquire(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The only slot is taken, so b gives up waiting.
+	stopCh := make(chan struct{})
+	time.AfterFunc(500*time.Millisecond, func() { close(stopCh) })
+	if _, err := b.acquire(c, stopCh); err == nil {
+		t.Fatal("expected the semaphore to be full")
+	}
+
+	release()
+
+	doneCh := make(chan error, 1)
+	go func() {
+		release, err := b.acquire(c, nil)
+		if err == nil {
+			release()
+		}
+		doneCh <- err
+	}()
+
+	select {
+	case err := <-doneCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
@@ -0,0 +1,47 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/hashicorp/consul-template/logging"
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// maintainSession creates a session in Consul from the given entry, and renews
+// it until stopCh is closed. Each time a session is created, held is called
+// with its ID and a channel which is closed once the session is lost, and held
+// must return soon after. A lost session is created again after
+// sessionCreateRetry. Logs are written to the given logger.
+func maintainSession(client *consulapi.Client, logger *logging.Logger, se *consulapi.SessionEntry,
+	stopCh <-chan struct{}, held func(id string, sessionCh <-chan struct{})) {
+	for {
+		logger.Printf("[INFO] attempting to create session")
+		session := client.Session()
+		id, _, err := session.Create(se, nil)
+		if err != nil {
+			logger.Printf("[ERR] failed to create session: %v", err)
+		} else {
+			logger.Printf("[INFO] created session %s", id)
+
+			sessionCh := make(chan struct{})
+			doneCh := make(chan struct{})
+			go func() {
+				defer close(doneCh)
+				held(id, sessionCh)
+			}()
+
+			// Renew our session periodically
+			if err := session.RenewPeriodic(se.TTL, id, nil, stopCh); err != nil {
+				logger.Printf("[ERR] failed to renew session: %v", err)
+			}
+			close(sessionCh)
+			<-doneCh
+		}
+
+		select {
+		case <-time.After(sessionCreateRetry):
+		case <-stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package manager
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/logging"
+	"github.com/hashicorp/consul-template/watch"
+	"github.com/pkg/errors"
+)
+
+// Status is a point-in-time snapshot of the internal state of a Runner.
+type Status struct {
+	// Templates is the render state of each template.
+	Templates []*TemplateStatus `json:"templates"`
+
+	// Views is the polling state of each view in the watcher.
+	Views []watch.ViewStatus `json:"views"`
+
+	// Child is the state of the first child process. This is nil if not
+	// running in exec mode.
+	Child *ChildStatus `json:"child"`
+
+	// Children is the state of each child process in exec mode, in the order
+	// they are started.
+	Children []*ChildStatus `json:"children"`
+
+	// Dedup is the de-duplication leadership state.
+	Dedup *DedupStatus `json:"dedup"`
+}
+
+// TemplateStatus is the render state of a single template.
+type TemplateStatus struct {
+	// ID is the unique ID of the template.
+	ID string `json:"id"`
+
+	// Source is the path to the template on disk. This is empty for templates
+	// given as inline contents.
+	Source string `json:"source"`
+
+	// Destinations is the list of paths this template is rendered to.
+	Destinations []string `json:"destinations"`
+
+	// WouldRender, DidRender, LastWouldRender and LastDidRender mirror the
+	// fields of the most recent RenderEvent.
+	WouldRender     bool      `json:"would_render"`
+	DidRender       bool      `json:"did_render"`
+	LastWouldRender time.Time `json:"last_would_render"`
+	LastDidRender   time.Time `json:"last_did_render"`
+
+	// UpdatedAt is the last time the template was evaluated.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// MissingDeps is the list of dependencies the template is blocked on
+	// because no data has been received for them yet.
+	MissingDeps []string `json:"missing_deps"`
+
+	// UnwatchedDeps is the list of dependencies the template is blocked on
+	// because the watcher has not started querying for them yet.
+	UnwatchedDeps []string `json:"unwatched_deps"`
+}
+
+// ChildStatus is the state of the child process in exec mode.
+type ChildStatus struct {
+	// Name is the name of the exec block, which may be empty.
+	Name string `json:"name"`
+
+	// Command is the command being supervised.
+	Command string `json:"command"`
+
+	// PID is the pid of the running process, or 0 if it is not running.
+	PID int `json:"pid"`
+
+	// Exited indicates a process has exited and ExitCode is the code it exited
+	// with.
+	Exited   bool `json:"exited"`
+	ExitCode int  `json:"exit_code"`
+}
+
+// DedupStatus is the de-duplication leadership state.
+type DedupStatus struct {
+	// Enabled indicates de-duplication mode is active.
+	Enabled bool `json:"enabled"`
+
+	// Leader is a map of template ID to whether this instance currently holds
+	// the lock for that template.
+	Leader map[string]bool `json:"leader"`
+}
+
+// Status returns a snapshot of the internal state of the runner.
+func (r *Runner) Status() *Status {
+	return &Status{
+		Templates: r.templatesStatus(),
+		Views:     r.viewsStatus(),
+		Child:     r.childStatus(),
+		Children:  r.childrenStatus(),
+		Dedup:     r.dedupStatus(),
+	}
+}
+
+func (r *Runner) templatesStatus() []*TemplateStatus {
+	templates, ctemplatesMap := r.templatesSnapshot()
+	events := r.RenderEvents()
+
+	result := make([]*TemplateStatus, 0, len(templates))
+	for _, tmpl := range templates {
+		s := &TemplateStatus{
+			ID:            tmpl.ID(),
+			Source:        tmpl.Source(),
+			Destinations:  make([]string, 0, 1),
+			MissingDeps:   make([]string, 0),
+			UnwatchedDeps: make([]string, 0),
+		}
+
+		for _, c := range ctemplatesMap[tmpl.ID()] {
+			s.Destinations = append(s.Destinations, config.StringVal(c.Destination))
+		}
+
+		if event, ok := events[tmpl.ID()]; ok {
+			s.WouldRender = event.WouldRender
+			s.DidRender = event.DidRender
+			s.LastWouldRender = event.LastWouldRender
+			s.LastDidRender = event.LastDidRender
+			s.UpdatedAt = event.UpdatedAt
+			s.MissingDeps = depStrings(event.MissingDeps)
+			s.UnwatchedDeps = depStrings(event.UnwatchedDeps)
+		}
+
+		result = append(result, s)
+	}
+	return result
+}
+
+func (r *Runner) viewsStatus() []watch.ViewStatus {
+	if r.watcher == nil {
+		return []watch.ViewStatus{}
+	}
+	return r.watcher.Status()
+}
+
+func (r *Runner) childStatus() *ChildStatus {
+	children := r.childrenStatus()
+	if len(children) == 0 {
+		return nil
+	}
+	return children[0]
+}
+
+func (r *Runner) childrenStatus() []*ChildStatus {
+	r.childLock.RLock()
+	defer r.childLock.RUnlock()
+
+	result := make([]*ChildStatus, 0, len(r.children))
+	for _, c := range r.children {
+		if c.child == nil {
+			continue
+		}
+
+		code, exited := c.child.LastExitCode()
+		result = append(result, &ChildStatus{
+			Name:     config.StringVal(c.config.Name),
+			Command:  c.child.Command(),
+			PID:      c.child.Pid(),
+			Exited:   exited,
+			ExitCode: code,
+		})
+	}
+	return result
+}
+
+func (r *Runner) dedupStatus() *DedupStatus {
+	r.renderEventsLock.RLock()
+	dedup, templates := r.dedup, r.templates
+	r.renderEventsLock.RUnlock()
+	if dedup == nil {
+		return &DedupStatus{}
+	}
+
+	leader := make(map[string]bool, len(templates))
+	for _, tmpl := range templates {
+		leader[tmpl.ID()] = dedup.IsLeader(tmpl)
+	}
+	return &DedupStatus{
+		Enabled: true,
+		Leader:  leader,
+	}
+}
+
+// depStrings returns the string representation of each dependency in the set.
+func depStrings(s *dep.Set) []string {
+	if s == nil {
+		return make([]string, 0)
+	}
+
+	list := s.List()
+	result := make([]string, 0, len(list))
+	for _, d := range list {
+		result = append(result, d.String())
+	}
+	return result
+}
+
+// statusServer is an HTTP listener which serves the runner's Status as JSON.
+type statusServer struct {
+	listener net.Listener
+	server   *http.Server
+	logger   *logging.Logger
+}
+
+// newStatusServer creates a new status server bound to the given address. The
+// server does not accept connections until start is called.
+func newStatusServer(r *Runner, addr string) (*statusServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "status")
+	}
+
+	s := &statusServer{
+		listener: ln,
+		logger:   logging.New("status").With("address", ln.Addr().String()),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/status", s.handle(func() interface{} {
+		return r.Status()
+	}))
+	mux.HandleFunc("/v1/status/templates", s.handle(func() interface{} {
+		return r.templatesStatus()
+	}))
+	mux.HandleFunc("/v1/status/views", s.handle(func() interface{} {
+		return r.viewsStatus()
+	}))
+	mux.HandleFunc("/v1/status/child", s.handle(func() interface{} {
+		return r.childStatus()
+	}))
+	mux.HandleFunc("/v1/status/children", s.handle(func() interface{} {
+		return r.childrenStatus()
+	}))
+	mux.HandleFunc("/v1/status/dedup", s.handle(func() interface{} {
+		return r.dedupStatus()
+	}))
+	s.server = &http.Server{Handler: mux}
+
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *statusServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// start begins serving requests in a goroutine.
+func (s *statusServer) start() {
+	s.logger.Printf("[INFO] listening on %s", s.Addr())
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Printf("[ERR] %s", err)
+		}
+	}()
+}
+
+// stop closes the listener and any open connections.
+func (s *statusServer) stop() {
+	s.logger.Printf("[DEBUG] stopping listener on %s", s.Addr())
+	s.server.Close()
+
+	// The server only closes listeners it is serving, so close the listener
+	// directly in case start was never called.
+	s.listener.Close()
+}
+
+// handle returns an http.HandlerFunc which writes the result of f as JSON.
+func (s *statusServer) handle(f func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		b, err := json.MarshalIndent(f(), "", "  ")
+		if err != nil {
+			s.logger.With("path", req.URL.Path).Printf("[ERR] failed to encode response: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+		w.Write([]byte("\n"))
+	}
+}
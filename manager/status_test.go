@@ -0,0 +1,112 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestRunner_Status(t *testing.T) {
+	t.Parallel()
+
+	c := config.DefaultConfig().Merge(&config.Config{
+		Status: &config.StatusConfig{
+			Address: config.String("127.0.0.1:0"),
+		},
+		Templates: &config.TemplateConfigs{
+			&config.TemplateConfig{
+				Contents:    config.String("hello"),
+				Destination: config.String("/tmp/status-test"),
+			},
+		},
+	})
+	c.Finalize()
+
+	r, err := NewRunner(c, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.outStream, r.errStream = ioutil.Discard, ioutil.Discard
+	defer r.Stop()
+
+	if r.status == nil {
+		t.Fatal("expected status listener")
+	}
+	r.status.start()
+
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := "http://" + r.status.Addr()
+
+	t.Run("templates", func(t *testing.T) {
+		resp, err := http.Get(addr + "/v1/status/templates")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected %d to be %d", resp.StatusCode, http.StatusOK)
+		}
+
+		var list []*TemplateStatus
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(list) != 1 {
+			t.Fatalf("expected %d to be %d", len(list), 1)
+		}
+		if !list[0].WouldRender {
+			t.Errorf("expected template to be rendered")
+		}
+		if exp := []string{"/tmp/status-test"}; fmt.Sprint(list[0].Destinations) != fmt.Sprint(exp) {
+			t.Errorf("expected %q to be %q", list[0].Destinations, exp)
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		resp, err := http.Get(addr + "/v1/status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var status Status
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(status.Templates) != 1 {
+			t.Errorf("expected %d to be %d", len(status.Templates), 1)
+		}
+		if status.Child != nil {
+			t.Errorf("expected %#v to be nil", status.Child)
+		}
+		if len(status.Children) != 0 {
+			t.Errorf("expected %#v to be empty", status.Children)
+		}
+		if status.Dedup == nil || status.Dedup.Enabled {
+			t.Errorf("expected dedup to be disabled, got %#v", status.Dedup)
+		}
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		resp, err := http.Post(addr+"/v1/status", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("expected %d to be %d", resp.StatusCode, http.StatusMethodNotAllowed)
+		}
+	})
+}
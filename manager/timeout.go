@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/template"
+)
+
+// renderTimeout returns the render timeout of the given template, which is the
+// shortest timeout of its configurations, or the top-level render timeout if
+// none of them set one. A timeout of 0 means the template waits forever.
+func (r *Runner) renderTimeout(tmpl *template.Template) time.Duration {
+	var timeout time.Duration
+	for _, c := range r.templateConfigsFor(tmpl) {
+		if d := config.TimeDurationVal(c.RenderTimeout); d > 0 && (timeout == 0 || d < timeout) {
+			timeout = d
+		}
+	}
+
+	if timeout == 0 {
+		timeout = config.TimeDurationVal(r.config.RenderTimeout)
+	}
+	return timeout
+}
+
+// everRendered returns true if the given template has rendered, or would have
+// rendered, at least once.
+func (r *Runner) everRendered(tmpl *template.Template) bool {
+	r.renderEventsLock.RLock()
+	defer r.renderEventsLock.RUnlock()
+
+	event, ok := r.renderEvents[tmpl.ID()]
+	if !ok {
+		return false
+	}
+	return !event.LastWouldRender.IsZero() || !event.LastDidRender.IsZero()
+}
+
+// nextRenderTimeout returns a channel which fires at the earliest render
+// timeout, counted from the given start, of the templates which have not yet
+// rendered. It returns nil if there is no such timeout.
+func (r *Runner) nextRenderTimeout(start time.Time) <-chan time.Time {
+	var next time.Time
+	for _, tmpl := range r.templates {
+		timeout := r.renderTimeout(tmpl)
+		if timeout == 0 || r.everRendered(tmpl) {
+			continue
+		}
+
+		if deadline := start.Add(timeout); next.IsZero() || deadline.Before(next) {
+			next = deadline
+		}
+	}
+
+	if next.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(next))
+}
+
+// checkRenderTimeouts returns an error reporting the missing dependencies of
+// each template which has not rendered before its render timeout, counted from
+// the given start.
+func (r *Runner) checkRenderTimeouts(start time.Time) error {
+	events := r.RenderEvents()
+	now := time.Now()
+
+	var reports []*RenderTimeoutReport
+	for _, tmpl := range r.templates {
+		timeout := r.renderTimeout(tmpl)
+		if timeout == 0 || r.everRendered(tmpl) || now.Before(start.Add(timeout)) {
+			continue
+		}
+
+		report := &RenderTimeoutReport{
+			ID:            tmpl.ID(),
+			Source:        tmpl.Source(),
+			Destinations:  make([]string, 0, 1),
+			Timeout:       timeout.String(),
+			MissingDeps:   make([]string, 0),
+			UnwatchedDeps: make([]string, 0),
+		}
+		for _, c := range r.templateConfigsFor(tmpl) {
+			report.Destinations = append(report.Destinations, config.StringVal(c.Destination))
+		}
+		if event, ok := events[tmpl.ID()]; ok {
+			report.MissingDeps = depStrings(event.MissingDeps)
+			report.UnwatchedDeps = depStrings(event.UnwatchedDeps)
+		}
+		reports = append(reports, report)
+	}
+
+	if len(reports) == 0 {
+		return nil
+	}
+
+	err := &ErrRenderTimeout{Templates: reports}
+	if b, jerr := json.Marshal(err); jerr == nil {
+		r.logger.Printf("[ERR] render timeout report: %s", b)
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+// +build linux
+
+package manager
+
+import (
+	"bytes"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/consul-template/logging"
+)
+
+// posixACLPrefix is the prefix of the extended attributes which store POSIX
+// ACLs. An access ACL also sets the group and other permission bits, so these
+// are not copied, otherwise the ACL of the previous file would replace the
+// permissions of the template.
+const posixACLPrefix = "system.posix_acl_"
+
+// copyXattrs copies the extended attributes of the file at src onto the file at
+// dst, including security labels such as security.selinux, but not POSIX ACLs.
+// This is best effort: the filesystem may not support extended attributes, and
+// only root may set some of them, so failures are logged and otherwise ignored.
+func copyXattrs(src, dst string) {
+	logger := logging.New("runner").With("path", src)
+
+	names, err := xattr(func(dest []byte) (int, error) {
+		return syscall.Listxattr(src, dest)
+	})
+	if err != nil {
+		if err != syscall.ENOTSUP {
+			logger.Printf("[DEBUG] failed listing xattrs of %s: %s", src, err)
+		}
+		return
+	}
+
+	for _, name := range bytes.Split(names, []byte{0}) {
+		if len(name) == 0 || strings.HasPrefix(string(name), posixACLPrefix) {
+			continue
+		}
+
+		value, err := xattr(func(dest []byte) (int, error) {
+			return syscall.Getxattr(src, string(name), dest)
+		})
+		if err != nil {
+			logger.Printf("[DEBUG] failed reading xattr %s of %s: %s", name, src, err)
+			continue
+		}
+
+		if err := syscall.Setxattr(dst, string(name), value, 0); err != nil {
+			logger.Printf("[DEBUG] failed setting xattr %s on %s: %s", name, dst, err)
+		}
+	}
+}
+
+// xattr calls f, which is Listxattr or Getxattr, with a buffer large enough for
+// the result. The attribute may change between asking for its size and
+// reading it, in which case it is read again.
+func xattr(f func([]byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := f(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+
+		buf := make([]byte, size)
+		n, err := f(buf)
+		if err == syscall.ERANGE {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+}
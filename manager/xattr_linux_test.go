@@ -0,0 +1,91 @@
+// +build linux
+
+package manager
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestAtomicWrite_xattrs(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outDir)
+	outFile, err := ioutil.TempFile(outDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := syscall.Setxattr(outFile.Name(), "user.consul-template", []byte("label"), 0); err != nil {
+		t.Skipf("xattrs not supported: %s", err)
+	}
+
+	if err := AtomicWrite(outFile.Name(), []byte("after"), 0644, true, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{outFile.Name(), outFile.Name() + ".bak"} {
+		buf := make([]byte, 64)
+		n, err := syscall.Getxattr(path, "user.consul-template", buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if act := string(buf[:n]); act != "label" {
+			t.Errorf("expected %q to be %q", act, "label")
+		}
+	}
+}
+
+func TestAtomicWrite_xattrsACL(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outDir)
+	outFile, err := ioutil.TempFile(outDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An access ACL which gives read access to another user, the group and
+	// others. Unlike an ACL which only has the entries of the permission bits,
+	// it is stored as an extended attribute.
+	acl := make([]byte, 4, 4+5*8)
+	binary.LittleEndian.PutUint32(acl, 2)
+	for _, e := range []struct {
+		tag, perm uint16
+		id        uint32
+	}{
+		{0x01, 6, 0xffffffff}, // user::rw-
+		{0x02, 4, 1000},       // user:1000:r--
+		{0x04, 4, 0xffffffff}, // group::r--
+		{0x10, 4, 0xffffffff}, // mask::r--
+		{0x20, 4, 0xffffffff}, // other::r--
+	} {
+		entry := make([]byte, 8)
+		binary.LittleEndian.PutUint16(entry, e.tag)
+		binary.LittleEndian.PutUint16(entry[2:], e.perm)
+		binary.LittleEndian.PutUint32(entry[4:], e.id)
+		acl = append(acl, entry...)
+	}
+	if err := syscall.Setxattr(outFile.Name(), "system.posix_acl_access", acl, 0); err != nil {
+		t.Skipf("ACLs not supported: %s", err)
+	}
+
+	if err := AtomicWrite(outFile.Name(), []byte("after"), 0600, false, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := os.Stat(outFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := os.FileMode(0600), stat.Mode().Perm(); act != exp {
+		t.Errorf("\nexp: %#v\nact: %#v", exp, act)
+	}
+}
@@ -0,0 +1,6 @@
+// +build !linux
+
+package manager
+
+// copyXattrs does nothing, since extended attributes are only copied on Linux.
+func copyXattrs(src, dst string) {}
@@ -0,0 +1,86 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Ready tells the service manager that startup, or a reload, is finished.
+	Ready = "READY=1"
+
+	// Reloading tells the service manager that the configuration is reloading.
+	// It must be followed by Ready once the reload is finished.
+	Reloading = "RELOADING=1"
+
+	// Watchdog tells the service manager that the service is still healthy.
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notifier sends the state of the service to the service manager with the
+// sd_notify protocol, over the unixgram socket named by NOTIFY_SOCKET. A nil
+// Notifier, when not run by a service manager, sends nothing.
+type Notifier struct {
+	socket string
+}
+
+// NewNotifier returns a notifier for the socket named by NOTIFY_SOCKET, or nil
+// if it is not set.
+func NewNotifier() *Notifier {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	return &Notifier{socket: socket}
+}
+
+// Notify sends the given states, such as Ready, in a single message.
+func (n *Notifier) Notify(states ...string) error {
+	if n == nil {
+		return nil
+	}
+
+	addr := &net.UnixAddr{Name: n.socket, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return fmt.Errorf("systemd: %s", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return fmt.Errorf("systemd: %s", err)
+	}
+	return nil
+}
+
+// Status returns the state which describes the service as the given text, on a
+// single line.
+func Status(text string) string {
+	return "STATUS=" + strings.Replace(text, "\n", " ", -1)
+}
+
+// WatchdogInterval returns how often the service manager expects Watchdog,
+// from WATCHDOG_USEC, or 0 if the watchdog is not enabled for this process.
+// Pings should be sent at half of the interval, so that one late ping is not
+// mistaken for a hang.
+func (n *Notifier) WatchdogInterval() time.Duration {
+	if n == nil {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+			return 0
+		}
+	}
+
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,121 @@
+package systemd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotifier_Notify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.SkipNow()
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	os.Setenv("NOTIFY_SOCKET", socket)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+
+	n := NewNotifier()
+	if err := n.Notify(Status("rendered\n3 templates"), Ready); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1024)
+	l, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "STATUS=rendered 3 templates\nREADY=1"
+	if act := string(buf[:l]); act != exp {
+		t.Errorf("\nexp: %q\nact: %q", exp, act)
+	}
+}
+
+func TestNotifier_nil(t *testing.T) {
+	os.Unsetenv("NOTIFY_SOCKET")
+
+	n := NewNotifier()
+	if n != nil {
+		t.Fatalf("expected %#v to be nil", n)
+	}
+	if err := n.Notify(Ready); err != nil {
+		t.Error(err)
+	}
+	if i := n.WatchdogInterval(); i != 0 {
+		t.Errorf("expected %s to be 0", i)
+	}
+}
+
+func TestNotifier_WatchdogInterval(t *testing.T) {
+	cases := []struct {
+		name string
+		usec string
+		pid  string
+		exp  time.Duration
+	}{
+		{
+			"unset",
+			"",
+			"",
+			0,
+		},
+		{
+			"usec",
+			"30000000",
+			"",
+			30 * time.Second,
+		},
+		{
+			"pid",
+			"30000000",
+			strconv.Itoa(os.Getpid()),
+			30 * time.Second,
+		},
+		{
+			"other_pid",
+			"30000000",
+			"1",
+			0,
+		},
+		{
+			"invalid",
+			"nope",
+			"",
+			0,
+		},
+	}
+
+	n := &Notifier{socket: "notify.sock"}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			os.Setenv("WATCHDOG_USEC", tc.usec)
+			defer os.Unsetenv("WATCHDOG_USEC")
+			os.Setenv("WATCHDOG_PID", tc.pid)
+			defer os.Unsetenv("WATCHDOG_PID")
+
+			if act := n.WatchdogInterval(); act != tc.exp {
+				t.Errorf("\nexp: %s\nact: %s", tc.exp, act)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// DogStatsdSink is a metrics.MetricSink which sends metrics to a DogStatsD
+// agent over UDP. Labels are sent as DogStatsD tags instead of being appended
+// to the metric name.
+type DogStatsdSink struct {
+	conn net.Conn
+	tags []string
+}
+
+// NewDogStatsdSink creates a new sink which sends metrics to the given address,
+// adding the given tags (in the "key:value" format) to every metric.
+func NewDogStatsdSink(addr string, tags []string) (*DogStatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DogStatsdSink{
+		conn: conn,
+		tags: tags,
+	}, nil
+}
+
+// Shutdown closes the connection to the agent.
+func (s *DogStatsdSink) Shutdown() {
+	s.conn.Close()
+}
+
+func (s *DogStatsdSink) SetGauge(key []string, val float32) {
+	s.SetGaugeWithLabels(key, val, nil)
+}
+
+func (s *DogStatsdSink) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	s.send(key, val, "g", labels)
+}
+
+func (s *DogStatsdSink) EmitKey(key []string, val float32) {
+	s.send(key, val, "g", nil)
+}
+
+func (s *DogStatsdSink) IncrCounter(key []string, val float32) {
+	s.IncrCounterWithLabels(key, val, nil)
+}
+
+func (s *DogStatsdSink) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	s.send(key, val, "c", labels)
+}
+
+func (s *DogStatsdSink) AddSample(key []string, val float32) {
+	s.AddSampleWithLabels(key, val, nil)
+}
+
+func (s *DogStatsdSink) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	s.send(key, val, "ms", labels)
+}
+
+// send writes a single metric to the agent. Errors are ignored, since metrics
+// are best-effort and UDP writes only fail locally.
+func (s *DogStatsdSink) send(key []string, val float32, typ string, labels []metrics.Label) {
+	s.conn.Write(formatDogStatsd(key, val, typ, s.tags, labels))
+}
+
+// formatDogStatsd formats a metric in the DogStatsD datagram format:
+//
+//	name:value|type|#tag1:value1,tag2:value2
+func formatDogStatsd(key []string, val float32, typ string, tags []string, labels []metrics.Label) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(dogStatsdSanitize(strings.Join(key, ".")))
+	buf.WriteByte(':')
+	buf.WriteString(strconv.FormatFloat(float64(val), 'f', -1, 32))
+	buf.WriteByte('|')
+	buf.WriteString(typ)
+
+	if len(tags) > 0 || len(labels) > 0 {
+		all := make([]string, 0, len(tags)+len(labels))
+		all = append(all, tags...)
+		for _, l := range labels {
+			all = append(all, dogStatsdSanitize(l.Name)+":"+dogStatsdSanitize(l.Value))
+		}
+		buf.WriteString("|#")
+		buf.WriteString(strings.Join(all, ","))
+	}
+
+	return buf.Bytes()
+}
+
+// dogStatsdSanitize replaces the characters which have a special meaning in
+// the DogStatsD format.
+func dogStatsdSanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', ',', '#', ' ':
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
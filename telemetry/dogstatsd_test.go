@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+func TestFormatDogStatsd(t *testing.T) {
+	cases := []struct {
+		name   string
+		key    []string
+		val    float32
+		typ    string
+		tags   []string
+		labels []metrics.Label
+		exp    string
+	}{
+		{
+			"counter",
+			[]string{"view", "retry"},
+			1,
+			"c",
+			nil,
+			nil,
+			"view.retry:1|c",
+		},
+		{
+			"sample_fraction",
+			[]string{"view", "fetch"},
+			1.5,
+			"ms",
+			nil,
+			nil,
+			"view.fetch:1.5|ms",
+		},
+		{
+			"tags",
+			[]string{"dedup", "leader"},
+			0,
+			"g",
+			[]string{"env:prod"},
+			nil,
+			"dedup.leader:0|g|#env:prod",
+		},
+		{
+			"labels",
+			[]string{"view", "retry"},
+			1,
+			"c",
+			[]string{"env:prod"},
+			[]metrics.Label{{Name: "type", Value: "consul"}},
+			"view.retry:1|c|#env:prod,type:consul",
+		},
+		{
+			"sanitizes",
+			[]string{"a:b", "c d"},
+			1,
+			"c",
+			nil,
+			[]metrics.Label{{Name: "x", Value: "y|z"}},
+			"a_b.c_d:1|c|#x:y_z",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			act := string(formatDogStatsd(tc.key, tc.val, tc.typ, tc.tags, tc.labels))
+			if act != tc.exp {
+				t.Errorf("expected %q to be %q", act, tc.exp)
+			}
+		})
+	}
+}
+
+func TestDogStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s, err := NewDogStatsdSink(conn.LocalAddr().String(), []string{"env:test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Shutdown()
+
+	s.IncrCounter([]string{"runner", "template", "rendered"}, 1)
+
+	buf := make([]byte, 512)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "runner.template.rendered:1|c|#env:test"
+	if act := string(buf[:n]); act != expected {
+		t.Errorf("expected %q to be %q", act, expected)
+	}
+}
@@ -0,0 +1,172 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// PrometheusSink is a metrics.MetricSink which keeps the current value of each
+// metric in memory and serves them in the Prometheus text exposition format.
+// Counters and samples are cumulative for the lifetime of the sink. Samples
+// are exposed as summaries with a sum and a count, but no quantiles.
+type PrometheusSink struct {
+	sync.Mutex
+	metrics map[string]*promMetric
+}
+
+// promMetric is a single time series.
+type promMetric struct {
+	name   string
+	typ    string
+	labels string
+	value  float64
+	count  uint64
+}
+
+// NewPrometheusSink creates a new, empty sink.
+func NewPrometheusSink() *PrometheusSink {
+	return &PrometheusSink{
+		metrics: make(map[string]*promMetric),
+	}
+}
+
+func (s *PrometheusSink) SetGauge(key []string, val float32) {
+	s.SetGaugeWithLabels(key, val, nil)
+}
+
+func (s *PrometheusSink) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	m := s.metric(key, "gauge", labels)
+	m.value = float64(val)
+	s.Unlock()
+}
+
+func (s *PrometheusSink) EmitKey(key []string, val float32) {
+	s.SetGaugeWithLabels(key, val, nil)
+}
+
+func (s *PrometheusSink) IncrCounter(key []string, val float32) {
+	s.IncrCounterWithLabels(key, val, nil)
+}
+
+func (s *PrometheusSink) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	m := s.metric(key, "counter", labels)
+	m.value += float64(val)
+	s.Unlock()
+}
+
+func (s *PrometheusSink) AddSample(key []string, val float32) {
+	s.AddSampleWithLabels(key, val, nil)
+}
+
+func (s *PrometheusSink) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	m := s.metric(key, "summary", labels)
+	m.value += float64(val)
+	m.count++
+	s.Unlock()
+}
+
+// metric returns the time series for the given key, type and labels, creating
+// it if it does not exist. The sink is locked on return, and the caller is
+// responsible for unlocking it.
+func (s *PrometheusSink) metric(key []string, typ string, labels []metrics.Label) *promMetric {
+	name := promName(key)
+	l := promLabels(labels)
+	id := typ + "|" + name + "|" + l
+
+	s.Lock()
+	m, ok := s.metrics[id]
+	if !ok {
+		m = &promMetric{name: name, typ: typ, labels: l}
+		s.metrics[id] = m
+	}
+	return m
+}
+
+// ServeHTTP implements http.Handler.
+func (s *PrometheusSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(s.Bytes())
+}
+
+// Bytes returns the current value of every metric in the Prometheus text
+// exposition format.
+func (s *PrometheusSink) Bytes() []byte {
+	s.Lock()
+	list := make([]promMetric, 0, len(s.metrics))
+	for _, m := range s.metrics {
+		list = append(list, *m)
+	}
+	s.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].name != list[j].name {
+			return list[i].name < list[j].name
+		}
+		return list[i].labels < list[j].labels
+	})
+
+	var buf bytes.Buffer
+	var last string
+	for _, m := range list {
+		if m.name != last {
+			fmt.Fprintf(&buf, "# TYPE %s %s\n", m.name, m.typ)
+			last = m.name
+		}
+
+		switch m.typ {
+		case "summary":
+			fmt.Fprintf(&buf, "%s_sum%s %s\n", m.name, m.labels, promFloat(m.value))
+			fmt.Fprintf(&buf, "%s_count%s %d\n", m.name, m.labels, m.count)
+		default:
+			fmt.Fprintf(&buf, "%s%s %s\n", m.name, m.labels, promFloat(m.value))
+		}
+	}
+	return buf.Bytes()
+}
+
+// promName joins the key into a valid Prometheus metric name.
+func promName(key []string) string {
+	return promSanitize(strings.Join(key, "_"))
+}
+
+// promLabelEscaper escapes label values as required by the text format.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promLabels formats the labels as a Prometheus label set, sorted by name.
+func promLabels(labels []metrics.Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	list := make([]string, 0, len(labels))
+	for _, l := range labels {
+		list = append(list, fmt.Sprintf("%s=\"%s\"", promSanitize(l.Name), promLabelEscaper.Replace(l.Value)))
+	}
+	sort.Strings(list)
+	return "{" + strings.Join(list, ",") + "}"
+}
+
+// promSanitize replaces any characters which are not valid in a Prometheus
+// metric or label name.
+func promSanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// promFloat formats a float in the shortest representation.
+func promFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
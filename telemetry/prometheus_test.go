@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+func TestPrometheusSink_Bytes(t *testing.T) {
+	s := NewPrometheusSink()
+
+	labels := []metrics.Label{{Name: "type", Value: "consul"}}
+	s.IncrCounterWithLabels([]string{"view", "retry"}, 1, labels)
+	s.IncrCounterWithLabels([]string{"view", "retry"}, 2, labels)
+	s.SetGauge([]string{"dedup", "leader"}, 1)
+	s.AddSample([]string{"view", "fetch"}, 10)
+	s.AddSample([]string{"view", "fetch"}, 2.5)
+	s.SetGaugeWithLabels([]string{"label.escape"}, 0, []metrics.Label{
+		{Name: "b", Value: "a\"b\\c\nd"},
+		{Name: "a", Value: "1"},
+	})
+
+	expected := `# TYPE dedup_leader gauge
+dedup_leader 1
+# TYPE label_escape gauge
+label_escape{a="1",b="a\"b\\c\nd"} 0
+# TYPE view_fetch summary
+view_fetch_sum 12.5
+view_fetch_count 2
+# TYPE view_retry counter
+view_retry{type="consul"} 3
+`
+	if act := string(s.Bytes()); act != expected {
+		t.Errorf("\nexp: %s\nact: %s", expected, act)
+	}
+}
+
+func TestPrometheusSink_ServeHTTP(t *testing.T) {
+	s := NewPrometheusSink()
+	s.IncrCounter([]string{"runner", "template", "rendered"}, 1)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# TYPE runner_template_rendered counter\nrunner_template_rendered 1\n"
+	if string(body) != expected {
+		t.Errorf("expected %q to be %q", body, expected)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4" {
+		t.Errorf("bad content type %q", ct)
+	}
+}
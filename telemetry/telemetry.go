@@ -0,0 +1,140 @@
+package telemetry
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/pkg/errors"
+)
+
+// Config is the configuration for the metrics sinks.
+type Config struct {
+	// Prefix is the prefix added to the name of every metric.
+	Prefix string
+
+	// DisableHostname disables prefixing gauges with the hostname.
+	DisableHostname bool
+
+	// StatsdAddress is the address of a statsd server.
+	StatsdAddress string
+
+	// DogStatsdAddress and DogStatsdTags are the address of a DogStatsD agent
+	// and the tags to add to every metric sent to it.
+	DogStatsdAddress string
+	DogStatsdTags    []string
+
+	// PrometheusAddress is the address where the Prometheus endpoint is
+	// served.
+	PrometheusAddress string
+}
+
+// Telemetry holds the sinks created by Setup so they can be shut down when the
+// configuration is reloaded.
+type Telemetry struct {
+	statsd     *metrics.StatsdSink
+	dogstatsd  *DogStatsdSink
+	prometheus *http.Server
+	listener   net.Listener
+}
+
+// Setup creates the sinks for the given configuration and installs them as the
+// global metrics sink. If no sinks are configured, metrics are discarded.
+func Setup(c *Config) (*Telemetry, error) {
+	t := new(Telemetry)
+	var sinks metrics.FanoutSink
+
+	if c.StatsdAddress != "" {
+		log.Printf("[DEBUG] (telemetry) enabling statsd on %s", c.StatsdAddress)
+		s, err := metrics.NewStatsdSink(c.StatsdAddress)
+		if err != nil {
+			t.Stop()
+			return nil, errors.Wrap(err, "telemetry: statsd")
+		}
+		t.statsd = s
+		sinks = append(sinks, s)
+	}
+
+	if c.DogStatsdAddress != "" {
+		log.Printf("[DEBUG] (telemetry) enabling dogstatsd on %s", c.DogStatsdAddress)
+		s, err := NewDogStatsdSink(c.DogStatsdAddress, c.DogStatsdTags)
+		if err != nil {
+			t.Stop()
+			return nil, errors.Wrap(err, "telemetry: dogstatsd")
+		}
+		t.dogstatsd = s
+		sinks = append(sinks, s)
+	}
+
+	if c.PrometheusAddress != "" {
+		log.Printf("[DEBUG] (telemetry) enabling prometheus on %s", c.PrometheusAddress)
+		ln, err := net.Listen("tcp", c.PrometheusAddress)
+		if err != nil {
+			t.Stop()
+			return nil, errors.Wrap(err, "telemetry: prometheus")
+		}
+
+		s := NewPrometheusSink()
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", s)
+
+		t.listener = ln
+		t.prometheus = &http.Server{Handler: mux}
+		go func() {
+			if err := t.prometheus.Serve(ln); err != nil && err != http.ErrServerClosed {
+				log.Printf("[ERR] (telemetry) %s", err)
+			}
+		}()
+		sinks = append(sinks, s)
+	}
+
+	var sink metrics.MetricSink = &metrics.BlackholeSink{}
+	if len(sinks) > 0 {
+		sink = sinks
+	}
+
+	if _, err := metrics.NewGlobal(newMetricsConfig(c), sink); err != nil {
+		t.Stop()
+		return nil, errors.Wrap(err, "telemetry")
+	}
+
+	return t, nil
+}
+
+// Stop shuts down any sinks and listeners created by Setup. Metrics emitted
+// after Stop are discarded until Setup is called again.
+func (t *Telemetry) Stop() {
+	if t == nil {
+		return
+	}
+
+	// Replace the global sink before shutting down the existing sinks, since
+	// the statsd sink panics if it receives a metric after shutdown.
+	metrics.NewGlobal(newMetricsConfig(&Config{}), &metrics.BlackholeSink{})
+
+	if t.statsd != nil {
+		t.statsd.Shutdown()
+	}
+
+	if t.dogstatsd != nil {
+		t.dogstatsd.Shutdown()
+	}
+
+	if t.prometheus != nil {
+		t.prometheus.Close()
+		t.listener.Close()
+	}
+}
+
+// newMetricsConfig converts the given configuration into a go-metrics
+// configuration. Runtime metrics are disabled because go-metrics provides no
+// way to stop collecting them, which would leak a goroutine on each reload.
+func newMetricsConfig(c *Config) *metrics.Config {
+	conf := metrics.DefaultConfig(c.Prefix)
+	conf.EnableHostname = !c.DisableHostname
+	conf.EnableRuntimeMetrics = false
+	conf.TimerGranularity = time.Millisecond
+	return conf
+}
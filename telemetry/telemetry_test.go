@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+func TestSetup_prometheus(t *testing.T) {
+	tel, err := Setup(&Config{
+		Prefix:            "consul_template",
+		DisableHostname:   true,
+		PrometheusAddress: "127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tel.Stop()
+
+	metrics.IncrCounter([]string{"runner", "template", "rendered"}, 1)
+
+	resp, err := http.Get("http://" + tel.listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "consul_template_runner_template_rendered 1\n"
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("expected %q to contain %q", body, expected)
+	}
+}
+
+func TestTelemetry_Stop(t *testing.T) {
+	tel, err := Setup(&Config{
+		PrometheusAddress: "127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := tel.listener.Addr().String()
+	tel.Stop()
+
+	if _, err := http.Get("http://" + addr + "/metrics"); err == nil {
+		t.Errorf("expected listener to be closed")
+	}
+
+	// Stopping a nil Telemetry is a no-op.
+	var nilTel *Telemetry
+	nilTel.Stop()
+}
+
+func TestTestSink(t *testing.T) {
+	s := NewTestSink()
+	metrics.IncrCounterWithLabels([]string{"view", "retry"}, 1, []metrics.Label{
+		{Name: "type", Value: "consul"},
+	})
+
+	list := s.Metrics("view.retry")
+	if len(list) != 1 {
+		t.Fatalf("expected %d to be %d", len(list), 1)
+	}
+	if list[0].Type != "counter" {
+		t.Errorf("expected %q to be %q", list[0].Type, "counter")
+	}
+	if list[0].Labels["type"] != "consul" {
+		t.Errorf("expected %q to be %q", list[0].Labels["type"], "consul")
+	}
+}
@@ -0,0 +1,95 @@
+package telemetry
+
+import (
+	"strings"
+	"sync"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// TestMetric is a single metric received by a TestSink.
+type TestMetric struct {
+	// Type is one of "gauge", "key", "counter" or "sample".
+	Type string
+
+	// Name is the key of the metric joined with ".".
+	Name string
+
+	Value  float32
+	Labels map[string]string
+}
+
+// TestSink is a metrics.MetricSink which records every metric it receives, so
+// tests can verify emitted metrics without any outside service.
+type TestSink struct {
+	sync.Mutex
+	metrics []TestMetric
+}
+
+// NewTestSink creates a new TestSink and installs it as the global metrics
+// sink, without a prefix or hostname. Since the sink is global, tests which
+// run in parallel may see each other's metrics.
+func NewTestSink() *TestSink {
+	s := new(TestSink)
+	conf := newMetricsConfig(&Config{DisableHostname: true})
+	metrics.NewGlobal(conf, s)
+	return s
+}
+
+// Metrics returns every metric received with the given name.
+func (s *TestSink) Metrics(name string) []TestMetric {
+	s.Lock()
+	defer s.Unlock()
+
+	var result []TestMetric
+	for _, m := range s.metrics {
+		if m.Name == name {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func (s *TestSink) SetGauge(key []string, val float32) {
+	s.SetGaugeWithLabels(key, val, nil)
+}
+
+func (s *TestSink) SetGaugeWithLabels(key []string, val float32, labels []metrics.Label) {
+	s.record("gauge", key, val, labels)
+}
+
+func (s *TestSink) EmitKey(key []string, val float32) {
+	s.record("key", key, val, nil)
+}
+
+func (s *TestSink) IncrCounter(key []string, val float32) {
+	s.IncrCounterWithLabels(key, val, nil)
+}
+
+func (s *TestSink) IncrCounterWithLabels(key []string, val float32, labels []metrics.Label) {
+	s.record("counter", key, val, labels)
+}
+
+func (s *TestSink) AddSample(key []string, val float32) {
+	s.AddSampleWithLabels(key, val, nil)
+}
+
+func (s *TestSink) AddSampleWithLabels(key []string, val float32, labels []metrics.Label) {
+	s.record("sample", key, val, labels)
+}
+
+func (s *TestSink) record(typ string, key []string, val float32, labels []metrics.Label) {
+	m := TestMetric{
+		Type:   typ,
+		Name:   strings.Join(key, "."),
+		Value:  val,
+		Labels: make(map[string]string, len(labels)),
+	}
+	for _, l := range labels {
+		m.Labels[l.Name] = l.Value
+	}
+
+	s.Lock()
+	s.metrics = append(s.metrics, m)
+	s.Unlock()
+}
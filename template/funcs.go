@@ -124,6 +124,58 @@ func fileFunc(b *Brain, used, missing *dep.Set) func(string) (string, error) {
 	}
 }
 
+// filesFunc returns or accumulates file glob dependencies.
+func filesFunc(b *Brain, used, missing *dep.Set) func(string) ([]*dep.FileEntry, error) {
+	return func(s string) ([]*dep.FileEntry, error) {
+		result := []*dep.FileEntry{}
+
+		if len(s) == 0 {
+			return result, nil
+		}
+
+		d, err := dep.NewFileGlobQuery(s)
+		if err != nil {
+			return result, err
+		}
+
+		used.Add(d)
+
+		if value, ok := b.Recall(d); ok {
+			return value.([]*dep.FileEntry), nil
+		}
+
+		missing.Add(d)
+
+		return result, nil
+	}
+}
+
+// fileTreeFunc returns or accumulates file tree dependencies.
+func fileTreeFunc(b *Brain, used, missing *dep.Set) func(string) ([]*dep.FileEntry, error) {
+	return func(s string) ([]*dep.FileEntry, error) {
+		result := []*dep.FileEntry{}
+
+		if len(s) == 0 {
+			return result, nil
+		}
+
+		d, err := dep.NewFileTreeQuery(s)
+		if err != nil {
+			return result, err
+		}
+
+		used.Add(d)
+
+		if value, ok := b.Recall(d); ok {
+			return value.([]*dep.FileEntry), nil
+		}
+
+		missing.Add(d)
+
+		return result, nil
+	}
+}
+
 // keyFunc returns or accumulates key dependencies.
 func keyFunc(b *Brain, used, missing *dep.Set) func(string) (string, error) {
 	return func(s string) (string, error) {
@@ -262,7 +314,9 @@ func nodesFunc(b *Brain, used, missing *dep.Set) func(...string) ([]*dep.Node, e
 	return func(s ...string) ([]*dep.Node, error) {
 		result := []*dep.Node{}
 
-		d, err := dep.NewCatalogNodesQuery(strings.Join(s, ""))
+		args, opts := splitQueryOptions(s)
+
+		d, err := dep.NewCatalogNodesQuery(strings.Join(args, ""), opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -279,6 +333,46 @@ func nodesFunc(b *Brain, used, missing *dep.Set) func(...string) ([]*dep.Node, e
 	}
 }
 
+// splitQueryOptions separates the "key=value" options, such as
+// "node-meta=rack:r1", from the positional arguments given to a query function.
+func splitQueryOptions(s []string) ([]string, []string) {
+	var args, opts []string
+	for _, v := range s {
+		if strings.Contains(v, "=") {
+			opts = append(opts, v)
+		} else {
+			args = append(args, v)
+		}
+	}
+	return args, opts
+}
+
+// preparedQueryFunc returns or accumulates prepared query dependencies.
+func preparedQueryFunc(b *Brain, used, missing *dep.Set) func(...string) ([]*dep.HealthService, error) {
+	return func(s ...string) ([]*dep.HealthService, error) {
+		result := []*dep.HealthService{}
+
+		if len(s) == 0 || s[0] == "" {
+			return result, nil
+		}
+
+		d, err := dep.NewPreparedQueryQuery(strings.Join(s, "|"))
+		if err != nil {
+			return nil, err
+		}
+
+		used.Add(d)
+
+		if value, ok := b.Recall(d); ok {
+			return value.([]*dep.HealthService), nil
+		}
+
+		missing.Add(d)
+
+		return result, nil
+	}
+}
+
 // secretFunc returns or accumulates secret dependencies from Vault.
 func secretFunc(b *Brain, used, missing *dep.Set) func(...string) (*dep.Secret, error) {
 	return func(s ...string) (*dep.Secret, error) {
@@ -359,11 +453,12 @@ func serviceFunc(b *Brain, used, missing *dep.Set) func(...string) ([]*dep.Healt
 	return func(s ...string) ([]*dep.HealthService, error) {
 		result := []*dep.HealthService{}
 
-		if len(s) == 0 || s[0] == "" {
+		args, opts := splitQueryOptions(s)
+		if len(args) == 0 || args[0] == "" {
 			return result, nil
 		}
 
-		d, err := dep.NewHealthServiceQuery(strings.Join(s, "|"))
+		d, err := dep.NewHealthServiceQuery(strings.Join(args, "|"), opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -151,23 +151,12 @@ func (t *Template) Execute(i *ExecuteInput) (*ExecuteResult, error) {
 
 	var used, missing dep.Set
 
-	tmpl := template.New("")
-	tmpl.Delims(t.leftDelim, t.rightDelim)
-	tmpl.Funcs(funcMap(&funcMapInput{
-		t:       tmpl,
+	tmpl, err := t.parse(&funcMapInput{
 		brain:   i.Brain,
 		env:     i.Env,
 		used:    &used,
 		missing: &missing,
-	}))
-
-	if t.errMissingKey {
-		tmpl.Option("missingkey=error")
-	} else {
-		tmpl.Option("missingkey=zero")
-	}
-
-	tmpl, err := tmpl.Parse(t.contents)
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "parse")
 	}
@@ -185,6 +174,38 @@ func (t *Template) Execute(i *ExecuteInput) (*ExecuteResult, error) {
 	}, nil
 }
 
+// Validate returns an error if the template cannot be parsed, without executing
+// it.
+func (t *Template) Validate() error {
+	_, err := t.parse(&funcMapInput{
+		brain:   NewBrain(),
+		used:    new(dep.Set),
+		missing: new(dep.Set),
+	})
+	if err != nil {
+		return errors.Wrap(err, "parse")
+	}
+	return nil
+}
+
+// parse parses the contents of the template with the template functions built
+// from the given input.
+func (t *Template) parse(i *funcMapInput) (*template.Template, error) {
+	tmpl := template.New("")
+	tmpl.Delims(t.leftDelim, t.rightDelim)
+
+	i.t = tmpl
+	tmpl.Funcs(funcMap(i))
+
+	if t.errMissingKey {
+		tmpl.Option("missingkey=error")
+	} else {
+		tmpl.Option("missingkey=zero")
+	}
+
+	return tmpl.Parse(t.contents)
+}
+
 // funcMapInput is input to the funcMap, which builds the template functions.
 type funcMapInput struct {
 	t       *template.Template
@@ -200,19 +221,22 @@ func funcMap(i *funcMapInput) template.FuncMap {
 
 	return template.FuncMap{
 		// API functions
-		"datacenters":  datacentersFunc(i.brain, i.used, i.missing),
-		"file":         fileFunc(i.brain, i.used, i.missing),
-		"key":          keyFunc(i.brain, i.used, i.missing),
-		"keyExists":    keyExistsFunc(i.brain, i.used, i.missing),
-		"keyOrDefault": keyWithDefaultFunc(i.brain, i.used, i.missing),
-		"ls":           lsFunc(i.brain, i.used, i.missing),
-		"node":         nodeFunc(i.brain, i.used, i.missing),
-		"nodes":        nodesFunc(i.brain, i.used, i.missing),
-		"secret":       secretFunc(i.brain, i.used, i.missing),
-		"secrets":      secretsFunc(i.brain, i.used, i.missing),
-		"service":      serviceFunc(i.brain, i.used, i.missing),
-		"services":     servicesFunc(i.brain, i.used, i.missing),
-		"tree":         treeFunc(i.brain, i.used, i.missing),
+		"datacenters":   datacentersFunc(i.brain, i.used, i.missing),
+		"file":          fileFunc(i.brain, i.used, i.missing),
+		"fileTree":      fileTreeFunc(i.brain, i.used, i.missing),
+		"files":         filesFunc(i.brain, i.used, i.missing),
+		"key":           keyFunc(i.brain, i.used, i.missing),
+		"keyExists":     keyExistsFunc(i.brain, i.used, i.missing),
+		"keyOrDefault":  keyWithDefaultFunc(i.brain, i.used, i.missing),
+		"ls":            lsFunc(i.brain, i.used, i.missing),
+		"node":          nodeFunc(i.brain, i.used, i.missing),
+		"nodes":         nodesFunc(i.brain, i.used, i.missing),
+		"preparedQuery": preparedQueryFunc(i.brain, i.used, i.missing),
+		"secret":        secretFunc(i.brain, i.used, i.missing),
+		"secrets":       secretsFunc(i.brain, i.used, i.missing),
+		"service":       serviceFunc(i.brain, i.used, i.missing),
+		"services":      servicesFunc(i.brain, i.used, i.missing),
+		"tree":          treeFunc(i.brain, i.used, i.missing),
 
 		// Scratch
 		"scratch": func() *Scratch { return &scratch },
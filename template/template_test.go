@@ -121,6 +121,59 @@ func TestNewTemplate(t *testing.T) {
 	}
 }
 
+func TestTemplate_Validate(t *testing.T) {
+	cases := []struct {
+		name string
+		i    *NewTemplateInput
+		err  bool
+	}{
+		{
+			"valid",
+			&NewTemplateInput{
+				Contents: `{{ key "foo" }}`,
+			},
+			false,
+		},
+		{
+			"unknown_function",
+			&NewTemplateInput{
+				Contents: `{{ nope "foo" }}`,
+			},
+			true,
+		},
+		{
+			"unclosed_action",
+			&NewTemplateInput{
+				Contents: `{{ key "foo" `,
+			},
+			true,
+		},
+		{
+			"delims",
+			&NewTemplateInput{
+				Contents:   `<< key "foo" >>{{`,
+				LeftDelim:  "<<",
+				RightDelim: ">>",
+			},
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			tmpl, err := NewTemplate(tc.i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tmpl.Validate()
+			if (err != nil) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
 func TestTemplate_Execute(t *testing.T) {
 	now = func() time.Time { return time.Unix(0, 0).UTC() }
 
@@ -302,6 +355,61 @@ func TestTemplate_Execute(t *testing.T) {
 			"content",
 			false,
 		},
+		{
+			"func_files",
+			&NewTemplateInput{
+				Contents: `{{ range files "/etc/app/conf.d/*.json" }}{{ .Name }}={{ .Contents }} {{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewFileGlobQuery("/etc/app/conf.d/*.json")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.FileEntry{
+						&dep.FileEntry{Path: "/etc/app/conf.d/a.json", Name: "a.json", Contents: "a"},
+						&dep.FileEntry{Path: "/etc/app/conf.d/b.json", Name: "b.json", Contents: "b"},
+					})
+					return b
+				}(),
+			},
+			"a.json=a b.json=b ",
+			false,
+		},
+		{
+			"func_files_no_exist",
+			&NewTemplateInput{
+				Contents: `{{ files "/etc/app/conf.d/*.json" }}`,
+			},
+			&ExecuteInput{
+				Brain: NewBrain(),
+			},
+			"[]",
+			false,
+		},
+		{
+			"func_fileTree",
+			&NewTemplateInput{
+				Contents: `{{ range fileTree "/etc/app" }}{{ .Name }}:{{ .Path }} {{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewFileTreeQuery("/etc/app")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.FileEntry{
+						&dep.FileEntry{Path: "/etc/app/a.json", Name: "a.json"},
+						&dep.FileEntry{Path: "/etc/app/conf.d/b.json", Name: "conf.d/b.json"},
+					})
+					return b
+				}(),
+			},
+			"a.json:/etc/app/a.json conf.d/b.json:/etc/app/conf.d/b.json ",
+			false,
+		},
 		{
 			"func_key",
 			&NewTemplateInput{
@@ -431,6 +539,27 @@ func TestTemplate_Execute(t *testing.T) {
 			"node1node2",
 			false,
 		},
+		{
+			"func_nodes_node_meta",
+			&NewTemplateInput{
+				Contents: `{{ range nodes "@dc1" "node-meta=rack:r1" }}{{ .Node }}{{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewCatalogNodesQuery("@dc1", "node-meta=rack:r1")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.Node{
+						&dep.Node{Node: "node1"},
+					})
+					return b
+				}(),
+			},
+			"node1",
+			false,
+		},
 		{
 			"func_secret_read",
 			&NewTemplateInput{
@@ -604,6 +733,57 @@ func TestTemplate_Execute(t *testing.T) {
 			"",
 			false,
 		},
+		{
+			"func_preparedQuery",
+			&NewTemplateInput{
+				Contents: `{{ range preparedQuery "webapp-failover" }}{{ .Node }}:{{ .Address }} {{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewPreparedQueryQuery("webapp-failover")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.HealthService{
+						&dep.HealthService{
+							Node:    "node2",
+							Address: "5.6.7.8",
+						},
+						&dep.HealthService{
+							Node:    "node1",
+							Address: "1.2.3.4",
+						},
+					})
+					return b
+				}(),
+			},
+			"node2:5.6.7.8 node1:1.2.3.4 ",
+			false,
+		},
+		{
+			"func_preparedQuery_poll",
+			&NewTemplateInput{
+				Contents: `{{ range preparedQuery "webapp-failover" "30s" }}{{ .Address }}{{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewPreparedQueryQuery("webapp-failover|30s")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.HealthService{
+						&dep.HealthService{
+							Address: "1.2.3.4",
+						},
+					})
+					return b
+				}(),
+			},
+			"1.2.3.4",
+			false,
+		},
 		{
 			"func_service",
 			&NewTemplateInput{
@@ -660,6 +840,54 @@ func TestTemplate_Execute(t *testing.T) {
 			"1.2.3.45.6.7.8",
 			false,
 		},
+		{
+			"func_service_node_meta",
+			&NewTemplateInput{
+				Contents: `{{ range service "webapp" "node-meta=rack:r1" "tag=v2" }}{{ .Address }}{{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewHealthServiceQuery("webapp", "node-meta=rack:r1", "tag=v2")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.HealthService{
+						&dep.HealthService{
+							Node:    "node1",
+							Address: "1.2.3.4",
+						},
+					})
+					return b
+				}(),
+			},
+			"1.2.3.4",
+			false,
+		},
+		{
+			"func_service_tags",
+			&NewTemplateInput{
+				Contents: `{{ range service "primary.webapp" "tag=v2" "exclude-tag=canary" }}{{ .Address }}{{ end }}`,
+			},
+			&ExecuteInput{
+				Brain: func() *Brain {
+					b := NewBrain()
+					d, err := dep.NewHealthServiceQuery("webapp", "exclude-tag=canary", "tag=v2", "tag=primary")
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.Remember(d, []*dep.HealthService{
+						&dep.HealthService{
+							Node:    "node1",
+							Address: "1.2.3.4",
+						},
+					})
+					return b
+				}(),
+			},
+			"1.2.3.4",
+			false,
+		},
 		{
 			"func_services",
 			&NewTemplateInput{
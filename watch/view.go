@@ -2,12 +2,13 @@ package watch
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 	"sync"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	dep "github.com/hashicorp/consul-template/dependency"
+	"github.com/hashicorp/consul-template/logging"
 )
 
 const (
@@ -32,6 +33,12 @@ type View struct {
 	receivedData bool
 	lastIndex    uint64
 
+	// lastContact is the last time a non-error response was received from the
+	// upstream and retries is the number of consecutive failed attempts. These
+	// are only used for reporting and are protected by dataLock.
+	lastContact time.Time
+	retries     int
+
 	// maxStale is the maximum amount of time to allow a query to be stale.
 	maxStale time.Duration
 
@@ -49,6 +56,10 @@ type View struct {
 	// Consul Template will generate a new secret instead of renewing an existing
 	// one.
 	vaultGrace time.Duration
+
+	// logger is the logger of this View, which adds the dependency to each
+	// line.
+	logger *logging.Logger
 }
 
 // NewViewInput is used as input to the NewView function.
@@ -87,6 +98,7 @@ func NewView(i *NewViewInput) (*View, error) {
 		retryFunc:  i.RetryFunc,
 		stopCh:     make(chan struct{}, 1),
 		vaultGrace: i.VaultGrace,
+		logger:     logging.New("view").With("dependency", i.Dependency.String()),
 	}, nil
 }
 
@@ -111,6 +123,45 @@ func (v *View) DataAndLastIndex() (interface{}, uint64) {
 	return v.data, v.lastIndex
 }
 
+// ViewStatus is a point-in-time summary of the polling state of a View.
+type ViewStatus struct {
+	// Dependency is the string representation of the view's dependency.
+	Dependency string `json:"dependency"`
+
+	// ReceivedData indicates the view has received data at least once.
+	ReceivedData bool `json:"received_data"`
+
+	// LastIndex is the index of the most recent response.
+	LastIndex uint64 `json:"last_index"`
+
+	// LastContact is the last time a non-error response was received. It is
+	// the zero time if the view has never successfully contacted the upstream.
+	LastContact time.Time `json:"last_contact"`
+
+	// Retries is the number of consecutive failed fetches.
+	Retries int `json:"retries"`
+}
+
+// Status returns a summary of the polling state of this View.
+func (v *View) Status() ViewStatus {
+	v.dataLock.RLock()
+	defer v.dataLock.RUnlock()
+	return ViewStatus{
+		Dependency:   v.dependency.String(),
+		ReceivedData: v.receivedData,
+		LastIndex:    v.lastIndex,
+		LastContact:  v.lastContact,
+		Retries:      v.retries,
+	}
+}
+
+// setRetries records the current retry count for reporting.
+func (v *View) setRetries(retries int) {
+	v.dataLock.Lock()
+	v.retries = retries
+	v.dataLock.Unlock()
+}
+
 // poll queries the Consul instance for data using the fetch function, but also
 // accounts for interrupts on the interrupt channel. This allows the poll
 // function to be fired in a goroutine, but then halted even if the fetch
@@ -130,8 +181,10 @@ func (v *View) poll(viewCh chan<- *View, errCh chan<- error) {
 			// Reset the retry to avoid exponentially incrementing retries when we
 			// have some successful requests
 			retries = 0
+			v.setRetries(retries)
 
-			log.Printf("[TRACE] (view) %s received data", v.dependency)
+			_, index := v.DataAndLastIndex()
+			v.logger.With("index", index).Printf("[TRACE] %s received data", v.dependency)
 			select {
 			case <-v.stopCh:
 				return
@@ -150,18 +203,23 @@ func (v *View) poll(viewCh chan<- *View, errCh chan<- error) {
 			// example, Consul make have an outage, but when it returns, the view
 			// is unchanged. We have to reset the counter retries, but not update the
 			// actual template.
-			log.Printf("[TRACE] (view) %s successful contact, resetting retries", v.dependency)
+			v.logger.Printf("[TRACE] %s successful contact, resetting retries", v.dependency)
 			retries = 0
+			v.setRetries(retries)
 			goto WAIT
 		case err := <-fetchErrCh:
+			v.setRetries(retries + 1)
+			metrics.IncrCounterWithLabels([]string{"view", "fetch", "error"}, 1, v.metricLabels())
+
 			if v.retryFunc != nil {
 				retry, sleep := v.retryFunc(retries)
 				if retry {
-					log.Printf("[WARN] (view) %s (retry attempt %d after %q)",
+					v.logger.With("retry", retries+1).Printf("[WARN] %s (retry attempt %d after %q)",
 						err, retries+1, sleep)
 					select {
 					case <-time.After(sleep):
 						retries++
+						metrics.IncrCounterWithLabels([]string{"view", "retry"}, 1, v.metricLabels())
 						continue
 					case <-v.stopCh:
 						return
@@ -169,7 +227,7 @@ func (v *View) poll(viewCh chan<- *View, errCh chan<- error) {
 				}
 			}
 
-			log.Printf("[ERR] (view) %s (exceeded maximum retries)", err)
+			v.logger.With("retry", retries).Printf("[ERR] %s (exceeded maximum retries)", err)
 
 			// Push the error back up to the watcher
 			select {
@@ -179,7 +237,7 @@ func (v *View) poll(viewCh chan<- *View, errCh chan<- error) {
 				return
 			}
 		case <-v.stopCh:
-			log.Printf("[TRACE] (view) %s stopping poll (received on view stopCh)", v.dependency)
+			v.logger.Printf("[TRACE] %s stopping poll (received on view stopCh)", v.dependency)
 			return
 		}
 	}
@@ -191,7 +249,7 @@ func (v *View) poll(viewCh chan<- *View, errCh chan<- error) {
 // result of doneCh and errCh. It is assumed that only one instance of fetch
 // is running per View and therefore no locking or mutexes are used.
 func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
-	log.Printf("[TRACE] (view) %s starting fetch", v.dependency)
+	v.logger.Printf("[TRACE] %s starting fetch", v.dependency)
 
 	var allowStale bool
 	if v.maxStale != 0 {
@@ -207,15 +265,17 @@ func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
 		default:
 		}
 
+		start := time.Now()
 		data, rm, err := v.dependency.Fetch(v.clients, &dep.QueryOptions{
 			AllowStale: allowStale,
 			WaitTime:   defaultWaitTime,
 			WaitIndex:  v.lastIndex,
 			VaultGrace: v.vaultGrace,
 		})
+		metrics.MeasureSinceWithLabels([]string{"view", "fetch"}, start, v.metricLabels())
 		if err != nil {
 			if err == dep.ErrStopped {
-				log.Printf("[TRACE] (view) %s reported stop", v.dependency)
+				v.logger.Printf("[TRACE] %s reported stop", v.dependency)
 			} else {
 				errCh <- err
 			}
@@ -231,7 +291,10 @@ func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
 		// If we got this far, we received data successfully. That data might not
 		// trigger a data update (because we could continue below), but we need to
 		// inform the poller to reset the retry count.
-		log.Printf("[TRACE] (view) %s marking successful data response", v.dependency)
+		v.logger.Printf("[TRACE] %s marking successful data response", v.dependency)
+		v.dataLock.Lock()
+		v.lastContact = time.Now()
+		v.dataLock.Unlock()
 		select {
 		case successCh <- struct{}{}:
 		default:
@@ -239,7 +302,8 @@ func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
 
 		if allowStale && rm.LastContact > v.maxStale {
 			allowStale = false
-			log.Printf("[TRACE] (view) %s stale data (last contact exceeded max_stale)", v.dependency)
+			v.logger.Printf("[TRACE] %s stale data (last contact exceeded max_stale)", v.dependency)
+			metrics.IncrCounterWithLabels([]string{"view", "stale_fallback"}, 1, v.metricLabels())
 			continue
 		}
 
@@ -248,13 +312,14 @@ func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
 		}
 
 		if rm.LastIndex == v.lastIndex {
-			log.Printf("[TRACE] (view) %s no new data (index was the same)", v.dependency)
+			v.logger.With("index", rm.LastIndex).Printf("[TRACE] %s no new data (index was the same)", v.dependency)
+			metrics.IncrCounterWithLabels([]string{"view", "same_index"}, 1, v.metricLabels())
 			continue
 		}
 
 		v.dataLock.Lock()
 		if rm.LastIndex < v.lastIndex {
-			log.Printf("[TRACE] (view) %s had a lower index, resetting", v.dependency)
+			v.logger.With("index", rm.LastIndex).Printf("[TRACE] %s had a lower index, resetting", v.dependency)
 			v.lastIndex = 0
 			v.dataLock.Unlock()
 			continue
@@ -262,13 +327,13 @@ func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
 		v.lastIndex = rm.LastIndex
 
 		if v.receivedData && reflect.DeepEqual(data, v.data) {
-			log.Printf("[TRACE] (view) %s no new data (contents were the same)", v.dependency)
+			v.logger.Printf("[TRACE] %s no new data (contents were the same)", v.dependency)
 			v.dataLock.Unlock()
 			continue
 		}
 
 		if data == nil && rm.Block {
-			log.Printf("[TRACE] (view) %s asked for blocking query", v.dependency)
+			v.logger.Printf("[TRACE] %s asked for blocking query", v.dependency)
 			v.dataLock.Unlock()
 			continue
 		}
@@ -282,6 +347,11 @@ func (v *View) fetch(doneCh, successCh chan<- struct{}, errCh chan<- error) {
 	}
 }
 
+// metricLabels returns the labels attached to metrics emitted by this view.
+func (v *View) metricLabels() []metrics.Label {
+	return []metrics.Label{{Name: "type", Value: v.dependency.Type().String()}}
+}
+
 // stop halts polling of this view.
 func (v *View) stop() {
 	v.dependency.Stop()
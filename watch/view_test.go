@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/hashicorp/consul-template/telemetry"
 )
 
 func TestPoll_returnsViewCh(t *testing.T) {
@@ -139,6 +141,10 @@ func TestPoll_retries(t *testing.T) {
 	case <-time.After(100 * time.Millisecond):
 	}
 
+	if status := view.Status(); status.Retries != 1 {
+		t.Errorf("expected %d to be %d", status.Retries, 1)
+	}
+
 	select {
 	case <-viewCh:
 		// Got this far, so the test passes
@@ -174,6 +180,57 @@ func TestFetch_resetRetries(t *testing.T) {
 	}
 }
 
+func TestFetch_metrics(t *testing.T) {
+	sink := telemetry.NewTestSink()
+
+	view, err := NewView(&NewViewInput{
+		Dependency: &TestDepSameIndex{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer view.stop()
+
+	errCh := make(chan error)
+
+	// The first fetch receives data
+	doneCh := make(chan struct{})
+	go view.fetch(doneCh, make(chan struct{}, 1), errCh)
+
+	select {
+	case <-doneCh:
+	case err := <-errCh:
+		t.Fatalf("error while fetching: %s", err)
+	}
+
+	// The next fetch returns the same index, so it loops. Waiting for a second
+	// successful response ensures the first was recorded.
+	successCh := make(chan struct{})
+	go view.fetch(make(chan struct{}), successCh, errCh)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-successCh:
+		case err := <-errCh:
+			t.Fatalf("error while fetching: %s", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	fetches := sink.Metrics("view.fetch")
+	if len(fetches) == 0 {
+		t.Fatal("expected view.fetch to be emitted")
+	}
+	if fetches[0].Type != "sample" || fetches[0].Labels["type"] != "local" {
+		t.Errorf("bad metric %#v", fetches[0])
+	}
+
+	if len(sink.Metrics("view.same_index")) == 0 {
+		t.Error("expected view.same_index to be emitted")
+	}
+}
+
 func TestFetch_maxStale(t *testing.T) {
 	view, err := NewView(&NewViewInput{
 		Dependency: &TestDepStale{},
@@ -225,6 +282,48 @@ func TestFetch_savesView(t *testing.T) {
 	}
 }
 
+func TestStatus_afterFetch(t *testing.T) {
+	view, err := NewView(&NewViewInput{
+		Dependency: &TestDep{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := view.Status()
+	if status.ReceivedData {
+		t.Errorf("expected no data before fetching")
+	}
+	if !status.LastContact.IsZero() {
+		t.Errorf("expected %s to be zero", status.LastContact)
+	}
+
+	doneCh := make(chan struct{})
+	successCh := make(chan struct{}, 1)
+	errCh := make(chan error)
+
+	go view.fetch(doneCh, successCh, errCh)
+
+	select {
+	case <-doneCh:
+		status := view.Status()
+		if status.Dependency != view.Dependency().String() {
+			t.Errorf("expected %q to be %q", status.Dependency, view.Dependency().String())
+		}
+		if !status.ReceivedData {
+			t.Errorf("expected data to be received")
+		}
+		if status.LastIndex != 1 {
+			t.Errorf("expected %d to be %d", status.LastIndex, 1)
+		}
+		if status.LastContact.IsZero() {
+			t.Errorf("expected last contact to be set")
+		}
+	case err := <-errCh:
+		t.Errorf("error while fetching: %s", err)
+	}
+}
+
 func TestFetch_returnsErrCh(t *testing.T) {
 	view, err := NewView(&NewViewInput{
 		Dependency: &TestDepFetchError{},
@@ -2,6 +2,7 @@ package watch
 
 import (
 	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -62,6 +63,11 @@ type NewWatcherInput struct {
 	// RenewVault indicates if this watcher should renew Vault tokens.
 	RenewVault bool
 
+	// VaultAuth indicates the Vault token was obtained by logging in with an
+	// auth method, so the watcher logs in again before it expires, whether or
+	// not it is renewed.
+	VaultAuth bool
+
 	// VaultToken is the vault token to renew.
 	VaultToken string
 
@@ -92,8 +98,14 @@ func NewWatcher(i *NewWatcherInput) (*Watcher, error) {
 	}
 
 	// Start a watcher for the Vault renew if that config was specified
-	if i.RenewVault {
-		vt, err := dep.NewVaultTokenQuery(i.VaultToken)
+	if i.RenewVault || i.VaultAuth {
+		var vt *dep.VaultTokenQuery
+		var err error
+		if i.VaultAuth {
+			vt, err = dep.NewVaultAuthTokenQuery(i.VaultToken, i.RenewVault)
+		} else {
+			vt, err = dep.NewVaultTokenQuery(i.VaultToken)
+		}
 		if err != nil {
 			return nil, errors.Wrap(err, "watcher")
 		}
@@ -209,6 +221,27 @@ func (w *Watcher) Remove(d dep.Dependency) bool {
 	return false
 }
 
+// Status returns the status of each view this watcher is watching, sorted by
+// dependency.
+func (w *Watcher) Status() []ViewStatus {
+	w.Lock()
+	defer w.Unlock()
+
+	result := make([]ViewStatus, 0, len(w.depViewMap))
+	for _, view := range w.depViewMap {
+		if view == nil {
+			continue
+		}
+		result = append(result, view.Status())
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Dependency < result[j].Dependency
+	})
+
+	return result
+}
+
 // Size returns the number of views this watcher is watching.
 func (w *Watcher) Size() int {
 	w.Lock()
@@ -109,6 +109,30 @@ func TestWatching_exists(t *testing.T) {
 	}
 }
 
+func TestNewWatcher_vaultAuth(t *testing.T) {
+	w, err := NewWatcher(&NewWatcherInput{
+		Clients:    dep.NewClientSet(),
+		Once:       true,
+		RenewVault: false,
+		VaultAuth:  true,
+		VaultToken: "my-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// The token obtained by logging in is watched even when it is not renewed,
+	// so it is replaced before it expires.
+	d, err := dep.NewVaultTokenQuery("my-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.Watching(d) {
+		t.Errorf("expected to be watching")
+	}
+}
+
 func TestRemove_exists(t *testing.T) {
 	w, err := NewWatcher(&NewWatcherInput{
 		Clients: dep.NewClientSet(),
@@ -182,3 +206,31 @@ func TestSize_returnsNumViews(t *testing.T) {
 		t.Errorf("expected %d to be %d", w.Size(), 10)
 	}
 }
+
+func TestStatus_sortedViews(t *testing.T) {
+	w, err := NewWatcher(&NewWatcherInput{
+		Clients: dep.NewClientSet(),
+		Once:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"c", "a", "b"} {
+		if _, err := w.Add(&TestDep{name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status := w.Status()
+	if len(status) != 3 {
+		t.Fatalf("expected %d to be %d", len(status), 3)
+	}
+
+	for i, name := range []string{"a", "b", "c"} {
+		expected := (&TestDep{name: name}).String()
+		if status[i].Dependency != expected {
+			t.Errorf("expected %q to be %q", status[i].Dependency, expected)
+		}
+	}
+}
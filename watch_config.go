@@ -0,0 +1,160 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+var (
+	// configWatchInterval is how often the watched files are checked for
+	// changes.
+	configWatchInterval = 1 * time.Second
+
+	// configWatchDebounce is how long the watched files must stay unchanged
+	// after a change before a reload is triggered, so that a reload does not
+	// see half of an edit which touches several files.
+	configWatchDebounce = 2 * time.Second
+)
+
+// configWatcher polls the configuration paths and the template sources, and
+// notifies reloadCh once their contents changed and then stayed unchanged for
+// the debounce period. Directories are walked for the files they contain, the
+// same as when the configuration is loaded, so added and removed files are
+// noticed too.
+type configWatcher struct {
+	paths []string
+
+	interval time.Duration
+	debounce time.Duration
+
+	reloadCh chan struct{}
+	stopCh   chan struct{}
+}
+
+// newConfigWatcher starts watching the given configuration paths and the
+// template sources of the given configuration, from their current contents.
+func newConfigWatcher(paths []string, c *config.Config) *configWatcher {
+	w := &configWatcher{
+		paths:    configWatchPaths(paths, c),
+		interval: configWatchInterval,
+		debounce: configWatchDebounce,
+		reloadCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+
+	log.Printf("[DEBUG] (cli) watching %d configuration paths for changes", len(w.paths))
+	go w.run(hashFiles(w.paths))
+	return w
+}
+
+// watchConfig stops the given watcher, which may be nil, and returns a new
+// watcher for the given configuration if it enables watch_config, or nil.
+func watchConfig(w *configWatcher, paths []string, c *config.Config) *configWatcher {
+	w.Stop()
+	if !config.BoolVal(c.WatchConfig) {
+		return nil
+	}
+	return newConfigWatcher(paths, c)
+}
+
+// ReloadCh returns the channel which is notified when the configuration
+// should be reloaded. It returns nil for a nil watcher, which never notifies.
+func (w *configWatcher) ReloadCh() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.reloadCh
+}
+
+// Stop stops watching. It is safe to call on a nil watcher.
+func (w *configWatcher) Stop() {
+	if w == nil {
+		return
+	}
+	close(w.stopCh)
+}
+
+// run checks the files every interval, comparing them to the given hashes.
+func (w *configWatcher) run(hashes map[string]string) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var debounceCh <-chan time.Time
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-debounceCh:
+			debounceCh = nil
+			log.Printf("[INFO] (cli) configuration changed on disk, reloading")
+			select {
+			case w.reloadCh <- struct{}{}:
+			default:
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		next := hashFiles(w.paths)
+		if reflect.DeepEqual(hashes, next) {
+			continue
+		}
+		hashes = next
+
+		log.Printf("[DEBUG] (cli) configuration changed on disk, waiting %s for "+
+			"further changes", w.debounce)
+		debounceCh = time.After(w.debounce)
+	}
+}
+
+// configWatchPaths returns the configuration paths and the template sources of
+// the given configuration.
+func configWatchPaths(paths []string, c *config.Config) []string {
+	watched := make([]string, 0, len(paths))
+	watched = append(watched, paths...)
+
+	if c.Templates != nil {
+		for _, t := range *c.Templates {
+			if s := config.StringVal(t.Source); s != "" {
+				watched = append(watched, s)
+			}
+		}
+	}
+	return watched
+}
+
+// hashFiles returns the hash of the contents of each file at or under the given
+// paths. A path which cannot be read is included with an empty hash, so that
+// it changes once it can be read again.
+func hashFiles(paths []string) map[string]string {
+	hashes := make(map[string]string)
+	for _, path := range paths {
+		filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				hashes[p] = ""
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				hashes[p] = ""
+				return nil
+			}
+			sum := sha256.Sum256(b)
+			hashes[p] = hex.EncodeToString(sum[:])
+			return nil
+		})
+	}
+	return hashes
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-template/config"
+)
+
+func TestConfigWatchPaths(t *testing.T) {
+	cases := []struct {
+		name  string
+		paths []string
+		c     *config.Config
+		e     []string
+	}{
+		{
+			"empty",
+			nil,
+			&config.Config{},
+			[]string{},
+		},
+		{
+			"paths",
+			[]string{"config.hcl", "config.d"},
+			&config.Config{},
+			[]string{"config.hcl", "config.d"},
+		},
+		{
+			"template_sources",
+			[]string{"config.hcl"},
+			&config.Config{
+				Templates: &config.TemplateConfigs{
+					&config.TemplateConfig{
+						Source: config.String("foo.tpl"),
+					},
+					&config.TemplateConfig{
+						Contents: config.String("bar"),
+					},
+				},
+			},
+			[]string{"config.hcl", "foo.tpl"},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d_%s", i, tc.name), func(t *testing.T) {
+			a := configWatchPaths(tc.paths, tc.c)
+			if !reflect.DeepEqual(tc.e, a) {
+				t.Errorf("\nexp: %#v\nact: %#v", tc.e, a)
+			}
+		})
+	}
+}
+
+func TestHashFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	foo := filepath.Join(dir, "foo.hcl")
+	bar := filepath.Join(dir, "config.d", "bar.hcl")
+	missing := filepath.Join(dir, "missing.hcl")
+
+	if err := os.Mkdir(filepath.Dir(bar), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{foo, bar} {
+		if err := ioutil.WriteFile(path, []byte(path), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hashes := hashFiles([]string{foo, filepath.Dir(bar), missing})
+	if l := len(hashes); l != 3 {
+		t.Fatalf("\nexp: %#v\nact: %#v", 3, hashes)
+	}
+	if hashes[foo] == "" || hashes[bar] == "" || hashes[foo] == hashes[bar] {
+		t.Errorf("expected a hash of each file, got %#v", hashes)
+	}
+	if h, ok := hashes[missing]; !ok || h != "" {
+		t.Errorf("expected an empty hash for %s, got %#v", missing, hashes)
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.hcl")
+	if err := ioutil.WriteFile(path, []byte(`wait = "1s"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &configWatcher{
+		paths:    []string{dir},
+		interval: 10 * time.Millisecond,
+		debounce: 100 * time.Millisecond,
+		reloadCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+	go w.run(hashFiles(w.paths))
+	defer w.Stop()
+
+	select {
+	case <-w.ReloadCh():
+		t.Fatal("reload without a change")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Several changes within the debounce period trigger a single reload.
+	for _, contents := range []string{`wait = "2s"`, `wait = "3s"`} {
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	select {
+	case <-w.ReloadCh():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+
+	select {
+	case <-w.ReloadCh():
+		t.Fatal("reload without a change")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// A file added to a watched directory triggers a reload.
+	if err := ioutil.WriteFile(filepath.Join(dir, "new.hcl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-w.ReloadCh():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	c := config.DefaultConfig()
+	c.Finalize()
+
+	if w := watchConfig(nil, nil, c); w != nil {
+		t.Errorf("expected no watcher, got %#v", w)
+	}
+
+	c.WatchConfig = config.Bool(true)
+	w := watchConfig(nil, nil, c)
+	if w == nil {
+		t.Fatal("expected a watcher")
+	}
+
+	c.WatchConfig = config.Bool(false)
+	if next := watchConfig(w, nil, c); next != nil {
+		t.Errorf("expected no watcher, got %#v", next)
+	}
+	select {
+	case <-w.stopCh:
+	default:
+		t.Errorf("expected the previous watcher to be stopped")
+	}
+}